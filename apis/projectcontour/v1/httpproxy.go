@@ -53,28 +53,46 @@ type Include struct {
 	// In effect, they are added onto the Conditions of included HTTPProxy Route
 	// structs.
 	// When applied, they are merged using AND, with one exception:
-	// There can be only one Prefix MatchCondition per Conditions slice.
-	// More than one Prefix, or contradictory Conditions, will make the
-	// include invalid.
+	// There can be only one Prefix, Exact or Regex MatchCondition per
+	// Conditions slice. More than one, or contradictory Conditions, will
+	// make the include invalid. The routes of an include with an Exact or
+	// Regex condition must not have path conditions of their own.
 	// +optional
 	Conditions []MatchCondition `json:"conditions,omitempty"`
 }
 
 // MatchCondition are a general holder for matching rules for HTTPProxies.
-// One of Prefix or Header must be provided.
+// One of Prefix, Exact, Regex or Header must be provided.
 type MatchCondition struct {
 	// Prefix defines a prefix match for a request.
 	// +optional
 	Prefix string `json:"prefix,omitempty"`
 
+	// Exact defines an exact match for the path of a request. When
+	// included below prefix conditions, the prefixes are prepended
+	// to the path.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Regex defines an RE2 regular expression that must match the
+	// whole path of a request. When included below prefix conditions,
+	// the regex must match the remainder of the path after the
+	// prefixes.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
 	// Header specifies the header condition to match.
 	// +optional
 	Header *HeaderMatchCondition `json:"header,omitempty"`
+
+	// QueryParameter specifies the query parameter condition to match.
+	// +optional
+	QueryParameter *QueryParameterMatchCondition `json:"queryParameter,omitempty"`
 }
 
 // HeaderMatchCondition specifies how to conditionally match against HTTP
 // headers. The Name field is required, but only one of the remaining
-// fields should be be provided.
+// fields, other than IgnoreCase, should be be provided.
 type HeaderMatchCondition struct {
 	// Name is the name of the header to match against. Name is required.
 	// Header names are case insensitive.
@@ -111,6 +129,68 @@ type HeaderMatchCondition struct {
 	// equal to. The condition is true if the header has any other value.
 	// +optional
 	NotExact string `json:"notexact,omitempty"`
+
+	// Prefix specifies a string that the header value must start with.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Suffix specifies a string that the header value must end with.
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+
+	// Regex specifies an RE2 regular expression that the whole header
+	// value must match.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// IgnoreCase specifies that the Exact, NotExact, Contains,
+	// NotContains, Prefix and Suffix values are compared without
+	// regard to case. Use the (?i) flag for case insensitive Regex
+	// conditions.
+	// +optional
+	IgnoreCase bool `json:"ignoreCase,omitempty"`
+}
+
+// QueryParameterMatchCondition specifies how to conditionally match against
+// HTTP query parameters. The Name field is required, and exactly one of the
+// remaining fields must be provided.
+type QueryParameterMatchCondition struct {
+	// Name is the name of the query parameter to match against. Name is
+	// required. Query parameter names are case sensitive.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Exact specifies a string that the query parameter value must be
+	// equal to.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Prefix specifies a string that the query parameter value must
+	// start with.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Suffix specifies a string that the query parameter value must
+	// end with.
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+
+	// Regex specifies an RE2 regular expression that the whole query
+	// parameter value must match.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Contains specifies a substring that must be present in the query
+	// parameter value.
+	// +optional
+	Contains string `json:"contains,omitempty"`
+
+	// Present specifies that condition is true when the named query
+	// parameter is present, regardless of its value. Note that setting
+	// Present to false does not make the condition true if the named
+	// query parameter is absent.
+	// +optional
+	Present bool `json:"present,omitempty"`
 }
 
 // ExtensionServiceReference names an ExtensionService resource.
@@ -244,6 +324,10 @@ type VirtualHost struct {
 	// The policy for rate limiting on the virtual host.
 	// +optional
 	RateLimitPolicy *RateLimitPolicy `json:"rateLimitPolicy,omitempty"`
+	// The policy for access logging on the virtual host.
+	// Overrides the global access log configuration.
+	// +optional
+	AccessLog *AccessLogPolicy `json:"accessLog,omitempty"`
 }
 
 // TLS describes tls properties. The SNI names that will be matched on
@@ -284,8 +368,38 @@ type TLS struct {
 	// EnableFallbackCertificate defines if the vhost should allow a default certificate to
 	// be applied which handles all requests which don't match the SNI defined in this vhost.
 	EnableFallbackCertificate bool `json:"enableFallbackCertificate,omitempty"`
+
+	// OCSPStaplePolicy defines how Envoy staples the OCSP response stored
+	// under the `ocsp.der` key of the TLS secret.
+	//
+	// LenientStapling staples the response if it is present and valid,
+	// and otherwise completes the handshake without it.
+	// StrictStapling staples the response if present, and fails the
+	// handshake if the response has expired.
+	// MustStaple requires a valid response, failing the handshake if it
+	// is missing or expired.
+	//
+	// Defaults to LenientStapling.
+	// +optional
+	// +kubebuilder:validation:Enum=LenientStapling;StrictStapling;MustStaple
+	OCSPStaplePolicy OCSPStaplePolicy `json:"ocspStaplePolicy,omitempty"`
 }
 
+// OCSPStaplePolicy is the policy for stapling OCSP responses to the
+// certificate presented by a virtual host.
+type OCSPStaplePolicy string
+
+const (
+	// OCSPStaplePolicyLenient staples the OCSP response if it is
+	// present and valid.
+	OCSPStaplePolicyLenient OCSPStaplePolicy = "LenientStapling"
+	// OCSPStaplePolicyStrict staples the OCSP response if it is
+	// present, failing the handshake if it has expired.
+	OCSPStaplePolicyStrict OCSPStaplePolicy = "StrictStapling"
+	// OCSPStaplePolicyMust requires a valid OCSP response to be stapled.
+	OCSPStaplePolicyMust OCSPStaplePolicy = "MustStaple"
+)
+
 // CORSHeaderValue specifies the value of the string headers returned by a cross-domain request.
 // +kubebuilder:validation:Pattern="^[a-zA-Z0-9!#$%&'*+.^_`|~-]+$"
 type CORSHeaderValue string
@@ -331,9 +445,9 @@ type CORSPolicy struct {
 type Route struct {
 	// Conditions are a set of rules that are applied to a Route.
 	// When applied, they are merged using AND, with one exception:
-	// There can be only one Prefix MatchCondition per Conditions slice.
-	// More than one Prefix, or contradictory Conditions, will make the
-	// route invalid.
+	// There can be only one Prefix, Exact or Regex MatchCondition per
+	// Conditions slice. More than one, or contradictory Conditions, will
+	// make the route invalid.
 	// +optional
 	Conditions []MatchCondition `json:"conditions,omitempty"`
 	// Services are the services to proxy traffic.
@@ -371,6 +485,12 @@ type Route struct {
 	// The policy for managing request headers during proxying.
 	// +optional
 	RequestHeadersPolicy *HeadersPolicy `json:"requestHeadersPolicy,omitempty"`
+	// AutoHostRewrite rewrites the 'Host' header of requests to the
+	// DNS name of the upstream host they are sent to. All of the
+	// route's services must be of type ExternalName, and the 'Host'
+	// header must not also be set by the RequestHeadersPolicy.
+	// +optional
+	AutoHostRewrite bool `json:"autoHostRewrite,omitempty"`
 	// The policy for managing response headers during proxying.
 	// Rewriting the 'Host' header is not supported.
 	// +optional
@@ -391,6 +511,60 @@ type Route struct {
 	// DirectResponsePolicy returns an arbitrary HTTP response directly.
 	// +optional
 	DirectResponsePolicy *HTTPDirectResponsePolicy `json:"directResponsePolicy,omitempty"`
+
+	// The policy for buffering request bodies on this route.
+	// +optional
+	BufferPolicy *BufferPolicy `json:"bufferPolicy,omitempty"`
+
+	// The policy for access logging on this route. Overrides the
+	// access log configuration of the virtual host.
+	// +optional
+	AccessLog *AccessLogPolicy `json:"accessLog,omitempty"`
+
+	// ClientCertificateAuthorization restricts the route to clients
+	// presenting matching certificates. Overrides the authorization
+	// configured on the virtual host's client validation. Requires
+	// client validation to be configured on the virtual host.
+	// +optional
+	ClientCertificateAuthorization *ClientCertificateAuthorization `json:"clientCertificateAuthorization,omitempty"`
+}
+
+// AccessLogPolicy overrides the global access log configuration.
+// Fields that are not set keep their inherited value.
+type AccessLogPolicy struct {
+	// Format sets the access log format.
+	//
+	// Values: `envoy`, `json`.
+	// +optional
+	// +kubebuilder:validation:Enum=envoy;json
+	Format string `json:"format,omitempty"`
+
+	// FormatString sets the access log format when format is `envoy`.
+	// +optional
+	FormatString string `json:"formatString,omitempty"`
+
+	// JSONFields sets the fields that JSON logging will output
+	// when format is `json`.
+	// +optional
+	JSONFields []string `json:"jsonFields,omitempty"`
+
+	// Level sets the verbosity level of the access log.
+	//
+	// Values: `info` (all requests are logged), `error` and `disabled`.
+	// +optional
+	// +kubebuilder:validation:Enum=info;error;disabled
+	Level string `json:"level,omitempty"`
+}
+
+// BufferPolicy defines how the request body is buffered before it is
+// forwarded to the upstream.
+type BufferPolicy struct {
+	// MaxRequestBytes is the maximum size, in bytes, of a request
+	// body that Envoy will buffer before forwarding the request.
+	// Requests with a larger body are rejected with a 413 response.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	MaxRequestBytes uint32 `json:"maxRequestBytes"`
 }
 
 type HTTPDirectResponsePolicy struct {
@@ -719,12 +893,22 @@ type Service struct {
 	// UpstreamValidation defines how to verify the backend service's certificate
 	// +optional
 	UpstreamValidation *UpstreamValidation `json:"validation,omitempty"`
+	// ClientCertificate defines the client certificate Envoy presents to this
+	// service, overriding the client certificate configured on Contour.
+	// Only applies to services using the tls or h2 protocols.
+	// +optional
+	ClientCertificate *ClientCertificate `json:"clientCertificate,omitempty"`
 	// If Mirror is true the Service will receive a read only mirror of the traffic for this route.
 	Mirror bool `json:"mirror,omitempty"`
 	// The policy for managing request headers during proxying.
 	// Rewriting the 'Host' header is not supported.
 	// +optional
 	RequestHeadersPolicy *HeadersPolicy `json:"requestHeadersPolicy,omitempty"`
+	// HostRewrite rewrites the 'Host' header of requests sent to this
+	// Service to the Service's external name, overriding any 'Host'
+	// header set by the route. The Service must be of type ExternalName.
+	// +optional
+	HostRewrite bool `json:"hostRewrite,omitempty"`
 	// The policy for managing response headers during proxying.
 	// Rewriting the 'Host' header is not supported.
 	// +optional
@@ -732,6 +916,57 @@ type Service struct {
 	// The policies for rewriting Set-Cookie header attributes.
 	// +optional
 	CookieRewritePolicies []CookieRewritePolicy `json:"cookieRewritePolicies,omitempty"`
+	// The policy for balancing requests across the topology zones
+	// of the Service's endpoints.
+	// +optional
+	LocalityLoadBalancerPolicy *LocalityLoadBalancerPolicy `json:"localityLoadBalancerPolicy,omitempty"`
+	// The policy for passively ejecting unhealthy endpoints
+	// of the Service from the load balancing pool.
+	// +optional
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty"`
+}
+
+// OutlierDetection defines how endpoints that return errors are
+// temporarily ejected from the load balancing pool. Unlike health
+// checks, outlier detection observes live traffic and does not
+// require the backend to expose a health endpoint.
+type OutlierDetection struct {
+	// ConsecutiveServerErrors is the number of consecutive 5xx
+	// responses or connection failures after which an endpoint
+	// is ejected. If not supplied, Envoy's default value of 5 applies.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ConsecutiveServerErrors uint32 `json:"consecutiveServerErrors,omitempty"`
+
+	// ConsecutiveGatewayErrors is the number of consecutive 502,
+	// 503 or 504 responses or connection failures after which an
+	// endpoint is ejected. If not supplied, endpoints are not
+	// ejected for gateway errors alone.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ConsecutiveGatewayErrors uint32 `json:"consecutiveGatewayErrors,omitempty"`
+
+	// Interval is the time between ejection analysis sweeps.
+	// If not supplied, Envoy's default value of 10s applies.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	Interval string `json:"interval,omitempty"`
+
+	// BaseEjectionTime is the base time an endpoint stays ejected.
+	// The actual time is the base time multiplied by the number of
+	// times the endpoint has been ejected.
+	// If not supplied, Envoy's default value of 30s applies.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	BaseEjectionTime string `json:"baseEjectionTime,omitempty"`
+
+	// MaxEjectionPercent is the maximum percentage of the Service's
+	// endpoints that can be ejected at the same time.
+	// If not supplied, Envoy's default value of 10% applies.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MaxEjectionPercent uint32 `json:"maxEjectionPercent,omitempty"`
 }
 
 // HTTPHealthCheckPolicy defines health checks on the upstream service.
@@ -888,6 +1123,36 @@ type PathRewritePolicy struct {
 	// ReplacePrefix describes how the path prefix should be replaced.
 	// +optional
 	ReplacePrefix []ReplacePrefix `json:"replacePrefix,omitempty"`
+
+	// RegexRewrite describes how the parts of the path matching a
+	// regular expression should be substituted.
+	// +optional
+	RegexRewrite *RegexRewrite `json:"regexRewrite,omitempty"`
+
+	// ReplaceFullPath is the path that the whole request path
+	// will be replaced with. It must start with a '/'.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ReplaceFullPath string `json:"replaceFullPath,omitempty"`
+}
+
+// RegexRewrite describes how to rewrite the parts of a request path
+// that match an RE2 regular expression.
+type RegexRewrite struct {
+	// Pattern is the RE2 regular expression matched against the
+	// request path. Every non-overlapping match is substituted.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern"`
+
+	// Substitution is the string that each match of Pattern is
+	// replaced with. It may refer to capture groups of Pattern
+	// with \1 to \9, or to the whole match with \0. A literal
+	// backslash must be written as \\.
+	//
+	// +kubebuilder:validation:Required
+	Substitution string `json:"substitution"`
 }
 
 // HeaderHashOptions contains options to configure a HTTP request header hash
@@ -940,6 +1205,25 @@ type RequestHashPolicy struct {
 	HashSourceIP bool `json:"hashSourceIP,omitempty"`
 }
 
+// LocalityLoadBalancerPolicy defines how requests are balanced across
+// the topology zones of a Service's endpoints. Locality information is
+// only available when endpoints are discovered from EndpointSlices.
+type LocalityLoadBalancerPolicy struct {
+	// Strategy specifies the policy used to balance requests across
+	// zones. Valid policy names are `LocalityWeighted` and `ZoneAware`.
+	//
+	// `LocalityWeighted` spreads requests evenly across zones, whatever
+	// the number of endpoints in each zone.
+	//
+	// `ZoneAware` prefers endpoints in the same zone as the Envoy
+	// handling the request, spilling over into other zones when the
+	// local zone does not have enough capacity. This requires Envoy
+	// to be bootstrapped with its zone, see `contour bootstrap --zone`.
+	//
+	// +kubebuilder:validation:Enum=LocalityWeighted;ZoneAware
+	Strategy string `json:"strategy"`
+}
+
 // LoadBalancerPolicy defines the load balancing policy.
 type LoadBalancerPolicy struct {
 	// Strategy specifies the policy used to balance requests
@@ -990,7 +1274,74 @@ type UpstreamValidation struct {
 	// The secret must contain key named ca.crt.
 	CACertificate string `json:"caSecret"`
 	// Key which is expected to be present in the 'subjectAltName' of the presented certificate.
-	SubjectName string `json:"subjectName"`
+	// Either SubjectName or SubjectAltNames must be specified.
+	// +optional
+	SubjectName string `json:"subjectName,omitempty"`
+	// SubjectNameType is the type of 'subjectAltName' that SubjectName is matched against.
+	// Use URI to match a SPIFFE ID such as spiffe://cluster.local/ns/default/sa/backend.
+	// Defaults to DNS.
+	// +optional
+	// +kubebuilder:validation:Enum=DNS;URI;IP
+	SubjectNameType SubjectAltNameType `json:"subjectNameType,omitempty"`
+	// SubjectAltNames is a list of matchers for the 'subjectAltName' of the
+	// presented certificate. The certificate is accepted if any of its
+	// subject alternative names matches any of the matchers, or SubjectName.
+	// +optional
+	SubjectAltNames []SubjectAltNameMatcher `json:"subjectAltNames,omitempty"`
+}
+
+// SubjectAltNameMatcher matches a certificate's subject alternative names
+// of a given type. Exactly one of Exact, Prefix, Suffix or Regex must be
+// specified.
+type SubjectAltNameMatcher struct {
+	// Type is the type of subject alternative name to match.
+	// Defaults to DNS.
+	// +optional
+	// +kubebuilder:validation:Enum=DNS;URI;IP
+	Type SubjectAltNameType `json:"type,omitempty"`
+	// Exact matches a subject alternative name equal to the given value.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+	// Prefix matches a subject alternative name starting with the given value.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Suffix matches a subject alternative name ending with the given value.
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+	// Regex matches a subject alternative name against the given
+	// regular expression.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+}
+
+// SubjectAltNameType is the type of a certificate's subject alternative name.
+type SubjectAltNameType string
+
+const (
+	// SubjectAltNameTypeDNS matches DNS subject alternative names.
+	SubjectAltNameTypeDNS SubjectAltNameType = "DNS"
+	// SubjectAltNameTypeURI matches URI subject alternative names,
+	// such as SPIFFE IDs.
+	SubjectAltNameTypeURI SubjectAltNameType = "URI"
+	// SubjectAltNameTypeIP matches IP address subject alternative names.
+	SubjectAltNameTypeIP SubjectAltNameType = "IP"
+)
+
+// ClientCertificate defines where Envoy gets the client certificate it
+// presents to an upstream service. Exactly one of SecretName or SDSName
+// must be set.
+type ClientCertificate struct {
+	// SecretName is the name or namespaced name of a Kubernetes Secret of
+	// type kubernetes.io/tls containing the client certificate and key.
+	// A Secret in another namespace must be delegated to the HTTPProxy's
+	// namespace with a TLSCertificateDelegation.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// SDSName is the name of a certificate that Envoy fetches from the
+	// secret discovery service configured on Contour, for example a
+	// SPIFFE ID served by a SPIRE agent.
+	// +optional
+	SDSName string `json:"sdsName,omitempty"`
 }
 
 // DownstreamValidation defines how to verify the client certificate.
@@ -1028,6 +1379,83 @@ type DownstreamValidation struct {
 	// certificate chain will be subject to validation by CRL.
 	// +optional
 	OnlyVerifyLeafCertCrl bool `json:"crlOnlyVerifyLeafCert"`
+
+	// SubjectAltNames is a list of matchers for the 'subjectAltName' of
+	// the client certificate. If specified, the TLS handshake fails
+	// unless one of the certificate's subject alternative names matches
+	// one of the matchers.
+	// +optional
+	SubjectAltNames []SubjectAltNameMatcher `json:"subjectAltNames,omitempty"`
+
+	// CertificateHashes is a list of hex encoded SHA-256 fingerprints of
+	// the client certificates to accept, optionally colon separated.
+	// If specified, the TLS handshake fails unless the client
+	// certificate's fingerprint is in the list.
+	// +optional
+	CertificateHashes []string `json:"certificateHashes,omitempty"`
+
+	// Authorization restricts the virtual host to clients presenting
+	// matching certificates. Unlike SubjectAltNames, it is checked for
+	// each request and may be overridden by each route.
+	// +optional
+	Authorization *ClientCertificateAuthorization `json:"authorization,omitempty"`
+
+	// ForwardClientCertificate, if set, sets the
+	// x-forwarded-client-cert header on requests sent to backends,
+	// containing the certificate hash and the selected details of the
+	// client certificate. Otherwise, the header is removed.
+	// +optional
+	ForwardClientCertificate *ClientCertificateDetails `json:"forwardClientCertificate,omitempty"`
+}
+
+// ClientCertificateAuthorization allows requests from clients whose
+// certificate identifies a matching principal. A certificate's
+// principal is its first URI subject alternative name, or else its
+// first DNS subject alternative name, or else its subject.
+type ClientCertificateAuthorization struct {
+	// Principals is a list of matchers for the client certificate's
+	// principal. Requests are allowed if any of the matchers match.
+	// +kubebuilder:validation:MinItems=1
+	Principals []ClientCertificatePrincipal `json:"principals"`
+}
+
+// ClientCertificatePrincipal matches the principal of a client
+// certificate. Exactly one of Exact, Prefix, Suffix or Regex must be
+// specified.
+type ClientCertificatePrincipal struct {
+	// Exact matches a principal equal to the given value.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+	// Prefix matches a principal starting with the given value.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Suffix matches a principal ending with the given value.
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+	// Regex matches a principal against the given regular expression.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+}
+
+// ClientCertificateDetails selects the details of the client
+// certificate added to the x-forwarded-client-cert header, in addition
+// to the certificate hash.
+type ClientCertificateDetails struct {
+	// Subject adds the subject of the client certificate.
+	// +optional
+	Subject bool `json:"subject,omitempty"`
+	// URI adds the URI subject alternative names of the client certificate.
+	// +optional
+	URI bool `json:"uri,omitempty"`
+	// DNS adds the DNS subject alternative names of the client certificate.
+	// +optional
+	DNS bool `json:"dns,omitempty"`
+	// Cert adds the PEM encoded client certificate.
+	// +optional
+	Cert bool `json:"cert,omitempty"`
+	// Chain adds the PEM encoded client certificate chain.
+	// +optional
+	Chain bool `json:"chain,omitempty"`
 }
 
 // HTTPProxyStatus reports the current state of the HTTPProxy.
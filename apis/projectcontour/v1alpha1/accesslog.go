@@ -163,6 +163,54 @@ var envoyComplexOperators = map[string]struct {
 	"TRAILER": {},
 }
 
+// accessLogServiceOperators is the list of simple Envoy log template keywords
+// whose values are part of the standard entry sent to an access log service.
+// GRPC_STATUS and GRPC_STATUS_NUMBER are sent as the grpc-status header.
+var accessLogServiceOperators = map[string]struct{}{
+	"BYTES_RECEIVED":                    {},
+	"BYTES_SENT":                        {},
+	"CONNECTION_TERMINATION_DETAILS":    {},
+	"DOWNSTREAM_DIRECT_REMOTE_ADDRESS":  {},
+	"DOWNSTREAM_LOCAL_ADDRESS":          {},
+	"DOWNSTREAM_LOCAL_SUBJECT":          {},
+	"DOWNSTREAM_LOCAL_URI_SAN":          {},
+	"DOWNSTREAM_PEER_SUBJECT":           {},
+	"DOWNSTREAM_PEER_URI_SAN":           {},
+	"DOWNSTREAM_REMOTE_ADDRESS":         {},
+	"DOWNSTREAM_TLS_CIPHER":             {},
+	"DOWNSTREAM_TLS_SESSION_ID":         {},
+	"DOWNSTREAM_TLS_VERSION":            {},
+	"DURATION":                          {},
+	"GRPC_STATUS":                       {},
+	"GRPC_STATUS_NUMBER":                {},
+	"PROTOCOL":                          {},
+	"REQUEST_DURATION":                  {},
+	"REQUEST_HEADERS_BYTES":             {},
+	"REQUESTED_SERVER_NAME":             {},
+	"RESPONSE_CODE":                     {},
+	"RESPONSE_CODE_DETAILS":             {},
+	"RESPONSE_FLAGS":                    {},
+	"RESPONSE_HEADERS_BYTES":            {},
+	"ROUTE_NAME":                        {},
+	"START_TIME":                        {},
+	"UPSTREAM_CLUSTER":                  {},
+	"UPSTREAM_HOST":                     {},
+	"UPSTREAM_LOCAL_ADDRESS":            {},
+	"UPSTREAM_REMOTE_ADDRESS":           {},
+	"UPSTREAM_REQUEST_ATTEMPT_COUNT":    {},
+	"UPSTREAM_TRANSPORT_FAILURE_REASON": {},
+}
+
+// accessLogServiceComplexOperators is the list of Envoy log template keywords
+// with arguments that can be sent to an access log service, as request header
+// or environment custom tags, or as additional response headers or trailers.
+var accessLogServiceComplexOperators = map[string]struct{}{
+	"ENVIRONMENT": {},
+	"REQ":         {},
+	"RESP":        {},
+	"TRAILER":     {},
+}
+
 // AccessLogType is the name of a supported access logging mechanism.
 type AccessLogType string
 
@@ -206,6 +254,28 @@ func (a AccessLogJSONFields) Validate() error {
 	return nil
 }
 
+// ValidateForAccessLogService ensures that every field can be sent to an
+// access log service, which doesn't use the JSON format. Each field must
+// be a single operator, either one whose value is part of the standard
+// access log entry, or a request header, response header, response trailer
+// or environment operator.
+func (a AccessLogJSONFields) ValidateForAccessLogService() error {
+	for key, val := range a.AsFieldMap() {
+		match := commandOperatorRegexp.FindStringSubmatch(val)
+		if match == nil || match[0] != val {
+			return fmt.Errorf("invalid JSON field %s: %q can't be sent to the access log service", key, val)
+		}
+
+		_, okSimple := accessLogServiceOperators[match[2]]
+		_, okComplex := accessLogServiceComplexOperators[match[2]]
+		if !okSimple && !okComplex {
+			return fmt.Errorf("invalid JSON field %s: %q can't be sent to the access log service", key, val)
+		}
+	}
+
+	return nil
+}
+
 func (a AccessLogJSONFields) AsFieldMap() map[string]string {
 	fieldMap := map[string]string{}
 
@@ -84,6 +84,33 @@ func TestValidateAccessLogJSONFields(t *testing.T) {
 	}
 }
 
+func TestValidateAccessLogJSONFieldsForAccessLogService(t *testing.T) {
+	errorCases := [][]string{
+		{"@timestamp", "upstream_wire_bytes_sent"},
+		{"@timestamp", "duration=%DURATION%.0"},
+		{"@timestamp", "dog=pug"},
+		{"path=%REQ_WITHOUT_QUERY(X-ENVOY-ORIGINAL-PATH?:PATH)%"},
+		{"@timestamp", "duration=my durations are %DURATION% and method is %REQ(:METHOD)%"},
+	}
+
+	for _, c := range errorCases {
+		assert.Error(t, v1alpha1.AccessLogJSONFields(c).ValidateForAccessLogService(), c)
+	}
+
+	successCases := [][]string{
+		v1alpha1.DefaultAccessLogJSONFields,
+		{"@timestamp", "duration=%START_TIME(%s.%6f)%"},
+		{"@timestamp", "content-id=%REQ(X-CONTENT-ID):10%"},
+		{"@timestamp", "length=%RESP(CONTENT-LENGTH)%"},
+		{"@timestamp", "trailer=%TRAILER(CONTENT-LENGTH)%"},
+		{"pod=%ENVIRONMENT(ENVOY_POD_NAME)%"},
+	}
+
+	for _, c := range successCases {
+		assert.NoError(t, v1alpha1.AccessLogJSONFields(c).ValidateForAccessLogService(), c)
+	}
+}
+
 func TestAccessLogFormatString(t *testing.T) {
 	errorCases := []string{
 		"%REQ=dog%\n",
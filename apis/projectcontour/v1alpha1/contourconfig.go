@@ -66,6 +66,11 @@ type ContourConfigurationSpec struct {
 	// +optional
 	RateLimitService *RateLimitServiceConfig `json:"rateLimitService,omitempty"`
 
+	// Tracing defines properties for exporting trace data from Envoy
+	// to an OpenTelemetry collector.
+	// +optional
+	Tracing *TracingConfig `json:"tracing,omitempty"`
+
 	// Policy specifies default policy applied if not overridden by the user
 	// +optional
 	Policy *PolicyConfig `json:"policy,omitempty"`
@@ -75,8 +80,26 @@ type ContourConfigurationSpec struct {
 	// Contour's default is { address: "0.0.0.0", port: 8000 }.
 	// +optional
 	Metrics *MetricsConfig `json:"metrics,omitempty"`
+
+	// FeatureFlags defines toggles to enable new Contour features.
+	// Available toggles are:
+	// useEndpointSlices - configures Contour to build Envoy endpoint
+	// data from Kubernetes EndpointSlices rather than Endpoints.
+	// +optional
+	FeatureFlags FeatureFlags `json:"featureFlags,omitempty"`
 }
 
+// FeatureFlags defines the set of feature flags
+// to toggle new Contour features.
+type FeatureFlags []string
+
+const (
+	// FeatureUseEndpointSlices configures Contour to consume
+	// discovery.k8s.io/v1 EndpointSlices instead of core/v1
+	// Endpoints when generating ClusterLoadAssignments.
+	FeatureUseEndpointSlices = "useEndpointSlices"
+)
+
 // XDSServerType is the type of xDS server implementation.
 type XDSServerType string
 
@@ -264,6 +287,12 @@ type EnvoyConfig struct {
 	// +optional
 	ClientCertificate *NamespacedName `json:"clientCertificate,omitempty"`
 
+	// SecretDiscoveryService configures the secret discovery service
+	// that Envoy fetches client certificates from, for HTTPProxy
+	// services that set clientCertificate.sdsName.
+	// +optional
+	SecretDiscoveryService *SecretDiscoveryServiceConfig `json:"secretDiscoveryService,omitempty"`
+
 	// Logging defines how Envoy's logs can be configured.
 	// +optional
 	Logging *EnvoyLogging `json:"logging,omitempty"`
@@ -345,6 +374,10 @@ type EnvoyListenerConfig struct {
 	// +optional
 	ConnectionBalancer string `json:"connectionBalancer,omitempty"`
 
+	// HTTP3 holds the HTTP/3 (QUIC) settings for the HTTPS listener.
+	// +optional
+	HTTP3 *EnvoyHTTP3 `json:"http3,omitempty"`
+
 	// TLS holds various configurable Envoy TLS listener values.
 	// +optional
 	TLS *EnvoyTLS `json:"tls,omitempty"`
@@ -400,6 +433,28 @@ type EnvoyTLS struct {
 	CipherSuites []string `json:"cipherSuites,omitempty"`
 }
 
+// EnvoyHTTP3 describes HTTP/3 parameters for the Envoy HTTPS listener.
+type EnvoyHTTP3 struct {
+	// Enabled adds a UDP listener that serves HTTP/3 over QUIC on the
+	// HTTPS listener's address and port. Responses on the HTTPS listener
+	// advertise it to clients with an "alt-svc" header.
+	//
+	// Contour's default is false.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// AdvertisedPort is the UDP port advertised in the "alt-svc" header.
+	// It should be the port that clients use to reach the QUIC listener,
+	// which is usually the Envoy service's HTTPS port rather than the
+	// HTTPS listener's port.
+	//
+	// Contour's default is 443.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	AdvertisedPort *int `json:"advertisedPort,omitempty"`
+}
+
 // EnvoyListener defines parameters for an Envoy Listener.
 type EnvoyListener struct {
 	// Defines an Envoy Listener Address.
@@ -443,6 +498,35 @@ type EnvoyLogging struct {
 	// Other values will produce an error.
 	// +optional
 	AccessLogLevel AccessLogLevel `json:"accessLogLevel,omitempty"`
+
+	// AccessLogService configures Envoy to send HTTP and TCP access
+	// logs to a gRPC access log service instead of writing them to
+	// the listener access log files.
+	// +optional
+	AccessLogService *AccessLogServiceConfig `json:"accessLogService,omitempty"`
+}
+
+// SecretDiscoveryServiceConfig defines properties for fetching
+// client certificates from a secret discovery service.
+type SecretDiscoveryServiceConfig struct {
+	// ExtensionService identifies the extension service defining
+	// the secret discovery service.
+	ExtensionService NamespacedName `json:"extensionService"`
+}
+
+// AccessLogServiceConfig defines properties for sending access logs
+// to a gRPC access log service.
+type AccessLogServiceConfig struct {
+	// ExtensionService identifies the extension service defining
+	// the gRPC access log service.
+	ExtensionService NamespacedName `json:"extensionService"`
+
+	// LogName is sent to the access log service to identify the
+	// source of the access log entries.
+	//
+	// Contour's default is "contour".
+	// +optional
+	LogName string `json:"logName,omitempty"`
 }
 
 // TimeoutParameters holds various configurable proxy timeout values.
@@ -615,6 +699,48 @@ type RateLimitServiceConfig struct {
 	EnableXRateLimitHeaders *bool `json:"enableXRateLimitHeaders,omitempty"`
 }
 
+// TracingConfig defines properties for exporting trace data from Envoy.
+type TracingConfig struct {
+	// ExtensionService identifies the extension service defining
+	// the OpenTelemetry collector that receives trace data.
+	ExtensionService NamespacedName `json:"extensionService"`
+
+	// OverallSampling defines the percentage of requests that are
+	// sampled for tracing, as a number between 0 and 100.
+	//
+	// Contour's default is 100.
+	// +optional
+	OverallSampling *string `json:"overallSampling,omitempty"`
+
+	// MaxPathTagLength defines the maximum length of the request path
+	// to extract and include in the HttpUrl tag.
+	//
+	// Contour's default is 256.
+	// +optional
+	MaxPathTagLength *uint32 `json:"maxPathTagLength,omitempty"`
+
+	// CustomTags defines a list of custom tags with unique tag names
+	// that are added to each span.
+	// +optional
+	CustomTags []*CustomTag `json:"customTags,omitempty"`
+}
+
+// CustomTag defines a custom tag that is added to spans. Exactly
+// one of Literal or RequestHeaderName must be set.
+type CustomTag struct {
+	// TagName is the unique name of the custom tag.
+	TagName string `json:"tagName"`
+
+	// Literal is a static value for the tag.
+	// +optional
+	Literal string `json:"literal,omitempty"`
+
+	// RequestHeaderName is the name of the request header
+	// whose value is used for the tag.
+	// +optional
+	RequestHeaderName string `json:"requestHeaderName,omitempty"`
+}
+
 // PolicyConfig holds default policy used if not explicitly set by the user
 type PolicyConfig struct {
 	// RequestHeadersPolicy defines the request headers set/removed on all routes
@@ -15,6 +15,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strconv"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -38,6 +39,10 @@ func (c *ContourConfigurationSpec) Validate() error {
 	if c.Gateway != nil {
 		validateFuncs = append(validateFuncs, c.Gateway.Validate)
 	}
+	if c.Tracing != nil {
+		validateFuncs = append(validateFuncs, c.Tracing.Validate)
+	}
+	validateFuncs = append(validateFuncs, c.FeatureFlags.Validate)
 
 	for _, validate := range validateFuncs {
 		if err := validate(); err != nil {
@@ -57,6 +62,31 @@ func (x XDSServerType) Validate() error {
 	}
 }
 
+// Validate ensures that every feature flag is known.
+func (f FeatureFlags) Validate() error {
+	for _, flag := range f {
+		switch flag {
+		case FeatureUseEndpointSlices:
+		default:
+			return fmt.Errorf("invalid contour configuration, unknown feature flag: %q", flag)
+		}
+	}
+
+	return nil
+}
+
+// IsEndpointSliceEnabled returns true if the useEndpointSlices
+// feature flag is set.
+func (f FeatureFlags) IsEndpointSliceEnabled() bool {
+	for _, flag := range f {
+		if flag == FeatureUseEndpointSlices {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (d ClusterDNSFamilyType) Validate() error {
 	switch d {
 	case AutoClusterDNSFamily, IPv4ClusterDNSFamily, IPv6ClusterDNSFamily:
@@ -97,6 +127,10 @@ func (e *EnvoyConfig) Validate() error {
 		}
 	}
 
+	if err := e.SecretDiscoveryService.Validate(); err != nil {
+		return err
+	}
+
 	// Envoy TLS configuration
 	if e.Listener != nil && e.Listener.TLS != nil {
 		return e.Listener.TLS.Validate()
@@ -105,6 +139,19 @@ func (e *EnvoyConfig) Validate() error {
 	return nil
 }
 
+// Validate ensures the secret discovery service extension service is specified.
+func (s *SecretDiscoveryServiceConfig) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.ExtensionService.Name == "" || s.ExtensionService.Namespace == "" {
+		return fmt.Errorf("invalid secret discovery service configuration: extension service must be specified")
+	}
+
+	return nil
+}
+
 // Validate ensures EnvoyTLS configuration is valid.
 func (e *EnvoyTLS) Validate() error {
 	if e.MinimumProtocolVersion != "" && e.MinimumProtocolVersion != "1.2" && e.MinimumProtocolVersion != "1.3" {
@@ -159,6 +206,42 @@ func (g *GatewayConfig) Validate() error {
 	return nil
 }
 
+// Validate ensures the sampling rate is a valid percentage and that
+// custom tags have unique names and exactly one value source.
+func (t *TracingConfig) Validate() error {
+	if t == nil {
+		return nil
+	}
+
+	if t.ExtensionService.Name == "" || t.ExtensionService.Namespace == "" {
+		return fmt.Errorf("invalid tracing configuration: extension service must be specified")
+	}
+
+	if t.OverallSampling != nil {
+		sampling, err := strconv.ParseFloat(*t.OverallSampling, 64)
+		if err != nil || sampling < 0 || sampling > 100 {
+			return fmt.Errorf("invalid tracing configuration: overall sampling %q must be a number between 0 and 100", *t.OverallSampling)
+		}
+	}
+
+	tagNames := sets.NewString()
+	for _, tag := range t.CustomTags {
+		if tag.TagName == "" {
+			return fmt.Errorf("invalid tracing configuration: custom tag name must be specified")
+		}
+		if tagNames.Has(tag.TagName) {
+			return fmt.Errorf("invalid tracing configuration: duplicate custom tag name %q", tag.TagName)
+		}
+		tagNames.Insert(tag.TagName)
+
+		if (tag.Literal == "") == (tag.RequestHeaderName == "") {
+			return fmt.Errorf("invalid tracing configuration: custom tag %q must specify exactly one of literal or requestHeaderName", tag.TagName)
+		}
+	}
+
+	return nil
+}
+
 func (e *EnvoyLogging) Validate() error {
 	if e == nil {
 		return nil
@@ -170,9 +253,30 @@ func (e *EnvoyLogging) Validate() error {
 	if err := e.AccessLogJSONFields.Validate(); err != nil {
 		return err
 	}
+	if err := e.AccessLogService.Validate(); err != nil {
+		return err
+	}
+	if e.AccessLogService != nil && e.AccessLogFormat == JSONAccessLog {
+		if err := e.AccessLogJSONFields.ValidateForAccessLogService(); err != nil {
+			return err
+		}
+	}
 	return AccessLogFormatString(e.AccessLogFormatString).Validate()
 }
 
+// Validate ensures the access log service extension service is specified.
+func (a *AccessLogServiceConfig) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	if a.ExtensionService.Name == "" || a.ExtensionService.Namespace == "" {
+		return fmt.Errorf("invalid access log service configuration: extension service must be specified")
+	}
+
+	return nil
+}
+
 // AccessLogFormatterExtensions returns a list of formatter extension names required by the access log format.
 //
 // Note: When adding support for new formatter, update the list of extensions here and
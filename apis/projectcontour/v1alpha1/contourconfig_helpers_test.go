@@ -19,6 +19,7 @@ import (
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
 )
 
 func TestContourConfigurationSpecValidate(t *testing.T) {
@@ -155,6 +156,103 @@ func TestContourConfigurationSpecValidate(t *testing.T) {
 		c.Gateway.GatewayRef = &v1alpha1.NamespacedName{Namespace: "ns", Name: "name"}
 		require.Error(t, c.Validate())
 	})
+
+	t.Run("access log service validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			Envoy: &v1alpha1.EnvoyConfig{
+				Logging: &v1alpha1.EnvoyLogging{
+					AccessLogFormat: v1alpha1.EnvoyAccessLog,
+					AccessLogService: &v1alpha1.AccessLogServiceConfig{
+						ExtensionService: v1alpha1.NamespacedName{Namespace: "ns", Name: "als"},
+					},
+				},
+			},
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogFormat = v1alpha1.JSONAccessLog
+		c.Envoy.Logging.AccessLogJSONFields = v1alpha1.AccessLogJSONFields{"@timestamp", "user_agent"}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogJSONFields = v1alpha1.AccessLogJSONFields{"@timestamp", "upstream_wire_bytes_sent"}
+		require.Error(t, c.Validate())
+
+		c.Envoy.Logging.AccessLogJSONFields = nil
+		c.Envoy.Logging.AccessLogService.ExtensionService.Namespace = ""
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("secret discovery service validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			Envoy: &v1alpha1.EnvoyConfig{
+				SecretDiscoveryService: &v1alpha1.SecretDiscoveryServiceConfig{
+					ExtensionService: v1alpha1.NamespacedName{Namespace: "ns", Name: "spire"},
+				},
+			},
+		}
+		require.NoError(t, c.Validate())
+
+		c.Envoy.SecretDiscoveryService.ExtensionService.Name = ""
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("tracing validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{
+			Tracing: &v1alpha1.TracingConfig{
+				ExtensionService: v1alpha1.NamespacedName{Namespace: "ns", Name: "otel"},
+			},
+		}
+		require.NoError(t, c.Validate())
+
+		c.Tracing.OverallSampling = pointer.String("12.5")
+		require.NoError(t, c.Validate())
+
+		c.Tracing.OverallSampling = pointer.String("101")
+		require.Error(t, c.Validate())
+
+		c.Tracing.OverallSampling = pointer.String("all")
+		require.Error(t, c.Validate())
+
+		c.Tracing.OverallSampling = nil
+		c.Tracing.CustomTags = []*v1alpha1.CustomTag{
+			{TagName: "literal", Literal: "foo"},
+			{TagName: "header", RequestHeaderName: ":path"},
+		}
+		require.NoError(t, c.Validate())
+
+		c.Tracing.CustomTags = []*v1alpha1.CustomTag{
+			{TagName: "both", Literal: "foo", RequestHeaderName: ":path"},
+		}
+		require.Error(t, c.Validate())
+
+		c.Tracing.CustomTags = []*v1alpha1.CustomTag{
+			{TagName: "neither"},
+		}
+		require.Error(t, c.Validate())
+
+		c.Tracing.CustomTags = []*v1alpha1.CustomTag{
+			{TagName: "dup", Literal: "foo"},
+			{TagName: "dup", Literal: "bar"},
+		}
+		require.Error(t, c.Validate())
+
+		c.Tracing.CustomTags = nil
+		c.Tracing.ExtensionService = v1alpha1.NamespacedName{}
+		require.Error(t, c.Validate())
+	})
+
+	t.Run("feature flag validation", func(t *testing.T) {
+		c := v1alpha1.ContourConfigurationSpec{}
+		require.NoError(t, c.Validate())
+		require.False(t, c.FeatureFlags.IsEndpointSliceEnabled())
+
+		c.FeatureFlags = v1alpha1.FeatureFlags{v1alpha1.FeatureUseEndpointSlices}
+		require.NoError(t, c.Validate())
+		require.True(t, c.FeatureFlags.IsEndpointSliceEnabled())
+
+		c.FeatureFlags = v1alpha1.FeatureFlags{v1alpha1.FeatureUseEndpointSlices, "foo"}
+		require.Error(t, c.Validate())
+	})
 }
 
 func TestSanitizeCipherSuites(t *testing.T) {
@@ -47,6 +47,22 @@ func (in AccessLogJSONFields) DeepCopy() AccessLogJSONFields {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessLogServiceConfig) DeepCopyInto(out *AccessLogServiceConfig) {
+	*out = *in
+	out.ExtensionService = in.ExtensionService
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessLogServiceConfig.
+func (in *AccessLogServiceConfig) DeepCopy() *AccessLogServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessLogServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterParameters) DeepCopyInto(out *ClusterParameters) {
 	*out = *in
@@ -169,6 +185,11 @@ func (in *ContourConfigurationSpec) DeepCopyInto(out *ContourConfigurationSpec)
 		*out = new(RateLimitServiceConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(TracingConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Policy != nil {
 		in, out := &in.Policy, &out.Policy
 		*out = new(PolicyConfig)
@@ -179,6 +200,11 @@ func (in *ContourConfigurationSpec) DeepCopyInto(out *ContourConfigurationSpec)
 		*out = new(MetricsConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.FeatureFlags != nil {
+		in, out := &in.FeatureFlags, &out.FeatureFlags
+		*out = make(FeatureFlags, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContourConfigurationSpec.
@@ -344,6 +370,21 @@ func (in *ContourSettings) DeepCopy() *ContourSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomTag) DeepCopyInto(out *CustomTag) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomTag.
+func (in *CustomTag) DeepCopy() *CustomTag {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomTag)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DebugConfig) DeepCopyInto(out *DebugConfig) {
 	*out = *in
@@ -397,6 +438,11 @@ func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
 		*out = new(NamespacedName)
 		**out = **in
 	}
+	if in.SecretDiscoveryService != nil {
+		in, out := &in.SecretDiscoveryService, &out.SecretDiscoveryService
+		*out = new(SecretDiscoveryServiceConfig)
+		**out = **in
+	}
 	if in.Logging != nil {
 		in, out := &in.Logging, &out.Logging
 		*out = new(EnvoyLogging)
@@ -434,6 +480,31 @@ func (in *EnvoyConfig) DeepCopy() *EnvoyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyHTTP3) DeepCopyInto(out *EnvoyHTTP3) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AdvertisedPort != nil {
+		in, out := &in.AdvertisedPort, &out.AdvertisedPort
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyHTTP3.
+func (in *EnvoyHTTP3) DeepCopy() *EnvoyHTTP3 {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyHTTP3)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvoyListener) DeepCopyInto(out *EnvoyListener) {
 	*out = *in
@@ -467,6 +538,11 @@ func (in *EnvoyListenerConfig) DeepCopyInto(out *EnvoyListenerConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.HTTP3 != nil {
+		in, out := &in.HTTP3, &out.HTTP3
+		*out = new(EnvoyHTTP3)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(EnvoyTLS)
@@ -492,6 +568,11 @@ func (in *EnvoyLogging) DeepCopyInto(out *EnvoyLogging) {
 		*out = make(AccessLogJSONFields, len(*in))
 		copy(*out, *in)
 	}
+	if in.AccessLogService != nil {
+		in, out := &in.AccessLogService, &out.AccessLogService
+		*out = new(AccessLogServiceConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyLogging.
@@ -619,7 +700,7 @@ func (in *ExtensionServiceSpec) DeepCopyInto(out *ExtensionServiceSpec) {
 	if in.UpstreamValidation != nil {
 		in, out := &in.UpstreamValidation, &out.UpstreamValidation
 		*out = new(v1.UpstreamValidation)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Protocol != nil {
 		in, out := &in.Protocol, &out.Protocol
@@ -685,6 +766,25 @@ func (in *ExtensionServiceTarget) DeepCopy() *ExtensionServiceTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in FeatureFlags) DeepCopyInto(out *FeatureFlags) {
+	{
+		in := &in
+		*out = make(FeatureFlags, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureFlags.
+func (in FeatureFlags) DeepCopy() FeatureFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(FeatureFlags)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GatewayConfig) DeepCopyInto(out *GatewayConfig) {
 	*out = *in
@@ -979,6 +1079,22 @@ func (in *RateLimitServiceConfig) DeepCopy() *RateLimitServiceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretDiscoveryServiceConfig) DeepCopyInto(out *SecretDiscoveryServiceConfig) {
+	*out = *in
+	out.ExtensionService = in.ExtensionService
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretDiscoveryServiceConfig.
+func (in *SecretDiscoveryServiceConfig) DeepCopy() *SecretDiscoveryServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretDiscoveryServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
@@ -1049,6 +1165,43 @@ func (in *TimeoutParameters) DeepCopy() *TimeoutParameters {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TracingConfig) DeepCopyInto(out *TracingConfig) {
+	*out = *in
+	out.ExtensionService = in.ExtensionService
+	if in.OverallSampling != nil {
+		in, out := &in.OverallSampling, &out.OverallSampling
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxPathTagLength != nil {
+		in, out := &in.MaxPathTagLength, &out.MaxPathTagLength
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.CustomTags != nil {
+		in, out := &in.CustomTags, &out.CustomTags
+		*out = make([]*CustomTag, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(CustomTag)
+				**out = **in
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TracingConfig.
+func (in *TracingConfig) DeepCopy() *TracingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TracingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *XDSServerConfig) DeepCopyInto(out *XDSServerConfig) {
 	*out = *in
@@ -18,6 +18,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/projectcontour/contour/internal/certgen"
 	"github.com/projectcontour/contour/internal/k8s"
@@ -46,6 +47,9 @@ func registerCertGen(app *kingpin.Application) (*kingpin.CmdClause, *certgenConf
 	certgenApp.Flag("overwrite", "Overwrite existing files or Secrets.").BoolVar(&certgenConfig.Overwrite)
 	certgenApp.Flag("secrets-format", "Specify how to format the generated Kubernetes Secrets.").Default("legacy").StringVar(&certgenConfig.Format)
 	certgenApp.Flag("secrets-name-suffix", "Specify a suffix to be appended to the generated Kubernetes secrets' names.").StringVar(&certgenConfig.NameSuffix)
+	certgenApp.Flag("rotate", "Keep running and renew the certificates in the Kubernetes cluster before they expire.").BoolVar(&certgenConfig.Rotate)
+	certgenApp.Flag("rotation-interval", "How often to check whether the certificates need to be renewed.").Default("1h").DurationVar(&certgenConfig.RotationInterval)
+	certgenApp.Flag("renew-before", "Renew certificates that expire within this duration.").Default("720h").DurationVar(&certgenConfig.RenewBefore)
 
 	certgenApp.Arg("outputdir", "Directory to write output files into (default \"certs\").").Default("certs").StringVar(&certgenConfig.OutputDir)
 
@@ -87,30 +91,51 @@ type certgenConfig struct {
 
 	// NameSuffix specifies the suffix to use for the generated Kubernetes secrets' names.
 	NameSuffix string
+
+	// Rotate means that certgen keeps running and renews the certificates
+	// in the Kubernetes cluster before they expire.
+	Rotate bool
+
+	// RotationInterval is how often to check whether the certificates need to be renewed.
+	RotationInterval time.Duration
+
+	// RenewBefore is how long before expiry the certificates are renewed.
+	RenewBefore time.Duration
 }
 
-// OutputCerts outputs the certs in certs as directed by config.
-func OutputCerts(config *certgenConfig, kubeclient *kubernetes.Clientset, certs *certs.Certificates) error {
+// asSecrets formats the certs in certs as Kubernetes Secrets as directed by config.
+func asSecrets(config *certgenConfig, certs *certs.Certificates) ([]*corev1.Secret, error) {
 	var secrets []*corev1.Secret
 	var errs []error
 
+	switch config.Format {
+	case "legacy":
+		secrets, errs = certgen.AsLegacySecrets(config.Namespace, config.NameSuffix, certs)
+	case "compact":
+		secrets, errs = certgen.AsSecrets(config.Namespace, config.NameSuffix, certs)
+	default:
+		return nil, fmt.Errorf("unsupported Secrets format %q", config.Format)
+	}
+
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return secrets, nil
+}
+
+// OutputCerts outputs the certs in certs as directed by config.
+func OutputCerts(config *certgenConfig, kubeclient kubernetes.Interface, certs *certs.Certificates) error {
+	var secrets []*corev1.Secret
+	var err error
+
 	force := certgen.NoOverwrite
 	if config.Overwrite {
 		force = certgen.Overwrite
 	}
 
 	if config.OutputYAML || config.OutputKube {
-		switch config.Format {
-		case "legacy":
-			secrets, errs = certgen.AsLegacySecrets(config.Namespace, config.NameSuffix, certs)
-		case "compact":
-			secrets, errs = certgen.AsSecrets(config.Namespace, config.NameSuffix, certs)
-		default:
-			return fmt.Errorf("unsupported Secrets format %q", config.Format)
-		}
-
-		if len(errs) > 0 {
-			return utilerrors.NewAggregate(errs)
+		if secrets, err = asSecrets(config, certs); err != nil {
+			return err
 		}
 	}
 
@@ -137,7 +162,123 @@ func OutputCerts(config *certgenConfig, kubeclient *kubernetes.Clientset, certs
 	return nil
 }
 
+// rotateCerts takes the next step in rotating the certificates in the
+// Kubernetes cluster, which are renewed if they are missing or expire
+// within config.RenewBefore. Moving to a new CA takes one step per pass:
+// the new CA is first added to the trust bundle next to the current one,
+// then the certificates are issued from it, and finally the previous CA
+// is dropped from the trust bundle. This keeps Contour and Envoy trusting
+// each other while the kubelet updates the mounted Secrets.
+// It returns the step that was taken.
+func rotateCerts(config *certgenConfig, kubeclient kubernetes.Interface, now time.Time) (certgen.RotationStep, error) {
+	existing, err := certgen.ReadSecretsKube(kubeclient, config.Namespace, config.NameSuffix)
+	if err != nil {
+		return certgen.RotationNone, fmt.Errorf("failed to read Secrets from %q: %w", config.Namespace, err)
+	}
+
+	step := certgen.NextRotationStep(existing, config.NameSuffix, now.Add(config.RenewBefore))
+	current := certgen.CurrentCertificates(existing, config.NameSuffix, now)
+
+	switch step {
+	case certgen.RotationNone:
+		return step, nil
+	case certgen.RotationBootstrap, certgen.RotationNewCA:
+		generatedCerts, err := certs.GenerateCerts(
+			&certs.Configuration{
+				Lifetime:  config.Lifetime,
+				Namespace: config.Namespace,
+			})
+		if err != nil {
+			return step, fmt.Errorf("failed to generate certificates: %w", err)
+		}
+
+		// Write the new CA first, so that it is not lost if writing
+		// the trust bundle fails.
+		caSecret := certgen.CASecret(config.Namespace, config.NameSuffix, generatedCerts)
+		if err := certgen.WriteSecretsKube(kubeclient, []*corev1.Secret{caSecret}, certgen.Overwrite); err != nil {
+			return step, fmt.Errorf("failed to write CA to %q: %w", config.Namespace, err)
+		}
+
+		if step == certgen.RotationBootstrap {
+			// Nothing trusts the current certificates, so all
+			// of them can be replaced at once.
+			current = generatedCerts
+		} else {
+			current.CACertificate = certgen.AppendCA(current.CACertificate, generatedCerts.CACertificate)
+		}
+	case certgen.RotationTrustCA:
+		current.CACertificate = certgen.AppendCA(current.CACertificate, certgen.CACertificate(existing, config.NameSuffix))
+	case certgen.RotationIssueCerts:
+		generatedCerts, err := certs.GenerateCerts(
+			&certs.Configuration{
+				Lifetime:      config.Lifetime,
+				Namespace:     config.Namespace,
+				CACertificate: certgen.CACertificate(existing, config.NameSuffix),
+				CAPrivateKey:  current.CAPrivateKey,
+			})
+		if err != nil {
+			return step, fmt.Errorf("failed to generate certificates: %w", err)
+		}
+
+		generatedCerts.CACertificate = current.CACertificate
+		current = generatedCerts
+	case certgen.RotationDropCA:
+		current.CACertificate = certgen.CACertificate(existing, config.NameSuffix)
+	}
+
+	secrets, err := asSecrets(config, current)
+	if err != nil {
+		return step, err
+	}
+
+	if err := certgen.WriteSecretsKube(kubeclient, secrets, certgen.Overwrite); err != nil {
+		return step, fmt.Errorf("failed to write certificates to %q: %w", config.Namespace, err)
+	}
+	return step, nil
+}
+
+// doRotateCerts checks the certificates in the Kubernetes cluster every
+// config.RotationInterval, renewing them when needed. It never returns.
+func doRotateCerts(config *certgenConfig, kubeclient kubernetes.Interface, log logrus.FieldLogger) {
+	if !config.OutputKube {
+		log.Fatal("--rotate requires --kube")
+	}
+
+	lifetime := 24 * time.Hour * time.Duration(config.Lifetime)
+	if lifetime == 0 {
+		lifetime = 24 * time.Hour * certs.DefaultCertificateLifetime
+	}
+	if config.RenewBefore >= lifetime {
+		log.Fatalf("--renew-before %s must be less than the certificate lifetime %s", config.RenewBefore, lifetime)
+	}
+
+	log.WithField("interval", config.RotationInterval).
+		WithField("renew_before", config.RenewBefore).
+		Info("rotating certificates")
+
+	for {
+		step, err := rotateCerts(config, kubeclient, time.Now())
+		switch {
+		case err != nil:
+			log.WithError(err).WithField("step", step).Error("failed to rotate certificates")
+		case step != certgen.RotationNone:
+			log.WithField("step", step).Info("rotated certificates")
+		}
+
+		time.Sleep(config.RotationInterval)
+	}
+}
+
 func doCertgen(config *certgenConfig, log logrus.FieldLogger) {
+	if config.Rotate {
+		coreClient, err := k8s.NewCoreClient(config.KubeConfig, config.InCluster)
+		if err != nil {
+			log.WithError(err).Fatalf("failed to create Kubernetes client")
+		}
+
+		doRotateCerts(config, coreClient, log)
+	}
+
 	generatedCerts, err := certs.GenerateCerts(
 		&certs.Configuration{
 			Lifetime:  config.Lifetime,
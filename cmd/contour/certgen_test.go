@@ -14,6 +14,7 @@
 package main
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -21,12 +22,15 @@ import (
 	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/projectcontour/contour/internal/certgen"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/pkg/certs"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestGeneratedSecretsValid(t *testing.T) {
@@ -291,3 +295,107 @@ func TestOutputFileMode(t *testing.T) {
 		})
 	}
 }
+
+func TestRotateCerts(t *testing.T) {
+	conf := &certgenConfig{
+		Namespace:   "foo",
+		OutputKube:  true,
+		Format:      "compact",
+		RenewBefore: 30 * 24 * time.Hour,
+	}
+	client := fake.NewSimpleClientset()
+
+	caCerts := func(t *testing.T, name string) []*x509.Certificate {
+		t.Helper()
+
+		s, err := client.CoreV1().Secrets("foo").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var certs []*x509.Certificate
+		data := s.Data[dag.CACertificateKey]
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				return certs
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			certs = append(certs, cert)
+		}
+	}
+
+	leafCert := func(t *testing.T, name string) *x509.Certificate {
+		t.Helper()
+
+		s, err := client.CoreV1().Secrets("foo").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+
+	now := time.Now()
+
+	// Missing Secrets are created.
+	step, err := rotateCerts(conf, client, now)
+	assert.NoError(t, err)
+	assert.Equal(t, certgen.RotationBootstrap, step)
+	assert.Len(t, caCerts(t, "contourcert"), 1)
+	assert.Len(t, caCerts(t, "envoycert"), 1)
+	firstCA := caCerts(t, "contourcert")[0]
+	firstLeaf := leafCert(t, "contourcert")
+
+	// Fresh certificates are left alone.
+	step, err = rotateCerts(conf, client, now)
+	assert.NoError(t, err)
+	assert.Equal(t, certgen.RotationNone, step)
+
+	// Before the certificates expire, a new CA is trusted next to
+	// the old one, without changing the certificates.
+	later := now.Add(350 * 24 * time.Hour)
+	step, err = rotateCerts(conf, client, later)
+	assert.NoError(t, err)
+	assert.Equal(t, certgen.RotationNewCA, step)
+	for _, name := range []string{"contourcert", "envoycert"} {
+		bundle := caCerts(t, name)
+		assert.Len(t, bundle, 2)
+		assert.Equal(t, firstCA.Raw, bundle[0].Raw)
+	}
+	assert.Equal(t, firstLeaf.Raw, leafCert(t, "contourcert").Raw)
+	secondCA := caCerts(t, "contourcert")[1]
+
+	// On the next pass, the certificates are issued from the new CA.
+	step, err = rotateCerts(conf, client, later)
+	assert.NoError(t, err)
+	assert.Equal(t, certgen.RotationIssueCerts, step)
+	for _, name := range []string{"contourcert", "envoycert"} {
+		assert.Len(t, caCerts(t, name), 2)
+		assert.NoError(t, leafCert(t, name).CheckSignatureFrom(secondCA))
+	}
+
+	// Then the old CA is dropped from the bundle.
+	step, err = rotateCerts(conf, client, later)
+	assert.NoError(t, err)
+	assert.Equal(t, certgen.RotationDropCA, step)
+	for _, name := range []string{"contourcert", "envoycert"} {
+		bundle := caCerts(t, name)
+		assert.Len(t, bundle, 1)
+		assert.Equal(t, secondCA.Raw, bundle[0].Raw)
+	}
+
+	// The renewed certificates are left alone until they expire.
+	step, err = rotateCerts(conf, client, now)
+	assert.NoError(t, err)
+	assert.Equal(t, certgen.RotationNone, step)
+}
@@ -49,6 +49,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"gopkg.in/alecthomas/kingpin.v2"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networking_v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -350,22 +351,56 @@ func (s *Server) doServe() error {
 		MergeSlashes:                 !*contourConfiguration.Envoy.Listener.DisableMergeSlashes,
 		XffNumTrustedHops:            *contourConfiguration.Envoy.Network.XffNumTrustedHops,
 		ConnectionBalancer:           contourConfiguration.Envoy.Listener.ConnectionBalancer,
+		HTTP3:                        *contourConfiguration.Envoy.Listener.HTTP3.Enabled,
+	}
+
+	// Responses for the vhosts served over HTTP/3 advertise the QUIC
+	// listener with an alt-svc header.
+	routeCache := &xdscache_v3.RouteCache{}
+	if listenerConfig.HTTP3 {
+		routeCache.HTTP3AdvertisedPort = *contourConfiguration.Envoy.Listener.HTTP3.AdvertisedPort
 	}
 
 	if listenerConfig.RateLimitConfig, err = s.setupRateLimitService(contourConfiguration); err != nil {
 		return err
 	}
 
+	if listenerConfig.TracingConfig, err = s.setupTracingService(contourConfiguration); err != nil {
+		return err
+	}
+
+	if listenerConfig.AccessLogServiceConfig, err = s.setupAccessLogService(contourConfiguration); err != nil {
+		return err
+	}
+
+	secretDiscoveryService, err := s.setupSecretDiscoveryService(contourConfiguration)
+	if err != nil {
+		return err
+	}
+
 	contourMetrics := metrics.NewMetrics(s.registry)
 
 	// Endpoints updates are handled directly by the EndpointsTranslator
 	// due to their high update rate and their orthogonal nature.
-	endpointHandler := xdscache_v3.NewEndpointsTranslator(s.log.WithField("context", "endpointstranslator"))
+	var endpointHandler endpointsTranslator
+	if contourConfiguration.FeatureFlags.IsEndpointSliceEnabled() {
+		endpointSliceHandler := xdscache_v3.NewEndpointSliceTranslator(s.log.WithField("context", "endpointslicetranslator"))
+
+		// Serve the Envoy pods as Envoy's local cluster
+		// so that zone aware routing can be used.
+		endpointSliceHandler.SetLocalService(types.NamespacedName{
+			Namespace: contourConfiguration.Envoy.Service.Namespace,
+			Name:      contourConfiguration.Envoy.Service.Name,
+		})
+		endpointHandler = endpointSliceHandler
+	} else {
+		endpointHandler = xdscache_v3.NewEndpointsTranslator(s.log.WithField("context", "endpointstranslator"))
+	}
 
 	resources := []xdscache.ResourceCache{
 		xdscache_v3.NewListenerCache(listenerConfig, *contourConfiguration.Envoy.Metrics, *contourConfiguration.Envoy.Health, *contourConfiguration.Envoy.Network.EnvoyAdminPort),
 		xdscache_v3.NewSecretsCache(envoy_v3.StatsSecrets(contourConfiguration.Envoy.Metrics.TLS)),
-		&xdscache_v3.RouteCache{},
+		routeCache,
 		&xdscache_v3.ClusterCache{},
 		endpointHandler,
 		&xdscache_v3.RuntimeCache{},
@@ -375,7 +410,7 @@ func (s *Server) doServe() error {
 	snapshotHandler := xdscache.NewSnapshotHandler(resources, s.log.WithField("context", "snapshotHandler"))
 
 	// register observer for endpoints updates.
-	endpointHandler.Observer = contour.ComposeObservers(snapshotHandler)
+	endpointHandler.SetObserver(contour.ComposeObservers(snapshotHandler))
 
 	// Log that we're using the fallback certificate if configured.
 	if contourConfiguration.HTTPProxy.FallbackCertificate != nil {
@@ -428,8 +463,12 @@ func (s *Server) doServe() error {
 		dnsLookupFamily:           contourConfiguration.Envoy.Cluster.DNSLookupFamily,
 		headersPolicy:             contourConfiguration.Policy,
 		clientCert:                clientCert,
+		secretDiscoveryService:    secretDiscoveryService,
 		fallbackCert:              fallbackCert,
 		connectTimeout:            timeouts.ConnectTimeout,
+		reservedContainerPorts:    reservedContainerPorts(contourConfiguration.Envoy),
+		accessLogService:          contourConfiguration.Envoy.Logging.AccessLogService != nil,
+		accessLogFormat:           contourConfiguration.Envoy.Logging.AccessLogFormat,
 		client:                    s.mgr.GetClient(),
 	})
 
@@ -482,12 +521,19 @@ func (s *Server) doServe() error {
 		s.log.WithError(err).WithField("resource", "secrets").Fatal("failed to create informer")
 	}
 
-	// Inform on endpoints.
-	if err := informOnResource(&corev1.Endpoints{}, &contour.EventRecorder{
+	// Inform on endpoints, or endpoint slices if enabled.
+	endpointRecorder := &contour.EventRecorder{
 		Next:    endpointHandler,
 		Counter: contourMetrics.EventHandlerOperations,
-	}, s.mgr.GetCache()); err != nil {
-		s.log.WithError(err).WithField("resource", "endpoints").Fatal("failed to create informer")
+	}
+	if contourConfiguration.FeatureFlags.IsEndpointSliceEnabled() {
+		if err := informOnResource(&discoveryv1.EndpointSlice{}, endpointRecorder, s.mgr.GetCache()); err != nil {
+			s.log.WithError(err).WithField("resource", "endpointslices").Fatal("failed to create informer")
+		}
+	} else {
+		if err := informOnResource(&corev1.Endpoints{}, endpointRecorder, s.mgr.GetCache()); err != nil {
+			s.log.WithError(err).WithField("resource", "endpoints").Fatal("failed to create informer")
+		}
 	}
 
 	// Register our event handler with the manager.
@@ -553,6 +599,7 @@ func (s *Server) doServe() error {
 		log:             s.log,
 		mgr:             s.mgr,
 		registry:        s.registry,
+		metrics:         contourMetrics,
 		config:          *contourConfiguration.XDSServer,
 		snapshotHandler: snapshotHandler,
 		resources:       resources,
@@ -575,22 +622,28 @@ func (s *Server) doServe() error {
 	return s.mgr.Start(signals.SetupSignalHandler())
 }
 
-func (s *Server) setupRateLimitService(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*xdscache_v3.RateLimitConfig, error) {
-	if contourConfiguration.RateLimitService == nil {
-		return nil, nil
-	}
+// extensionServiceConfig holds the settings used to reach an ExtensionService.
+type extensionServiceConfig struct {
+	key     client.ObjectKey
+	sni     string
+	timeout timeout.Setting
+}
 
+// getExtensionServiceConfig fetches the ExtensionService called name and
+// returns the settings used to reach it. kind names what the ExtensionService
+// is used for in errors.
+func (s *Server) getExtensionServiceConfig(name contour_api_v1alpha1.NamespacedName, kind string) (*extensionServiceConfig, error) {
 	// ensure the specified ExtensionService exists
 	extensionSvc := &contour_api_v1alpha1.ExtensionService{}
 	key := client.ObjectKey{
-		Namespace: contourConfiguration.RateLimitService.ExtensionService.Namespace,
-		Name:      contourConfiguration.RateLimitService.ExtensionService.Name,
+		Namespace: name.Namespace,
+		Name:      name.Name,
 	}
 
 	// Using GetAPIReader() here because the manager's caches won't be started yet,
 	// so reads from the manager's client (which uses the caches for reads) will fail.
 	if err := s.mgr.GetAPIReader().Get(context.Background(), key, extensionSvc); err != nil {
-		return nil, fmt.Errorf("error getting rate limit extension service %s: %v", key, err)
+		return nil, fmt.Errorf("error getting %s extension service %s: %v", kind, key, err)
 	}
 
 	// get the response timeout from the ExtensionService
@@ -600,25 +653,131 @@ func (s *Server) setupRateLimitService(contourConfiguration contour_api_v1alpha1
 	if tp := extensionSvc.Spec.TimeoutPolicy; tp != nil {
 		responseTimeout, err = timeout.Parse(tp.Response)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing rate limit extension service %s response timeout: %v", key, err)
+			return nil, fmt.Errorf("error parsing %s extension service %s response timeout: %v", kind, key, err)
 		}
 	}
 
 	var sni string
-	if extensionSvc.Spec.UpstreamValidation != nil {
-		sni = extensionSvc.Spec.UpstreamValidation.SubjectName
+	if uv := extensionSvc.Spec.UpstreamValidation; uv != nil {
+		sni, err = dag.ExtensionServiceSNI(uv)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s extension service %s SNI: %v", kind, key, err)
+		}
+	}
+
+	return &extensionServiceConfig{
+		key:     key,
+		sni:     sni,
+		timeout: responseTimeout,
+	}, nil
+}
+
+func (s *Server) setupRateLimitService(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*xdscache_v3.RateLimitConfig, error) {
+	if contourConfiguration.RateLimitService == nil {
+		return nil, nil
+	}
+
+	extensionSvc, err := s.getExtensionServiceConfig(contourConfiguration.RateLimitService.ExtensionService, "rate limit")
+	if err != nil {
+		return nil, err
 	}
 
 	return &xdscache_v3.RateLimitConfig{
-		ExtensionService:        key,
-		SNI:                     sni,
+		ExtensionService:        extensionSvc.key,
+		SNI:                     extensionSvc.sni,
 		Domain:                  contourConfiguration.RateLimitService.Domain,
-		Timeout:                 responseTimeout,
+		Timeout:                 extensionSvc.timeout,
 		FailOpen:                pointer.BoolDeref(contourConfiguration.RateLimitService.FailOpen, false),
 		EnableXRateLimitHeaders: pointer.BoolDeref(contourConfiguration.RateLimitService.EnableXRateLimitHeaders, false),
 	}, nil
 }
 
+func (s *Server) setupAccessLogService(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*xdscache_v3.AccessLogServiceConfig, error) {
+	alsConfig := contourConfiguration.Envoy.Logging.AccessLogService
+	if alsConfig == nil {
+		return nil, nil
+	}
+
+	extensionSvc, err := s.getExtensionServiceConfig(alsConfig.ExtensionService, "access log")
+	if err != nil {
+		return nil, err
+	}
+
+	logName := alsConfig.LogName
+	if logName == "" {
+		logName = "contour"
+	}
+
+	return &xdscache_v3.AccessLogServiceConfig{
+		ExtensionService: extensionSvc.key,
+		SNI:              extensionSvc.sni,
+		Timeout:          extensionSvc.timeout,
+		LogName:          logName,
+	}, nil
+}
+
+func (s *Server) setupSecretDiscoveryService(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*dag.SecretDiscoveryService, error) {
+	sdsConfig := contourConfiguration.Envoy.SecretDiscoveryService
+	if sdsConfig == nil {
+		return nil, nil
+	}
+
+	extensionSvc, err := s.getExtensionServiceConfig(sdsConfig.ExtensionService, "secret discovery")
+	if err != nil {
+		return nil, err
+	}
+
+	return &dag.SecretDiscoveryService{
+		ExtensionService: extensionSvc.key,
+		SNI:              extensionSvc.sni,
+		Timeout:          extensionSvc.timeout,
+	}, nil
+}
+
+func (s *Server) setupTracingService(contourConfiguration contour_api_v1alpha1.ContourConfigurationSpec) (*xdscache_v3.TracingConfig, error) {
+	tracingConfig := contourConfiguration.Tracing
+	if tracingConfig == nil {
+		return nil, nil
+	}
+
+	extensionSvc, err := s.getExtensionServiceConfig(tracingConfig.ExtensionService, "tracing")
+	if err != nil {
+		return nil, err
+	}
+
+	// Sample every request unless told otherwise. The sampling rate
+	// has already been validated as part of the configuration.
+	overallSampling := 100.0
+	if tracingConfig.OverallSampling != nil {
+		if overallSampling, err = strconv.ParseFloat(*tracingConfig.OverallSampling, 64); err != nil {
+			return nil, fmt.Errorf("error parsing tracing overall sampling %q: %v", *tracingConfig.OverallSampling, err)
+		}
+	}
+
+	maxPathTagLength := uint32(256)
+	if tracingConfig.MaxPathTagLength != nil {
+		maxPathTagLength = *tracingConfig.MaxPathTagLength
+	}
+
+	var customTags []*xdscache_v3.CustomTag
+	for _, tag := range tracingConfig.CustomTags {
+		customTags = append(customTags, &xdscache_v3.CustomTag{
+			TagName:           tag.TagName,
+			Literal:           tag.Literal,
+			RequestHeaderName: tag.RequestHeaderName,
+		})
+	}
+
+	return &xdscache_v3.TracingConfig{
+		ExtensionService: extensionSvc.key,
+		SNI:              extensionSvc.sni,
+		Timeout:          extensionSvc.timeout,
+		OverallSampling:  overallSampling,
+		MaxPathTagLength: maxPathTagLength,
+		CustomTags:       customTags,
+	}, nil
+}
+
 func (s *Server) setupDebugService(debugConfig contour_api_v1alpha1.DebugConfig, builder *dag.Builder) error {
 	debugsvc := &debug.Service{
 		Service: httpsvc.Service{
@@ -635,6 +794,7 @@ type xdsServer struct {
 	log             logrus.FieldLogger
 	mgr             manager.Manager
 	registry        *prometheus.Registry
+	metrics         *metrics.Metrics
 	config          contour_api_v1alpha1.XDSServerConfig
 	snapshotHandler *xdscache.SnapshotHandler
 	resources       []xdscache.ResourceCache
@@ -653,7 +813,7 @@ func (x *xdsServer) Start(ctx context.Context) error {
 	}
 	log.Printf("informer caches synced")
 
-	grpcServer := xds.NewServer(x.registry, grpcOptions(log, x.config.TLS)...)
+	grpcServer := xds.NewServer(x.registry, grpcOptions(ctx, log, x.config.TLS, x.metrics)...)
 
 	switch x.config.Type {
 	case contour_api_v1alpha1.EnvoyServerType:
@@ -805,6 +965,11 @@ func (s *Server) setupGatewayAPI(contourConfiguration contour_api_v1alpha1.Conto
 			s.log.WithError(err).Fatal("failed to create tlsroute-controller")
 		}
 
+		// Create and register the TCPRoute controller with the manager.
+		if err := controller.RegisterTCPRouteController(s.log.WithField("context", "tcproute-controller"), mgr, eventHandler); err != nil {
+			s.log.WithError(err).Fatal("failed to create tcproute-controller")
+		}
+
 		// Inform on ReferencePolicies.
 		if err := informOnResource(&gatewayapi_v1alpha2.ReferencePolicy{}, eventHandler, mgr.GetCache()); err != nil {
 			s.log.WithError(err).WithField("resource", "referencepolicies").Fatal("failed to create informer")
@@ -833,8 +998,12 @@ type dagBuilderConfig struct {
 	dnsLookupFamily           contour_api_v1alpha1.ClusterDNSFamilyType
 	headersPolicy             *contour_api_v1alpha1.PolicyConfig
 	clientCert                *types.NamespacedName
+	secretDiscoveryService    *dag.SecretDiscoveryService
 	fallbackCert              *types.NamespacedName
 	connectTimeout            time.Duration
+	reservedContainerPorts    map[int32]string
+	accessLogService          bool
+	accessLogFormat           contour_api_v1alpha1.AccessLogType
 	client                    client.Client
 }
 
@@ -909,9 +1078,12 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 			FallbackCertificate:       dbc.fallbackCert,
 			DNSLookupFamily:           dbc.dnsLookupFamily,
 			ClientCertificate:         dbc.clientCert,
+			SecretDiscoveryService:    dbc.secretDiscoveryService,
 			RequestHeadersPolicy:      &requestHeadersPolicy,
 			ResponseHeadersPolicy:     &responseHeadersPolicy,
 			ConnectTimeout:            dbc.connectTimeout,
+			AccessLogService:          dbc.accessLogService,
+			AccessLogFormat:           dbc.accessLogFormat,
 		},
 	}
 
@@ -920,6 +1092,7 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 			EnableExternalNameService: dbc.enableExternalNameService,
 			FieldLogger:               s.log.WithField("context", "GatewayAPIProcessor"),
 			ConnectTimeout:            dbc.connectTimeout,
+			ReservedContainerPorts:    dbc.reservedContainerPorts,
 		})
 	}
 
@@ -953,6 +1126,31 @@ func (s *Server) getDAGBuilder(dbc dagBuilderConfig) *dag.Builder {
 	return builder
 }
 
+// endpointsTranslator is implemented by the xDS caches that
+// translate Kubernetes endpoint data into ClusterLoadAssignments.
+type endpointsTranslator interface {
+	cache.ResourceEventHandler
+	xdscache.ResourceCache
+	SetObserver(observer contour.Observer)
+}
+
+// reservedContainerPorts returns the Envoy ports used by listeners
+// other than Gateway listeners, keyed by port.
+func reservedContainerPorts(envoyConfig *contour_api_v1alpha1.EnvoyConfig) map[int32]string {
+	ports := map[int32]string{
+		int32(envoyConfig.Health.Port):             "health",
+		int32(envoyConfig.Metrics.Port):            "stats",
+		int32(*envoyConfig.Network.EnvoyAdminPort): "admin",
+	}
+
+	// The stats and health listeners usually share a port.
+	if envoyConfig.Health.Port == envoyConfig.Metrics.Port {
+		ports[int32(envoyConfig.Metrics.Port)] = "stats and health"
+	}
+
+	return ports
+}
+
 func informOnResource(obj client.Object, handler cache.ResourceEventHandler, cache ctrl_cache.Cache) error {
 	inf, err := cache.GetInformer(context.Background(), obj)
 	if err != nil {
@@ -14,18 +14,17 @@
 package main
 
 import (
-	"crypto/rand"
+	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"errors"
-	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/certwatcher"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/metrics"
 	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
 	"github.com/projectcontour/contour/pkg/config"
 
@@ -138,7 +137,7 @@ func newServeContext() *serveContext {
 // grpcOptions returns a slice of grpc.ServerOptions.
 // if ctx.PermitInsecureGRPC is false, the option set will
 // include TLS configuration.
-func grpcOptions(log logrus.FieldLogger, contourXDSConfig *contour_api_v1alpha1.TLS) []grpc.ServerOption {
+func grpcOptions(ctx context.Context, log logrus.FieldLogger, contourXDSConfig *contour_api_v1alpha1.TLS, metrics *metrics.Metrics) []grpc.ServerOption {
 	opts := []grpc.ServerOption{
 		// By default the Go grpc library defaults to a value of ~100 streams per
 		// connection. This number is likely derived from the HTTP/2 spec:
@@ -161,63 +160,42 @@ func grpcOptions(log logrus.FieldLogger, contourXDSConfig *contour_api_v1alpha1.
 	}
 
 	if !pointer.BoolDeref(contourXDSConfig.Insecure, false) {
-		tlsconfig := tlsconfig(log, contourXDSConfig)
+		tlsconfig := tlsconfig(ctx, log, contourXDSConfig, metrics)
 		creds := credentials.NewTLS(tlsconfig)
 		opts = append(opts, grpc.Creds(creds))
 	}
 	return opts
 }
 
-// tlsconfig returns a new *tls.Config. If the TLS parameters passed are not properly configured
-// for tls communication, tlsconfig returns nil.
-func tlsconfig(log logrus.FieldLogger, contourXDSTLS *contour_api_v1alpha1.TLS) *tls.Config {
+// tlsconfig returns a new *tls.Config. The certificates and key are reloaded
+// whenever the files change, until ctx is done, to ensure that the latest
+// certificates are used in case they have been rotated.
+func tlsconfig(ctx context.Context, log logrus.FieldLogger, contourXDSTLS *contour_api_v1alpha1.TLS, metrics *metrics.Metrics) *tls.Config {
 	err := verifyTLSFlags(contourXDSTLS)
 	if err != nil {
 		log.WithError(err).Fatal("failed to verify TLS flags")
 	}
 
-	// Define a closure that lazily loads certificates and key at TLS handshake
-	// to ensure that latest certificates are used in case they have been rotated.
-	loadConfig := func() (*tls.Config, error) {
-		if contourXDSTLS == nil {
-			return nil, nil
-		}
-		cert, err := tls.LoadX509KeyPair(contourXDSTLS.CertFile, contourXDSTLS.KeyFile)
-		if err != nil {
-			return nil, err
-		}
-
-		ca, err := os.ReadFile(contourXDSTLS.CAFile)
-		if err != nil {
-			return nil, err
-		}
-
-		certPool := x509.NewCertPool()
-		if ok := certPool.AppendCertsFromPEM(ca); !ok {
-			return nil, fmt.Errorf("unable to append certificate in %s to CA pool", contourXDSTLS.CAFile)
-		}
-
-		return &tls.Config{
-			Certificates: []tls.Certificate{cert},
-			ClientAuth:   tls.RequireAndVerifyClientCert,
-			ClientCAs:    certPool,
-			MinVersion:   tls.VersionTLS13,
-		}, nil
+	watcher := &certwatcher.Watcher{
+		CertFile:    contourXDSTLS.CertFile,
+		KeyFile:     contourXDSTLS.KeyFile,
+		CAFile:      contourXDSTLS.CAFile,
+		Metrics:     metrics,
+		FieldLogger: log.WithField("context", "certwatcher"),
 	}
 
 	// Attempt to load certificates and key to catch configuration errors early.
-	if _, lerr := loadConfig(); lerr != nil {
-		log.WithError(lerr).Fatal("failed to load certificate and key")
+	if err := watcher.Reload(); err != nil {
+		log.WithError(err).Fatal("failed to load certificate and key")
 	}
 
-	return &tls.Config{
-		MinVersion: tls.VersionTLS13,
-		ClientAuth: tls.RequireAndVerifyClientCert,
-		Rand:       rand.Reader,
-		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
-			return loadConfig()
-		},
-	}
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			log.WithError(err).Error("failed to watch certificate and key files")
+		}
+	}()
+
+	return watcher.TLSConfig()
 }
 
 // verifyTLSFlags indicates if the TLS flags are set up correctly.
@@ -317,6 +295,29 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 		accessLogLevel = contour_api_v1alpha1.LogLevelDisabled
 	}
 
+	var accessLogService *contour_api_v1alpha1.AccessLogServiceConfig
+	if ctx.Config.AccessLogService != nil {
+		nsedName := k8s.NamespacedNameFrom(ctx.Config.AccessLogService.ExtensionService)
+		accessLogService = &contour_api_v1alpha1.AccessLogServiceConfig{
+			ExtensionService: contour_api_v1alpha1.NamespacedName{
+				Name:      nsedName.Name,
+				Namespace: nsedName.Namespace,
+			},
+			LogName: ctx.Config.AccessLogService.LogName,
+		}
+	}
+
+	var secretDiscoveryService *contour_api_v1alpha1.SecretDiscoveryServiceConfig
+	if ctx.Config.SecretDiscoveryService != nil {
+		nsedName := k8s.NamespacedNameFrom(ctx.Config.SecretDiscoveryService.ExtensionService)
+		secretDiscoveryService = &contour_api_v1alpha1.SecretDiscoveryServiceConfig{
+			ExtensionService: contour_api_v1alpha1.NamespacedName{
+				Name:      nsedName.Name,
+				Namespace: nsedName.Namespace,
+			},
+		}
+	}
+
 	var defaultHTTPVersions []contour_api_v1alpha1.HTTPVersionType
 	for _, version := range ctx.Config.DefaultHTTPVersions {
 		switch version {
@@ -375,6 +376,30 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 		}
 	}
 
+	var tracing *contour_api_v1alpha1.TracingConfig
+	if ctx.Config.Tracing != nil {
+		nsedName := k8s.NamespacedNameFrom(ctx.Config.Tracing.ExtensionService)
+
+		var customTags []*contour_api_v1alpha1.CustomTag
+		for _, tag := range ctx.Config.Tracing.CustomTags {
+			customTags = append(customTags, &contour_api_v1alpha1.CustomTag{
+				TagName:           tag.TagName,
+				Literal:           tag.Literal,
+				RequestHeaderName: tag.RequestHeaderName,
+			})
+		}
+
+		tracing = &contour_api_v1alpha1.TracingConfig{
+			ExtensionService: contour_api_v1alpha1.NamespacedName{
+				Name:      nsedName.Name,
+				Namespace: nsedName.Namespace,
+			},
+			OverallSampling:  ctx.Config.Tracing.OverallSampling,
+			MaxPathTagLength: ctx.Config.Tracing.MaxPathTagLength,
+			CustomTags:       customTags,
+		}
+	}
+
 	policy := &contour_api_v1alpha1.PolicyConfig{
 		RequestHeadersPolicy: &contour_api_v1alpha1.HeadersPolicy{
 			Set:    ctx.Config.Policy.RequestHeadersPolicy.Set,
@@ -387,6 +412,13 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 		ApplyToIngress: pointer.Bool(ctx.Config.Policy.ApplyToIngress),
 	}
 
+	http3 := &contour_api_v1alpha1.EnvoyHTTP3{
+		Enabled: &ctx.Config.Listener.HTTP3.Enabled,
+	}
+	if ctx.Config.Listener.HTTP3.AdvertisedPort > 0 {
+		http3.AdvertisedPort = pointer.Int(ctx.Config.Listener.HTTP3.AdvertisedPort)
+	}
+
 	var clientCertificate *contour_api_v1alpha1.NamespacedName
 	if len(ctx.Config.TLS.ClientCertificate.Name) > 0 {
 		clientCertificate = &contour_api_v1alpha1.NamespacedName{
@@ -440,6 +472,7 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 				DisableAllowChunkedLength: &ctx.Config.DisableAllowChunkedLength,
 				DisableMergeSlashes:       &ctx.Config.DisableMergeSlashes,
 				ConnectionBalancer:        ctx.Config.Listener.ConnectionBalancer,
+				HTTP3:                     http3,
 				TLS: &contour_api_v1alpha1.EnvoyTLS{
 					MinimumProtocolVersion: ctx.Config.TLS.MinimumProtocolVersion,
 					CipherSuites:           cipherSuites,
@@ -464,12 +497,14 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 				Address: ctx.statsAddr,
 				Port:    ctx.statsPort,
 			},
-			ClientCertificate: clientCertificate,
+			ClientCertificate:      clientCertificate,
+			SecretDiscoveryService: secretDiscoveryService,
 			Logging: &contour_api_v1alpha1.EnvoyLogging{
 				AccessLogFormat:       accessLogFormat,
 				AccessLogFormatString: ctx.Config.AccessLogFormatString,
 				AccessLogJSONFields:   accessLogFields,
 				AccessLogLevel:        accessLogLevel,
+				AccessLogService:      accessLogService,
 			},
 			DefaultHTTPVersions: defaultHTTPVersions,
 			Timeouts:            timeoutParams,
@@ -489,8 +524,10 @@ func (ctx *serveContext) convertToContourConfigurationSpec() contour_api_v1alpha
 		},
 		EnableExternalNameService: &ctx.Config.EnableExternalNameService,
 		RateLimitService:          rateLimitService,
+		Tracing:                   tracing,
 		Policy:                    policy,
 		Metrics:                   &contourMetrics,
+		FeatureFlags:              ctx.Config.FeatureFlags,
 	}
 
 	xdsServerType := contour_api_v1alpha1.ContourServerType
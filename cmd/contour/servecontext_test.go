@@ -14,6 +14,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"net"
@@ -202,8 +203,10 @@ func TestServeContextCertificateHandling(t *testing.T) {
 	checkFatalErr(t, err)
 
 	// Start a dummy server.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	log := fixture.NewTestLogger(t)
-	opts := grpcOptions(log, contourTLS)
+	opts := grpcOptions(ctx, log, contourTLS, nil)
 	g := grpc.NewServer(opts...)
 	if g == nil {
 		t.Error("failed to create server")
@@ -225,15 +228,19 @@ func TestServeContextCertificateHandling(t *testing.T) {
 			err = tc.serverCredentials.WritePEM(contourTLS.CertFile, contourTLS.KeyFile)
 			checkFatalErr(t, err)
 			clientCert, _ := tc.clientCredentials.TLSCertificate()
-			receivedCert, err := tryConnect(address, clientCert, caCertPool)
-			gotError := err != nil
-			if gotError != tc.expectError {
-				t.Errorf("Unexpected result when connecting to the server: %s", err)
-			}
-			if err == nil {
-				expectedCert, _ := tc.serverCredentials.X509Certificate()
-				assert.Equal(t, receivedCert, &expectedCert)
+
+			if tc.expectError {
+				_, err := tryConnect(address, clientCert, caCertPool)
+				assert.Error(t, err)
+				return
 			}
+
+			// The server reloads the files asynchronously once they change.
+			expectedCert, _ := tc.serverCredentials.X509Certificate()
+			assert.Eventually(t, func() bool {
+				receivedCert, err := tryConnect(address, clientCert, caCertPool)
+				return err == nil && receivedCert.Equal(&expectedCert)
+			}, 5*time.Second, 50*time.Millisecond)
 		})
 	}
 }
@@ -267,7 +274,9 @@ func TestTlsVersionDeprecation(t *testing.T) {
 
 	// Get preliminary TLS config from the serveContext.
 	log := fixture.NewTestLogger(t)
-	preliminaryTLSConfig := tlsconfig(log, contourTLS)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	preliminaryTLSConfig := tlsconfig(ctx, log, contourTLS, nil)
 
 	// Get actual TLS config that will be used during TLS handshake.
 	tlsConfig, err := preliminaryTLSConfig.GetConfigForClient(nil)
@@ -409,6 +418,9 @@ func TestConvertServeContext(t *testing.T) {
 					UseProxyProto:             pointer.Bool(false),
 					DisableAllowChunkedLength: pointer.Bool(false),
 					DisableMergeSlashes:       pointer.Bool(false),
+					HTTP3: &contour_api_v1alpha1.EnvoyHTTP3{
+						Enabled: pointer.Bool(false),
+					},
 					TLS: &contour_api_v1alpha1.EnvoyTLS{
 						MinimumProtocolVersion: "",
 					},
@@ -642,6 +654,37 @@ func TestConvertServeContext(t *testing.T) {
 				return cfg
 			},
 		},
+		"tracing": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				maxPathTagLength := uint32(128)
+				ctx.Config.Tracing = &config.Tracing{
+					ExtensionService: "otel/otel-collector",
+					OverallSampling:  pointer.String("25"),
+					MaxPathTagLength: &maxPathTagLength,
+					CustomTags: []config.CustomTag{
+						{TagName: "cluster", Literal: "west"},
+						{TagName: "user-agent", RequestHeaderName: "User-Agent"},
+					},
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				maxPathTagLength := uint32(128)
+				cfg.Tracing = &contour_api_v1alpha1.TracingConfig{
+					ExtensionService: contour_api_v1alpha1.NamespacedName{
+						Name:      "otel-collector",
+						Namespace: "otel",
+					},
+					OverallSampling:  pointer.String("25"),
+					MaxPathTagLength: &maxPathTagLength,
+					CustomTags: []*contour_api_v1alpha1.CustomTag{
+						{TagName: "cluster", Literal: "west"},
+						{TagName: "user-agent", RequestHeaderName: "User-Agent"},
+					},
+				}
+				return cfg
+			},
+		},
 		"default http versions": {
 			getServeContext: func(ctx *serveContext) *serveContext {
 				ctx.Config.DefaultHTTPVersions = []config.HTTPVersionType{
@@ -675,6 +718,42 @@ func TestConvertServeContext(t *testing.T) {
 				return cfg
 			},
 		},
+		"access log service": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.AccessLogService = &config.AccessLogService{
+					ExtensionService: "logging/als",
+					LogName:          "edge",
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Logging.AccessLogService = &contour_api_v1alpha1.AccessLogServiceConfig{
+					ExtensionService: contour_api_v1alpha1.NamespacedName{
+						Name:      "als",
+						Namespace: "logging",
+					},
+					LogName: "edge",
+				}
+				return cfg
+			},
+		},
+		"secret discovery service": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.SecretDiscoveryService = &config.SecretDiscoveryService{
+					ExtensionService: "spire/spire-agent",
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.SecretDiscoveryService = &contour_api_v1alpha1.SecretDiscoveryServiceConfig{
+					ExtensionService: contour_api_v1alpha1.NamespacedName{
+						Name:      "spire-agent",
+						Namespace: "spire",
+					},
+				}
+				return cfg
+			},
+		},
 		"disable merge slashes": {
 			getServeContext: func(ctx *serveContext) *serveContext {
 				ctx.Config.DisableMergeSlashes = true
@@ -685,6 +764,32 @@ func TestConvertServeContext(t *testing.T) {
 				return cfg
 			},
 		},
+		"http3": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.Listener.HTTP3 = config.HTTP3Parameters{
+					Enabled:        true,
+					AdvertisedPort: 8443,
+				}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.Envoy.Listener.HTTP3 = &contour_api_v1alpha1.EnvoyHTTP3{
+					Enabled:        pointer.Bool(true),
+					AdvertisedPort: pointer.Int(8443),
+				}
+				return cfg
+			},
+		},
+		"feature flags": {
+			getServeContext: func(ctx *serveContext) *serveContext {
+				ctx.Config.FeatureFlags = []string{"useEndpointSlices"}
+				return ctx
+			},
+			getContourConfiguration: func(cfg contour_api_v1alpha1.ContourConfigurationSpec) contour_api_v1alpha1.ContourConfigurationSpec {
+				cfg.FeatureFlags = contour_api_v1alpha1.FeatureFlags{contour_api_v1alpha1.FeatureUseEndpointSlices}
+				return cfg
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -15,15 +15,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
-	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -32,19 +35,66 @@ import (
 )
 
 const (
-	prometheusURL      = "http://unix/stats/prometheus"
-	healthcheckFailURL = "http://unix/healthcheck/fail"
-	prometheusStat     = "envoy_http_downstream_cx_active"
+	prometheusURL          = "http://unix/stats/prometheus"
+	healthcheckFailURL     = "http://unix/healthcheck/fail"
+	drainListenersURL      = "http://unix/drain_listeners?inboundonly"
+	prometheusStat         = "envoy_http_downstream_cx_active"
+	prometheusRequestsStat = "envoy_http_downstream_rq_active"
 )
 
 // shutdownReadyFile is the default file path used in the /shutdown endpoint.
 const shutdownReadyFile = "/admin/ok"
 
+// shutdownStatusFile is the default file path used in the /shutdown/status endpoint.
+const shutdownStatusFile = "/admin/shutdown-status.json"
+
 // shutdownReadyCheckInterval is the default polling interval for the file used in the /shutdown endpoint.
 const shutdownReadyCheckInterval = time.Second * 1
 
-func prometheusLabels() []string {
-	return []string{xdscache_v3.ENVOY_HTTP_LISTENER, xdscache_v3.ENVOY_HTTPS_LISTENER}
+const (
+	// drainStrategyGradual fails Envoy's health checks and lets
+	// Envoy drain connections over its configured drain time.
+	drainStrategyGradual = "gradual"
+
+	// drainStrategyImmediate additionally stops Envoy's inbound
+	// listeners from accepting new connections straight away.
+	drainStrategyImmediate = "immediate"
+)
+
+// Drain phases reported by the /shutdown/status endpoint.
+const (
+	drainPhaseNotStarted       = "NotStarted"
+	drainPhaseDraining         = "Draining"
+	drainPhaseDrained          = "Drained"
+	drainPhaseDeadlineExceeded = "DeadlineExceeded"
+)
+
+// prometheusListenerLabel is the label that holds the stat
+// prefix of the HTTP connection manager of a listener.
+const prometheusListenerLabel = "envoy_http_conn_manager_prefix"
+
+// ingressListenerPrefix is the stat prefix shared by all of
+// Envoy's ingress listeners, including those for Gateway
+// listeners on other ports.
+const ingressListenerPrefix = "ingress_"
+
+// drainStats holds the stats polled from Envoy while draining.
+type drainStats struct {
+	openConnections int
+	activeRequests  int
+}
+
+// drainStatus is the JSON progress report written by the shutdown
+// command and served from the /shutdown/status endpoint.
+type drainStatus struct {
+	Phase           string     `json:"phase"`
+	Strategy        string     `json:"strategy,omitempty"`
+	Listeners       []string   `json:"listeners,omitempty"`
+	OpenConnections *int       `json:"openConnections,omitempty"`
+	ActiveRequests  *int       `json:"activeRequests,omitempty"`
+	StartTime       *time.Time `json:"startTime,omitempty"`
+	Deadline        *time.Time `json:"deadline,omitempty"`
+	LastUpdateTime  *time.Time `json:"lastUpdateTime,omitempty"`
 }
 
 type shutdownmanagerContext struct {
@@ -54,6 +104,8 @@ type shutdownmanagerContext struct {
 	shutdownReadyFile string
 	// shutdownReadyCheckInterval is the polling interval for the file used in the /shutdown endpoint
 	shutdownReadyCheckInterval time.Duration
+	// shutdownStatusFile is the file path served from the /shutdown/status endpoint
+	shutdownStatusFile string
 
 	logrus.FieldLogger
 }
@@ -72,6 +124,24 @@ type shutdownContext struct {
 	// that can be open when polling for active connections in Envoy
 	minOpenConnections int
 
+	// minActiveRequests defines the minimum amount of requests that
+	// can be active when polling Envoy. A negative value disables
+	// the check.
+	minActiveRequests int
+
+	// maxDrainDuration defines the maximum time to wait for Envoy to
+	// drain before signalling that shutdown is completed. Zero means
+	// no deadline.
+	maxDrainDuration time.Duration
+
+	// drainStrategy defines how Envoy is told to drain, either
+	// "gradual" or "immediate".
+	drainStrategy string
+
+	// listeners defines the Envoy listeners whose stats are polled.
+	// If empty, all ingress listeners are polled.
+	listeners []string
+
 	// Deprecated: adminPort defines the port for the Envoy admin webpage, being configurable through --admin-port flag
 	adminPort int
 
@@ -81,6 +151,9 @@ type shutdownContext struct {
 	// shutdownReadyFile defines the name of the file that is used to signal that shutdown is completed.
 	shutdownReadyFile string
 
+	// shutdownStatusFile defines the name of the file that drain progress is written to.
+	shutdownStatusFile string
+
 	logrus.FieldLogger
 }
 
@@ -90,6 +163,7 @@ func newShutdownManagerContext() *shutdownmanagerContext {
 		httpServePort:              8090,
 		shutdownReadyFile:          shutdownReadyFile,
 		shutdownReadyCheckInterval: shutdownReadyCheckInterval,
+		shutdownStatusFile:         shutdownStatusFile,
 	}
 }
 
@@ -99,6 +173,9 @@ func newShutdownContext() *shutdownContext {
 		checkDelay:         0,
 		drainDelay:         0,
 		minOpenConnections: 0,
+		minActiveRequests:  -1,
+		maxDrainDuration:   0,
+		drainStrategy:      drainStrategyGradual,
 	}
 }
 
@@ -142,9 +219,50 @@ func (s *shutdownmanagerContext) shutdownReadyHandler(w http.ResponseWriter, r *
 	}
 }
 
+// shutdownStatusHandler handles the /shutdown/status endpoint which reports the progress
+// of the shutdown sequence as JSON. Like /shutdown, it relies on a file written by the
+// shutdown command since that runs in a different process.
+func (s *shutdownmanagerContext) shutdownStatusHandler(w http.ResponseWriter, r *http.Request) {
+	l := s.WithField("context", "shutdownStatusHandler")
+
+	data, err := os.ReadFile(s.shutdownStatusFile)
+	switch {
+	case os.IsNotExist(err):
+		data, err = json.Marshal(drainStatus{Phase: drainPhaseNotStarted})
+		if err != nil {
+			l.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case err != nil:
+		l.Errorf("error reading file %s: %v", s.shutdownStatusFile, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		l.Error(err)
+	}
+}
+
 // shutdownHandler is called from a pod preStop hook, where it will block pod shutdown
-// until envoy is able to drain connections to below the min-open threshold.
+// until envoy is able to drain connections to below the min-open threshold, or the
+// max drain duration has passed.
 func (s *shutdownContext) shutdownHandler() {
+	status := drainStatus{
+		Phase:     drainPhaseDraining,
+		Strategy:  s.drainStrategy,
+		Listeners: s.listeners,
+	}
+	startTime := time.Now()
+	status.StartTime = &startTime
+	if s.maxDrainDuration > 0 {
+		deadline := startTime.Add(s.maxDrainDuration)
+		status.Deadline = &deadline
+	}
+	s.writeStatus(status)
+
 	s.WithField("context", "shutdownHandler").Infof("waiting %s before draining connections", s.drainDelay)
 	time.Sleep(s.drainDelay)
 
@@ -172,115 +290,234 @@ func (s *shutdownContext) shutdownHandler() {
 		s.WithField("context", "shutdownHandler").Errorf("error sending envoy healthcheck fail after 4 attempts: %v", err)
 	}
 
+	if s.drainStrategy == drainStrategyImmediate {
+		s.Infof("draining envoy listeners")
+		if err := drainListeners(s.adminAddress); err != nil {
+			s.WithField("context", "shutdownHandler").Errorf("error draining envoy listeners: %v", err)
+		}
+	}
+
 	s.WithField("context", "shutdownHandler").Infof("waiting %s before polling for draining connections", s.checkDelay)
 	time.Sleep(s.checkDelay)
 
 	for {
-		openConnections, err := getOpenConnections(s.adminAddress)
+		stats, err := getDrainStats(s.adminAddress, s.listeners, s.minActiveRequests >= 0)
 		if err != nil {
 			s.Error(err)
 		} else {
-			if openConnections <= s.minOpenConnections {
-				s.WithField("context", "shutdownHandler").
-					WithField("open_connections", openConnections).
-					WithField("min_connections", s.minOpenConnections).
-					Info("min number of open connections found, shutting down")
-				file, err := os.Create(s.shutdownReadyFile)
-				if err != nil {
-					s.Error(err)
-				}
-				defer file.Close()
+			now := time.Now()
+			status.OpenConnections = &stats.openConnections
+			status.LastUpdateTime = &now
+			if s.minActiveRequests >= 0 {
+				status.ActiveRequests = &stats.activeRequests
+			}
+
+			l := s.WithField("context", "shutdownHandler").
+				WithField("open_connections", stats.openConnections).
+				WithField("min_connections", s.minOpenConnections)
+			if s.minActiveRequests >= 0 {
+				l = l.WithField("active_requests", stats.activeRequests).
+					WithField("min_requests", s.minActiveRequests)
+			}
+
+			if s.drained(stats) {
+				l.Info("min number of open connections found, shutting down")
+				status.Phase = drainPhaseDrained
+				s.writeStatus(status)
+				s.writeReadyFile()
 				return
 			}
+			l.Info("polled open connections")
+			s.writeStatus(status)
+		}
+
+		if status.Deadline != nil && !time.Now().Before(*status.Deadline) {
 			s.WithField("context", "shutdownHandler").
-				WithField("open_connections", openConnections).
-				WithField("min_connections", s.minOpenConnections).
-				Info("polled open connections")
+				WithField("max_drain_duration", s.maxDrainDuration).
+				Info("max drain duration exceeded, shutting down")
+			status.Phase = drainPhaseDeadlineExceeded
+			s.writeStatus(status)
+			s.writeReadyFile()
+			return
 		}
+
 		time.Sleep(s.checkInterval)
 	}
 }
 
-// shutdownEnvoy sends a POST request to /healthcheck/fail to tell Envoy to start draining connections
-func shutdownEnvoy(adminAddress string) error {
+// drained returns true if the polled stats are within the configured thresholds.
+func (s *shutdownContext) drained(stats drainStats) bool {
+	if stats.openConnections > s.minOpenConnections {
+		return false
+	}
+	return s.minActiveRequests < 0 || stats.activeRequests <= s.minActiveRequests
+}
 
-	httpClient := http.Client{
+// writeReadyFile creates the file used to signal that shutdown is completed.
+func (s *shutdownContext) writeReadyFile() {
+	file, err := os.Create(s.shutdownReadyFile)
+	if err != nil {
+		s.Error(err)
+		return
+	}
+	if err := file.Close(); err != nil {
+		s.Error(err)
+	}
+}
+
+// writeStatus writes the drain status to the status file. The file is
+// written to a temporary path then renamed so that readers never observe
+// a partially written file.
+func (s *shutdownContext) writeStatus(status drainStatus) {
+	if s.shutdownStatusFile == "" {
+		return
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		s.Error(err)
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.shutdownStatusFile), filepath.Base(s.shutdownStatusFile)+".*")
+	if err != nil {
+		s.Error(err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		s.Error(err)
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		s.Error(err)
+		return
+	}
+	if err := os.Rename(tmp.Name(), s.shutdownStatusFile); err != nil {
+		s.Error(err)
+	}
+}
+
+// adminClient returns an HTTP client that talks to the Envoy admin unix socket.
+func adminClient(adminAddress string) *http.Client {
+	return &http.Client{
 		Transport: &http.Transport{
 			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
 				return net.Dial("unix", adminAddress)
 			},
 		},
 	}
+}
+
+// shutdownEnvoy sends a POST request to /healthcheck/fail to tell Envoy to start draining connections
+func shutdownEnvoy(adminAddress string) error {
+	return adminPost(adminAddress, healthcheckFailURL)
+}
+
+// drainListeners sends a POST request to /drain_listeners to tell Envoy to stop
+// accepting new connections on its inbound listeners.
+func drainListeners(adminAddress string) error {
+	return adminPost(adminAddress, drainListenersURL)
+}
+
+func adminPost(adminAddress string, url string) error {
 	/* #nosec */
-	resp, err := httpClient.Post(healthcheckFailURL, "", nil)
+	resp, err := adminClient(adminAddress).Post(url, "", nil)
 	if err != nil {
-		return fmt.Errorf("creating healthcheck fail POST request failed: %s", err)
+		return fmt.Errorf("creating POST request for %q failed: %s", url, err)
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("POST for %q returned HTTP status %s", healthcheckFailURL, resp.Status)
+		return fmt.Errorf("POST for %q returned HTTP status %s", url, resp.Status)
 	}
 	return nil
 }
 
-// getOpenConnections parses a http request to a prometheus endpoint returning the sum of values found
-func getOpenConnections(adminAddress string) (int, error) {
-
-	httpClient := http.Client{
-		Transport: &http.Transport{
-			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
-				return net.Dial("unix", adminAddress)
-			},
-		},
-	}
-
+// getDrainStats parses a http request to a prometheus endpoint returning the
+// sum of open connections, and optionally active requests, across listeners.
+func getDrainStats(adminAddress string, listeners []string, activeRequests bool) (drainStats, error) {
 	// Make request to Envoy Prometheus endpoint
 	/* #nosec */
-	resp, err := httpClient.Get(prometheusURL)
+	resp, err := adminClient(adminAddress).Get(prometheusURL)
 	if err != nil {
-		return -1, fmt.Errorf("creating metrics GET request failed: %s", err)
+		return drainStats{-1, -1}, fmt.Errorf("creating metrics GET request failed: %s", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return -1, fmt.Errorf("GET for %q returned HTTP status %s", prometheusURL, resp.Status)
+		return drainStats{-1, -1}, fmt.Errorf("GET for %q returned HTTP status %s", prometheusURL, resp.Status)
 	}
 
 	// Parse Prometheus listener stats for open connections
-	return parseOpenConnections(resp.Body)
+	return parseDrainStats(resp.Body, listeners, activeRequests)
 }
 
-// parseOpenConnections returns the sum of open connections from a Prometheus HTTP request
-func parseOpenConnections(stats io.Reader) (int, error) {
+// parseDrainStats returns the sum of open connections, and optionally active
+// requests, for the given listeners from a Prometheus HTTP request.
+func parseDrainStats(stats io.Reader, listeners []string, activeRequests bool) (drainStats, error) {
 	var parser expfmt.TextParser
-	openConnections := 0
+	result := drainStats{-1, -1}
 
 	if stats == nil {
-		return -1, fmt.Errorf("stats input was nil")
+		return result, fmt.Errorf("stats input was nil")
 	}
 
 	// Parse Prometheus http response
 	metricFamilies, err := parser.TextToMetricFamilies(stats)
 	if err != nil {
-		return -1, fmt.Errorf("parsing Prometheus text format failed: %v", err)
+		return result, fmt.Errorf("parsing Prometheus text format failed: %v", err)
+	}
+
+	openConnections, err := sumListenerGauge(metricFamilies, prometheusStat, listeners)
+	if err != nil {
+		return result, err
 	}
+	result.openConnections = openConnections
 
+	if activeRequests {
+		activeRequests, err := sumListenerGauge(metricFamilies, prometheusRequestsStat, listeners)
+		if err != nil {
+			return drainStats{-1, -1}, err
+		}
+		result.activeRequests = activeRequests
+	}
+
+	return result, nil
+}
+
+// sumListenerGauge returns the sum of the values of the named gauge for the given listeners.
+func sumListenerGauge(metricFamilies map[string]*dto.MetricFamily, stat string, listeners []string) (int, error) {
 	// Validate stat exists in output
-	if _, ok := metricFamilies[prometheusStat]; !ok {
-		return -1, fmt.Errorf("error finding Prometheus stat %q in the request result", prometheusStat)
+	if _, ok := metricFamilies[stat]; !ok {
+		return -1, fmt.Errorf("error finding Prometheus stat %q in the request result", stat)
 	}
 
-	// Look up open connections value
-	for _, metrics := range metricFamilies[prometheusStat].Metric {
-		for _, labels := range metrics.Label {
-			for _, item := range prometheusLabels() {
-				if item == labels.GetValue() {
-					openConnections += int(metrics.Gauge.GetValue())
-				}
+	sum := 0
+	for _, metrics := range metricFamilies[stat].Metric {
+		for _, label := range metrics.Label {
+			if label.GetName() == prometheusListenerLabel && listenerSelected(label.GetValue(), listeners) {
+				sum += int(metrics.Gauge.GetValue())
 			}
 		}
 	}
-	return openConnections, nil
+	return sum, nil
+}
+
+// listenerSelected returns true if the named listener is one of the given
+// listeners, or is an ingress listener if no listeners are given.
+func listenerSelected(name string, listeners []string) bool {
+	if len(listeners) == 0 {
+		return strings.HasPrefix(name, ingressListenerPrefix)
+	}
+
+	for _, l := range listeners {
+		if l == name {
+			return true
+		}
+	}
+	return false
 }
 
 func doShutdownManager(config *shutdownmanagerContext) {
@@ -289,6 +526,7 @@ func doShutdownManager(config *shutdownmanagerContext) {
 
 	http.HandleFunc("/healthz", config.healthzHandler)
 	http.HandleFunc("/shutdown", config.shutdownReadyHandler)
+	http.HandleFunc("/shutdown/status", config.shutdownStatusHandler)
 
 	if err := http.ListenAndServe(fmt.Sprintf(":%d", config.httpServePort), nil); err != http.ErrServerClosed {
 		log.Fatal(err)
@@ -304,6 +542,7 @@ func registerShutdownManager(cmd *kingpin.CmdClause, log logrus.FieldLogger) (*k
 	shutdownmgr := cmd.Command("shutdown-manager", "Start envoy shutdown-manager.")
 	shutdownmgr.Flag("serve-port", "Port to serve the http server on.").IntVar(&ctx.httpServePort)
 	shutdownmgr.Flag("ready-file", "File to poll while waiting shutdown to be completed.").Default(shutdownReadyFile).StringVar(&ctx.shutdownReadyFile)
+	shutdownmgr.Flag("status-file", "File to serve from the /shutdown/status endpoint.").Default(shutdownStatusFile).StringVar(&ctx.shutdownStatusFile)
 
 	return shutdownmgr, ctx
 }
@@ -320,7 +559,12 @@ func registerShutdown(cmd *kingpin.CmdClause, log logrus.FieldLogger) (*kingpin.
 	shutdown.Flag("check-delay", "Time to wait before polling Envoy for open connections.").Default("0s").DurationVar(&ctx.checkDelay)
 	shutdown.Flag("drain-delay", "Time to wait before draining Envoy connections.").Default("0s").DurationVar(&ctx.drainDelay)
 	shutdown.Flag("min-open-connections", "Min number of open connections when polling Envoy.").IntVar(&ctx.minOpenConnections)
+	shutdown.Flag("min-active-requests", "Min number of active requests when polling Envoy. A negative value disables the check.").Default("-1").IntVar(&ctx.minActiveRequests)
+	shutdown.Flag("max-drain-duration", "Max time to wait for Envoy to drain before completing shutdown. Zero means no limit.").Default("0s").DurationVar(&ctx.maxDrainDuration)
+	shutdown.Flag("drain-strategy", "How Envoy drains connections, either gradual or immediate.").Default(drainStrategyGradual).EnumVar(&ctx.drainStrategy, drainStrategyGradual, drainStrategyImmediate)
+	shutdown.Flag("listener", "Envoy listener to poll for open connections; may be repeated. Defaults to all ingress listeners.").StringsVar(&ctx.listeners)
 	shutdown.Flag("ready-file", "File to write when shutdown is completed.").Default(shutdownReadyFile).StringVar(&ctx.shutdownReadyFile)
+	shutdown.Flag("status-file", "File to write drain progress to.").Default(shutdownStatusFile).StringVar(&ctx.shutdownStatusFile)
 
 	return shutdown, ctx
 }
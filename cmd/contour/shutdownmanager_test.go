@@ -15,8 +15,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -134,11 +136,148 @@ func TestShutdownManager_ShutdownReadyHandler_ClientCancel(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 }
 
-func TestParseOpenConnections(t *testing.T) {
+func TestShutdownManager_ShutdownStatusHandler(t *testing.T) {
+	mgr := newShutdownManagerContext()
+	mgr.FieldLogger = fixture.NewTestLogger(t)
+	mgr.shutdownStatusFile = path.Join(t.TempDir(), "status.json")
+
+	get := func() *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/shutdown/status", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(mgr.shutdownStatusHandler).ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Before shutdown has started there is no status file.
+	rr := get()
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"phase":"NotStarted"}`, rr.Body.String())
+
+	status := `{"phase":"Draining","strategy":"gradual","listeners":["ingress_http"],"openConnections":3}`
+	if err := os.WriteFile(mgr.shutdownStatusFile, []byte(status), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rr = get()
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, status, rr.Body.String())
+}
+
+func TestShutdownHandler(t *testing.T) {
 	type testcase struct {
-		stats           io.Reader
-		wantConnections int
-		wantError       error
+		stats             string
+		strategy          string
+		minActiveRequests int
+		maxDrainDuration  time.Duration
+		wantPhase         string
+		wantDrain         bool
+	}
+
+	run := func(t *testing.T, name string, tc testcase) {
+		t.Helper()
+
+		t.Run(name, func(t *testing.T) {
+			// Unix socket paths are length limited, so avoid t.TempDir().
+			dir, err := os.MkdirTemp("", "sdm")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			var healthcheckFailed, drained bool
+			mux := http.NewServeMux()
+			mux.HandleFunc("/healthcheck/fail", func(w http.ResponseWriter, r *http.Request) {
+				healthcheckFailed = true
+			})
+			mux.HandleFunc("/drain_listeners", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "inboundonly", r.URL.RawQuery)
+				drained = true
+			})
+			mux.HandleFunc("/stats/prometheus", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tc.stats))
+			})
+
+			l, err := net.Listen("unix", path.Join(dir, "admin.sock"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := httptest.NewUnstartedServer(mux)
+			srv.Listener = l
+			srv.Start()
+			defer srv.Close()
+
+			s := newShutdownContext()
+			s.FieldLogger = fixture.NewTestLogger(t)
+			s.adminAddress = path.Join(dir, "admin.sock")
+			s.checkInterval = 10 * time.Millisecond
+			s.drainStrategy = tc.strategy
+			s.minActiveRequests = tc.minActiveRequests
+			s.maxDrainDuration = tc.maxDrainDuration
+			s.shutdownReadyFile = path.Join(dir, "ok")
+			s.shutdownStatusFile = path.Join(dir, "status.json")
+
+			s.shutdownHandler()
+
+			assert.True(t, healthcheckFailed)
+			assert.Equal(t, tc.wantDrain, drained)
+			assert.FileExists(t, s.shutdownReadyFile)
+
+			data, err := os.ReadFile(s.shutdownStatusFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var status drainStatus
+			if err := json.Unmarshal(data, &status); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.wantPhase, status.Phase)
+			assert.Equal(t, tc.strategy, status.Strategy)
+			assert.Empty(t, status.Listeners)
+		})
+	}
+
+	run(t, "drained", testcase{
+		stats:             DRAINED,
+		strategy:          drainStrategyGradual,
+		minActiveRequests: -1,
+		wantPhase:         drainPhaseDrained,
+	})
+
+	run(t, "immediate drain strategy", testcase{
+		stats:             DRAINED,
+		strategy:          drainStrategyImmediate,
+		minActiveRequests: -1,
+		wantPhase:         drainPhaseDrained,
+		wantDrain:         true,
+	})
+
+	run(t, "active requests outstanding until deadline", testcase{
+		stats:             WITHREQUESTS,
+		strategy:          drainStrategyGradual,
+		minActiveRequests: 0,
+		maxDrainDuration:  50 * time.Millisecond,
+		wantPhase:         drainPhaseDeadlineExceeded,
+	})
+
+	run(t, "open connections outstanding until deadline", testcase{
+		stats:             VALIDBOTH,
+		strategy:          drainStrategyGradual,
+		minActiveRequests: -1,
+		maxDrainDuration:  50 * time.Millisecond,
+		wantPhase:         drainPhaseDeadlineExceeded,
+	})
+}
+
+func TestParseDrainStats(t *testing.T) {
+	type testcase struct {
+		stats          io.Reader
+		listeners      []string
+		activeRequests bool
+		wantStats      drainStats
+		wantError      error
 	}
 
 	run := func(t *testing.T, name string, tc testcase) {
@@ -147,46 +286,73 @@ func TestParseOpenConnections(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Helper()
 
-			gotConnections, gotError := parseOpenConnections(tc.stats)
+			gotStats, gotError := parseDrainStats(tc.stats, tc.listeners, tc.activeRequests)
 			assert.Equal(t, tc.wantError, gotError)
-			assert.Equal(t, tc.wantConnections, gotConnections)
+			assert.Equal(t, tc.wantStats, gotStats)
 		})
 	}
 
 	run(t, "nil stats", testcase{
-		stats:           nil,
-		wantConnections: -1,
-		wantError:       fmt.Errorf("stats input was nil"),
+		stats:     nil,
+		wantStats: drainStats{-1, -1},
+		wantError: fmt.Errorf("stats input was nil"),
 	})
 
 	run(t, "basic http only", testcase{
-		stats:           strings.NewReader(VALIDHTTP),
-		wantConnections: 4,
-		wantError:       nil,
+		stats:     strings.NewReader(VALIDHTTP),
+		wantStats: drainStats{4, -1},
+		wantError: nil,
 	})
 
 	run(t, "basic https only", testcase{
-		stats:           strings.NewReader(VALIDHTTPS),
-		wantConnections: 4,
-		wantError:       nil,
+		stats:     strings.NewReader(VALIDHTTPS),
+		wantStats: drainStats{4, -1},
+		wantError: nil,
 	})
 
 	run(t, "basic both protocols", testcase{
-		stats:           strings.NewReader(VALIDBOTH),
-		wantConnections: 8,
-		wantError:       nil,
+		stats:     strings.NewReader(VALIDBOTH),
+		wantStats: drainStats{8, -1},
+		wantError: nil,
+	})
+
+	run(t, "all ingress listeners", testcase{
+		stats:     strings.NewReader(WITHGATEWAYLISTENERS),
+		wantStats: drainStats{11, -1},
+		wantError: nil,
+	})
+
+	run(t, "filtered to https listener", testcase{
+		stats:     strings.NewReader(VALIDBOTH),
+		listeners: []string{"ingress_https"},
+		wantStats: drainStats{4, -1},
+		wantError: nil,
+	})
+
+	run(t, "active requests", testcase{
+		stats:          strings.NewReader(WITHREQUESTS),
+		activeRequests: true,
+		wantStats:      drainStats{8, 3},
+		wantError:      nil,
+	})
+
+	run(t, "missing active requests", testcase{
+		stats:          strings.NewReader(VALIDBOTH),
+		activeRequests: true,
+		wantStats:      drainStats{-1, -1},
+		wantError:      fmt.Errorf("error finding Prometheus stat \"envoy_http_downstream_rq_active\" in the request result"),
 	})
 
 	run(t, "missing values", testcase{
-		stats:           strings.NewReader(MISSING_STATS),
-		wantConnections: -1,
-		wantError:       fmt.Errorf("error finding Prometheus stat \"envoy_http_downstream_cx_active\" in the request result"),
+		stats:     strings.NewReader(MISSING_STATS),
+		wantStats: drainStats{-1, -1},
+		wantError: fmt.Errorf("error finding Prometheus stat \"envoy_http_downstream_cx_active\" in the request result"),
 	})
 
 	run(t, "invalid stats", testcase{
-		stats:           strings.NewReader("!!##$$##!!"),
-		wantConnections: -1,
-		wantError:       fmt.Errorf("parsing Prometheus text format failed: text format parsing error in line 1: invalid metric name"),
+		stats:     strings.NewReader("!!##$$##!!"),
+		wantStats: drainStats{-1, -1},
+		wantError: fmt.Errorf("parsing Prometheus text format failed: text format parsing error in line 1: invalid metric name"),
 	})
 }
 
@@ -273,6 +439,30 @@ envoy_server_hot_restart_epoch{} 0
 # TYPE envoy_http_downstream_cx_active gauge
 envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_http"} 4
 envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_https"} 4
+`
+
+	WITHREQUESTS = `# TYPE envoy_http_downstream_cx_active gauge
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_http"} 4
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_https"} 4
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="admin"} 1
+# TYPE envoy_http_downstream_rq_active gauge
+envoy_http_downstream_rq_active{envoy_http_conn_manager_prefix="ingress_http"} 1
+envoy_http_downstream_rq_active{envoy_http_conn_manager_prefix="ingress_https"} 2
+envoy_http_downstream_rq_active{envoy_http_conn_manager_prefix="admin"} 1
+`
+
+	WITHGATEWAYLISTENERS = `# TYPE envoy_http_downstream_cx_active gauge
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_http"} 4
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_https"} 4
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_http_81"} 3
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="stats"} 2
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="admin"} 1
+`
+
+	DRAINED = `# TYPE envoy_http_downstream_cx_active gauge
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_http"} 0
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="ingress_https"} 0
+envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="admin"} 1
 `
 
 	MISSING_STATS = `envoy_cluster_circuit_breakers_default_cx_pool_open{envoy_cluster_name="projectcontour_envoy-admin_9001"} 0
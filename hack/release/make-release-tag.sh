@@ -36,7 +36,7 @@ run::sed() {
 
 # Update the image tags in the Contour, Envoy, certgen and provisioner manifests to the new version
 # and switch the imagePullPolicy to IfNotPresent.
-for example in examples/contour/03-envoy.yaml examples/deployment/03-envoy-deployment.yaml examples/contour/03-contour.yaml examples/contour/02-job-certgen.yaml examples/gateway-provisioner/03-gateway-provisioner.yaml ; do
+for example in examples/contour/03-envoy.yaml examples/deployment/03-envoy-deployment.yaml examples/contour/03-contour.yaml examples/contour/02-job-certgen.yaml examples/certgen/02-deployment-certgen-rotate.yaml examples/gateway-provisioner/03-gateway-provisioner.yaml ; do
     # The version might be main or OLDVERS depending on whether we are
     # tagging from the release branch or from main.
     run::sed \
@@ -81,6 +81,7 @@ if git status -s examples/contour 2>&1 | grep -E -q '^\s+[MADRCU]'; then
         examples/contour/03-contour.yaml \
         examples/contour/03-envoy.yaml \
         examples/contour/02-job-certgen.yaml \
+        examples/certgen/02-deployment-certgen-rotate.yaml \
         examples/deployment/03-envoy-deployment.yaml \
         examples/gateway-provisioner/03-gateway-provisioner.yaml \
         examples/render/contour.yaml \
@@ -255,3 +255,23 @@ func MaxRequests(o metav1.Object) uint32 {
 func MaxRetries(o metav1.Object) uint32 {
 	return parseUInt32(ContourAnnotation(o, "max-retries"))
 }
+
+// TopologyAwareHints returns whether topology aware hints are enabled
+// for the Service, which is the case if either of the following
+// annotations is set to "auto" (case-insensitive):
+// 1. service.kubernetes.io/topology-mode
+// 2. service.kubernetes.io/topology-aware-hints
+func TopologyAwareHints(o metav1.Object) bool {
+	a := o.GetAnnotations()
+
+	for _, key := range []string{
+		"service.kubernetes.io/topology-mode",
+		"service.kubernetes.io/topology-aware-hints",
+	} {
+		if strings.EqualFold(a[key], "auto") {
+			return true
+		}
+	}
+
+	return false
+}
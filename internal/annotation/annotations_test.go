@@ -336,6 +336,49 @@ func TestTLSCertNamespace(t *testing.T) {
 	}
 }
 
+func TestTopologyAwareHints(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        bool
+	}{
+		"absent": {
+			annotations: map[string]string{},
+			want:        false,
+		},
+		"topology-aware-hints auto": {
+			annotations: map[string]string{
+				"service.kubernetes.io/topology-aware-hints": "auto",
+			},
+			want: true,
+		},
+		"topology-aware-hints Auto": {
+			annotations: map[string]string{
+				"service.kubernetes.io/topology-aware-hints": "Auto",
+			},
+			want: true,
+		},
+		"topology-aware-hints disabled": {
+			annotations: map[string]string{
+				"service.kubernetes.io/topology-aware-hints": "disabled",
+			},
+			want: false,
+		},
+		"topology-mode Auto": {
+			annotations: map[string]string{
+				"service.kubernetes.io/topology-mode": "Auto",
+			},
+			want: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := TopologyAwareHints(&metav1.ObjectMeta{Annotations: tc.annotations})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestHttpAllowed(t *testing.T) {
 	tests := map[string]struct {
 		i     *networking_v1.Ingress
@@ -126,7 +126,7 @@ func WriteSecretsYAML(outputDir string, secrets []*corev1.Secret, force Overwrit
 
 // WriteSecretsKube writes all the keypairs out to Kubernetes Secrets in the
 // compact format which is compatible with Secrets generated by cert-manager.
-func WriteSecretsKube(client *kubernetes.Clientset, secrets []*corev1.Secret, force OverwritePolicy) error {
+func WriteSecretsKube(client kubernetes.Interface, secrets []*corev1.Secret, force OverwritePolicy) error {
 	for _, s := range secrets {
 		if _, err := client.CoreV1().Secrets(s.Namespace).Create(context.TODO(), s, metav1.CreateOptions{}); err != nil {
 			if !k8serrors.IsAlreadyExists(err) {
@@ -0,0 +1,307 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/pkg/certs"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// caSecretName is the name of the Secret holding the CA keypair that
+// certgen issues certificates from when rotating them, before the name
+// suffix is appended.
+const caSecretName = "contourca"
+
+// secretNames are the names of the Secrets written by certgen, before the
+// name suffix is appended.
+var secretNames = []string{"contourcert", "envoycert", "cacert", caSecretName}
+
+// RotationStep is a step in rotating the certificates written by certgen.
+// Rotating to a new CA takes several steps, each of which is taken on a
+// separate pass, so that the kubelet can update the mounted Secrets and
+// Contour and Envoy keep trusting each other throughout.
+type RotationStep int
+
+const (
+	// RotationNone means that the certificates don't need to be rotated.
+	RotationNone RotationStep = iota
+
+	// RotationBootstrap means that the certificates are missing, so a
+	// new CA and certificates are generated at once.
+	RotationBootstrap
+
+	// RotationNewCA means that the certificates expire soon, so a new
+	// CA is generated and added to the trust bundle next to the current one.
+	RotationNewCA
+
+	// RotationTrustCA means that the CA in the contourca Secret is not
+	// yet in the trust bundle, and is added to it.
+	RotationTrustCA
+
+	// RotationIssueCerts means that the CA in the contourca Secret is
+	// trusted, and the Contour and Envoy certificates are issued from it.
+	RotationIssueCerts
+
+	// RotationDropCA means that the certificates are issued from the
+	// CA in the contourca Secret, and the previous CAs are dropped from
+	// the trust bundle.
+	RotationDropCA
+)
+
+func (s RotationStep) String() string {
+	switch s {
+	case RotationNone:
+		return "none"
+	case RotationBootstrap:
+		return "bootstrap"
+	case RotationNewCA:
+		return "new CA"
+	case RotationTrustCA:
+		return "trust CA"
+	case RotationIssueCerts:
+		return "issue certificates"
+	case RotationDropCA:
+		return "drop previous CA"
+	default:
+		return fmt.Sprintf("RotationStep(%d)", int(s))
+	}
+}
+
+// ReadSecretsKube returns the Secrets written by certgen with the given
+// name suffix that currently exist in namespace, keyed by name.
+func ReadSecretsKube(client kubernetes.Interface, namespace, nameSuffix string) (map[string]*corev1.Secret, error) {
+	secrets := map[string]*corev1.Secret{}
+
+	for _, name := range secretNames {
+		s, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name+nameSuffix, metav1.GetOptions{})
+		switch {
+		case k8serrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		secrets[s.Name] = s
+	}
+
+	return secrets, nil
+}
+
+// NextRotationStep returns the next step to take to rotate the
+// certificates in secrets, renewing them if they or the CA that
+// issued them expire before deadline.
+func NextRotationStep(secrets map[string]*corev1.Secret, nameSuffix string, deadline time.Time) RotationStep {
+	var leaves []*x509.Certificate
+	for _, name := range []string{"contourcert", "envoycert"} {
+		s, ok := secrets[name+nameSuffix]
+		if !ok {
+			return RotationBootstrap
+		}
+
+		certs := parseCertificates(s.Data[corev1.TLSCertKey])
+		if len(certs) == 0 {
+			return RotationBootstrap
+		}
+		leaves = append(leaves, certs[0])
+	}
+
+	expiring := false
+	for _, c := range leaves {
+		if c.NotAfter.Before(deadline) {
+			expiring = true
+		}
+	}
+
+	ca := parseCA(secrets[caSecretName+nameSuffix])
+	if ca == nil {
+		// The certificates were not written by a rotating certgen,
+		// so there is no CA to issue new certificates from yet.
+		if expiring {
+			return RotationNewCA
+		}
+		return RotationNone
+	}
+
+	bundle := trustedCAs(secrets, nameSuffix, time.Time{})
+	if !containsCertificate(bundle, ca) {
+		return RotationTrustCA
+	}
+
+	for _, c := range leaves {
+		if c.CheckSignatureFrom(ca) != nil {
+			return RotationIssueCerts
+		}
+	}
+
+	if len(bundle) > 1 {
+		return RotationDropCA
+	}
+
+	if expiring || ca.NotAfter.Before(deadline) {
+		return RotationNewCA
+	}
+
+	return RotationNone
+}
+
+// CurrentCertificates returns the certificates in secrets, with the CA
+// bundle holding the unexpired CAs that are currently trusted, and the
+// CA keypair from the contourca Secret.
+func CurrentCertificates(secrets map[string]*corev1.Secret, nameSuffix string, now time.Time) *certs.Certificates {
+	current := &certs.Certificates{}
+
+	if s, ok := secrets["contourcert"+nameSuffix]; ok {
+		current.ContourCertificate = s.Data[corev1.TLSCertKey]
+		current.ContourPrivateKey = s.Data[corev1.TLSPrivateKeyKey]
+	}
+	if s, ok := secrets["envoycert"+nameSuffix]; ok {
+		current.EnvoyCertificate = s.Data[corev1.TLSCertKey]
+		current.EnvoyPrivateKey = s.Data[corev1.TLSPrivateKeyKey]
+	}
+	if s, ok := secrets[caSecretName+nameSuffix]; ok {
+		current.CAPrivateKey = s.Data[corev1.TLSPrivateKeyKey]
+	}
+
+	current.CACertificate = encodeCertificates(trustedCAs(secrets, nameSuffix, now))
+
+	return current
+}
+
+// CASecret returns the Secret holding the CA keypair in certdata, which
+// certgen issues certificates from while rotating them.
+func CASecret(namespace, nameSuffix string, certdata *certs.Certificates) *corev1.Secret {
+	return newSecret(
+		corev1.SecretTypeTLS,
+		caSecretName+nameSuffix,
+		namespace,
+		map[string][]byte{
+			corev1.TLSCertKey:       certdata.CACertificate,
+			corev1.TLSPrivateKeyKey: certdata.CAPrivateKey,
+		})
+}
+
+// CACertificate returns the CA certificate from the contourca Secret
+// in secrets, or nil if there isn't one.
+func CACertificate(secrets map[string]*corev1.Secret, nameSuffix string) []byte {
+	ca := parseCA(secrets[caSecretName+nameSuffix])
+	if ca == nil {
+		return nil
+	}
+	return encodeCertificates([]*x509.Certificate{ca})
+}
+
+// AppendCA returns bundle with caCert appended, unless bundle
+// already holds it.
+func AppendCA(bundle, caCert []byte) []byte {
+	certs := parseCertificates(bundle)
+	for _, c := range parseCertificates(caCert) {
+		if !containsCertificate(certs, c) {
+			certs = append(certs, c)
+		}
+	}
+	return encodeCertificates(certs)
+}
+
+// trustedCAs returns the CA certificates in the trust bundles of the
+// secrets, skipping any that expire before now.
+func trustedCAs(secrets map[string]*corev1.Secret, nameSuffix string, now time.Time) []*x509.Certificate {
+	var bundle []*x509.Certificate
+
+	// Iterate in a fixed order so that the bundle is stable.
+	for _, name := range secretNames {
+		s, ok := secrets[name+nameSuffix]
+		if !ok {
+			continue
+		}
+
+		for _, key := range []string{dag.CACertificateKey, CACertificateKey} {
+			for _, c := range parseCertificates(s.Data[key]) {
+				if containsCertificate(bundle, c) || c.NotAfter.Before(now) {
+					continue
+				}
+				bundle = append(bundle, c)
+			}
+		}
+	}
+
+	return bundle
+}
+
+// parseCA returns the CA certificate in the Secret s, or nil if
+// s is nil or doesn't hold a valid keypair.
+func parseCA(s *corev1.Secret) *x509.Certificate {
+	if s == nil {
+		return nil
+	}
+	if _, err := tls.X509KeyPair(s.Data[corev1.TLSCertKey], s.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		return nil
+	}
+
+	certs := parseCertificates(s.Data[corev1.TLSCertKey])
+	if len(certs) == 0 {
+		return nil
+	}
+	return certs[0]
+}
+
+func containsCertificate(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeCertificates(certs []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, c := range certs {
+		buf.Write(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: c.Raw,
+		}))
+	}
+	return buf.Bytes()
+}
+
+// parseCertificates returns the certificates in the PEM data,
+// skipping any blocks that can't be parsed.
+func parseCertificates(data []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if c, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, c)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package certwatcher keeps the xDS server's TLS configuration up to
+// date as its certificate, key and CA bundle files are rotated.
+package certwatcher
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/projectcontour/contour/internal/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// settleDelay is how long to wait after a file change before reloading,
+// so that a certificate and key written one after the other are loaded
+// together.
+const settleDelay = 100 * time.Millisecond
+
+// Watcher loads a server TLS configuration that requires client
+// certificates, and reloads it whenever the certificate, key or CA
+// bundle files change.
+type Watcher struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+
+	// Metrics, if not nil, records the result of each reload.
+	Metrics *metrics.Metrics
+
+	logrus.FieldLogger
+
+	mu     sync.RWMutex
+	config *tls.Config
+}
+
+// Reload reads the certificate, key and CA bundle files. If they can't
+// be loaded, the previously loaded configuration is kept.
+func (w *Watcher) Reload() error {
+	config, err := w.load()
+	if w.Metrics != nil {
+		w.Metrics.SetXDSTLSReload(time.Now(), err)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.config = config
+	return nil
+}
+
+func (w *Watcher) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(w.CertFile, w.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := os.ReadFile(w.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if ok := certPool.AppendCertsFromPEM(ca); !ok {
+		return nil, fmt.Errorf("unable to append certificate in %s to CA pool", w.CAFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    certPool,
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+// TLSConfig returns a server TLS configuration which uses the most
+// recently loaded certificate, key and CA bundle for each handshake.
+func (w *Watcher) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS13,
+		ClientAuth:         tls.RequireAndVerifyClientCert,
+		Rand:               rand.Reader,
+		GetConfigForClient: w.GetConfigForClient,
+	}
+}
+
+// GetConfigForClient returns the most recently loaded TLS configuration.
+func (w *Watcher) GetConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.config == nil {
+		return nil, fmt.Errorf("TLS certificate %s has not been loaded", w.CertFile)
+	}
+	return w.config, nil
+}
+
+// Start watches the directories containing the certificate, key and CA
+// bundle files, reloading them once the watches are added and on any
+// change, until ctx is done.
+// Directories rather than files are watched since Kubernetes updates
+// mounted Secrets by swapping a symlink.
+func (w *Watcher) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	dirs := map[string]bool{}
+	for _, f := range []string{w.CertFile, w.KeyFile, w.CAFile} {
+		dir := filepath.Dir(f)
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return err
+		}
+		dirs[dir] = true
+	}
+
+	// reload fires settleDelay after the most recent change. It also
+	// fires once the watches are in place, to pick up any change made
+	// after the files were first loaded but before they were watched.
+	reload := time.NewTimer(settleDelay)
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			reload.Reset(settleDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.WithError(err).Error("error watching TLS certificate files")
+		case <-reload.C:
+			if err := w.Reload(); err != nil {
+				w.WithError(err).Error("failed to reload TLS certificate, key and CA bundle")
+				continue
+			}
+			w.Info("reloaded TLS certificate, key and CA bundle")
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certwatcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tsaarni/certyaml"
+)
+
+func TestWatcher(t *testing.T) {
+	ca := certyaml.Certificate{
+		Subject: "cn=ca",
+	}
+	before := certyaml.Certificate{
+		Subject: "cn=before",
+		Issuer:  &ca,
+	}
+	after := certyaml.Certificate{
+		Subject: "cn=after",
+		Issuer:  &ca,
+	}
+
+	dir := t.TempDir()
+	registry := prometheus.NewRegistry()
+	w := &Watcher{
+		CertFile:    filepath.Join(dir, "tls.crt"),
+		KeyFile:     filepath.Join(dir, "tls.key"),
+		CAFile:      filepath.Join(dir, "ca.crt"),
+		Metrics:     metrics.NewMetrics(registry),
+		FieldLogger: fixture.NewTestLogger(t),
+	}
+
+	// Nothing is served until the files have been loaded.
+	_, err := w.GetConfigForClient(nil)
+	assert.Error(t, err)
+
+	// Missing files fail to load.
+	assert.Error(t, w.Reload())
+	assert.Equal(t, map[string]float64{"failure": 1}, reloads(t, registry))
+
+	require.NoError(t, ca.WritePEM(w.CAFile, filepath.Join(dir, "ca.key")))
+	require.NoError(t, before.WritePEM(w.CertFile, w.KeyFile))
+	require.NoError(t, w.Reload())
+	assert.Equal(t, "before", servedCert(t, w))
+	assert.Equal(t, map[string]float64{"failure": 1, "success": 1}, reloads(t, registry))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- w.Start(ctx)
+	}()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	// Rotated files are reloaded, even if they change before the
+	// watcher has started watching them.
+	require.NoError(t, after.WritePEM(w.CertFile, w.KeyFile))
+	assert.Eventually(t, func() bool {
+		return servedCert(t, w) == "after"
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Invalid files are counted as failures, and the previous
+	// configuration continues to be served.
+	require.NoError(t, os.WriteFile(w.CertFile, []byte("invalid"), 0600))
+	assert.Eventually(t, func() bool {
+		return reloads(t, registry)["failure"] > 1
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, "after", servedCert(t, w))
+}
+
+// servedCert returns the common name of the certificate served by w.
+func servedCert(t *testing.T, w *Watcher) string {
+	t.Helper()
+
+	config, err := w.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Len(t, config.Certificates, 1)
+
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+// reloads returns the number of reloads recorded in registry, by result.
+func reloads(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	t.Helper()
+
+	gathering, err := registry.Gather()
+	require.NoError(t, err)
+
+	got := map[string]float64{}
+	for _, mf := range gathering {
+		if mf.GetName() != metrics.XDSTLSReloadTotal {
+			continue
+		}
+		for _, m := range mf.Metric {
+			got[m.Label[0].GetValue()] = m.Counter.GetValue()
+		}
+	}
+	return got
+}
@@ -78,6 +78,10 @@ func Defaults() contour_api_v1alpha1.ContourConfigurationSpec {
 				DisableAllowChunkedLength: pointer.Bool(false),
 				DisableMergeSlashes:       pointer.Bool(false),
 				ConnectionBalancer:        "",
+				HTTP3: &contour_api_v1alpha1.EnvoyHTTP3{
+					Enabled:        pointer.Bool(false),
+					AdvertisedPort: pointer.Int(443),
+				},
 				TLS: &contour_api_v1alpha1.EnvoyTLS{
 					MinimumProtocolVersion: "1.2",
 					CipherSuites:           contour_api_v1alpha1.DefaultTLSCiphers,
@@ -142,6 +146,7 @@ func Defaults() contour_api_v1alpha1.ContourConfigurationSpec {
 		},
 		EnableExternalNameService: pointer.Bool(false),
 		RateLimitService:          nil,
+		Tracing:                   nil,
 		Policy: &contour_api_v1alpha1.PolicyConfig{
 			RequestHeadersPolicy:  &contour_api_v1alpha1.HeadersPolicy{},
 			ResponseHeadersPolicy: &contour_api_v1alpha1.HeadersPolicy{},
@@ -26,6 +26,7 @@ import (
 )
 
 func TestOverlayOnDefaults(t *testing.T) {
+	maxPathTagLength := uint32(64)
 	allFieldsSpecified := contour_api_v1alpha1.ContourConfigurationSpec{
 		XDSServer: &contour_api_v1alpha1.XDSServerConfig{
 			Type:    contour_api_v1alpha1.EnvoyServerType,
@@ -56,6 +57,10 @@ func TestOverlayOnDefaults(t *testing.T) {
 				DisableAllowChunkedLength: pointer.Bool(true),
 				DisableMergeSlashes:       pointer.Bool(true),
 				ConnectionBalancer:        "yesplease",
+				HTTP3: &contour_api_v1alpha1.EnvoyHTTP3{
+					Enabled:        pointer.Bool(true),
+					AdvertisedPort: pointer.Int(8443),
+				},
 				TLS: &contour_api_v1alpha1.EnvoyTLS{
 					MinimumProtocolVersion: "1.7",
 					CipherSuites: []string{
@@ -147,6 +152,17 @@ func TestOverlayOnDefaults(t *testing.T) {
 			FailOpen:                pointer.Bool(true),
 			EnableXRateLimitHeaders: pointer.Bool(true),
 		},
+		Tracing: &contour_api_v1alpha1.TracingConfig{
+			ExtensionService: contour_api_v1alpha1.NamespacedName{
+				Namespace: "tracingservicenamespace",
+				Name:      "tracingservicename",
+			},
+			OverallSampling:  pointer.String("50"),
+			MaxPathTagLength: &maxPathTagLength,
+			CustomTags: []*contour_api_v1alpha1.CustomTag{
+				{TagName: "tagname", Literal: "tagvalue"},
+			},
+		},
 		Policy: &contour_api_v1alpha1.PolicyConfig{
 			RequestHeadersPolicy: &contour_api_v1alpha1.HeadersPolicy{
 				Set:    map[string]string{"set": "val"},
@@ -58,6 +58,7 @@ func (d *DAG) EnsureService(meta types.NamespacedName, port intstr.IntOrString,
 		MaxRequests:        annotation.MaxRequests(svc),
 		MaxRetries:         annotation.MaxRetries(svc),
 		ExternalName:       externalName(svc),
+		TopologyAwareHints: annotation.TopologyAwareHints(svc),
 	}, nil
 }
 
@@ -152,6 +153,44 @@ func (d *DAG) EnsureVirtualHost(hostname string) *VirtualHost {
 	return vhost
 }
 
+// EnsureSecureVirtualHost adds a secure virtual host with the provided
+// name to the listener if it does not already exist, and returns it.
+func (l *Listener) EnsureSecureVirtualHost(hostname string) *SecureVirtualHost {
+	if svh := l.svhostsByName[hostname]; svh != nil {
+		return svh
+	}
+
+	if l.svhostsByName == nil {
+		l.svhostsByName = map[string]*SecureVirtualHost{}
+	}
+
+	svh := &SecureVirtualHost{
+		VirtualHost: VirtualHost{
+			Name: hostname,
+		},
+	}
+	l.svhostsByName[hostname] = svh
+	return svh
+}
+
+// EnsureVirtualHost adds a virtual host with the provided name to the
+// listener if it does not already exist, and returns it.
+func (l *Listener) EnsureVirtualHost(hostname string) *VirtualHost {
+	if vhost := l.vhostsByName[hostname]; vhost != nil {
+		return vhost
+	}
+
+	if l.vhostsByName == nil {
+		l.vhostsByName = map[string]*VirtualHost{}
+	}
+
+	vhost := &VirtualHost{
+		Name: hostname,
+	}
+	l.vhostsByName[hostname] = vhost
+	return vhost
+}
+
 func (d *DAG) GetClusters() []*Cluster {
 	var res []*Cluster
 
@@ -179,6 +218,10 @@ func (d *DAG) GetClusters() []*Cluster {
 				res = append(res, vhost.TCPProxy.Clusters...)
 			}
 		}
+
+		if listener.TCPProxy != nil {
+			res = append(res, listener.TCPProxy.Clusters...)
+		}
 	}
 
 	return res
@@ -259,39 +302,3 @@ func (d *DAG) GetExtensionCluster(name string) *ExtensionCluster {
 
 	return nil
 }
-
-func (d *DAG) GetVirtualHostRoutes() map[*VirtualHost][]*Route {
-	res := map[*VirtualHost][]*Route{}
-
-	for _, l := range d.Listeners {
-		for _, vhost := range l.VirtualHosts {
-			var routes []*Route
-			for _, r := range vhost.Routes {
-				routes = append(routes, r)
-			}
-			if len(routes) > 0 {
-				res[vhost] = routes
-			}
-		}
-	}
-
-	return res
-}
-
-func (d *DAG) GetSecureVirtualHostRoutes() map[*SecureVirtualHost][]*Route {
-	res := map[*SecureVirtualHost][]*Route{}
-
-	for _, l := range d.Listeners {
-		for _, vhost := range l.SecureVirtualHosts {
-			var routes []*Route
-			for _, r := range vhost.Routes {
-				routes = append(routes, r)
-			}
-			if len(routes) > 0 {
-				res[vhost] = routes
-			}
-		}
-	}
-
-	return res
-}
@@ -275,7 +275,7 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 		Spec: gatewayapi_v1beta1.GatewaySpec{
 			GatewayClassName: gatewayapi_v1beta1.ObjectName(validClass.Name),
 			Listeners: []gatewayapi_v1beta1.Listener{{
-				Port:     80,
+				Port:     443,
 				Protocol: gatewayapi_v1beta1.TLSProtocolType,
 				TLS: &gatewayapi_v1beta1.GatewayTLSConfig{
 					Mode: gatewayapi.TLSModeTypePtr(gatewayapi_v1beta1.TLSModePassthrough),
@@ -297,7 +297,7 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 		Spec: gatewayapi_v1beta1.GatewaySpec{
 			GatewayClassName: gatewayapi_v1beta1.ObjectName(validClass.Name),
 			Listeners: []gatewayapi_v1beta1.Listener{{
-				Port:     80,
+				Port:     443,
 				Protocol: gatewayapi_v1beta1.TLSProtocolType,
 				TLS: &gatewayapi_v1beta1.GatewayTLSConfig{
 					Mode: gatewayapi.TLSModeTypePtr(gatewayapi_v1beta1.TLSModePassthrough),
@@ -319,7 +319,7 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 		Spec: gatewayapi_v1beta1.GatewaySpec{
 			GatewayClassName: gatewayapi_v1beta1.ObjectName(validClass.Name),
 			Listeners: []gatewayapi_v1beta1.Listener{{
-				Port:     80,
+				Port:     443,
 				Protocol: gatewayapi_v1beta1.TLSProtocolType,
 				TLS: &gatewayapi_v1beta1.GatewayTLSConfig{
 					Mode: gatewayapi.TLSModeTypePtr(gatewayapi_v1beta1.TLSModePassthrough),
@@ -499,6 +499,60 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 				},
 			),
 		},
+		"insert basic single route, HTTP listeners on multiple ports": {
+			gatewayclass: validClass,
+			gateway: &gatewayapi_v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "contour",
+					Namespace: "projectcontour",
+				},
+				Spec: gatewayapi_v1beta1.GatewaySpec{
+					GatewayClassName: gatewayapi_v1beta1.ObjectName(validClass.Name),
+					Listeners: []gatewayapi_v1beta1.Listener{
+						{
+							Name:     "http",
+							Port:     80,
+							Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+							AllowedRoutes: &gatewayapi_v1beta1.AllowedRoutes{
+								Namespaces: &gatewayapi_v1beta1.RouteNamespaces{
+									From: gatewayapi.FromNamespacesPtr(gatewayapi_v1beta1.NamespacesFromAll),
+								},
+							},
+						},
+						{
+							Name:     "http-alt",
+							Port:     8081,
+							Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+							AllowedRoutes: &gatewayapi_v1beta1.AllowedRoutes{
+								Namespaces: &gatewayapi_v1beta1.RouteNamespaces{
+									From: gatewayapi.FromNamespacesPtr(gatewayapi_v1beta1.NamespacesFromAll),
+								},
+							},
+						},
+					},
+				},
+			},
+			objs: []interface{}{
+				kuardService,
+				basicHTTPRoute,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("test.projectcontour.io", prefixrouteHTTPRoute("/", service(kuardService))),
+					),
+				},
+				&Listener{
+					Name: "ingress_http_8081",
+					Port: 8081,
+					VirtualHosts: virtualhosts(
+						virtualhost("test.projectcontour.io", prefixrouteHTTPRoute("/", service(kuardService))),
+					),
+				},
+			),
+		},
 		"gateway with addresses is unsupported": {
 			gatewayclass: validClass,
 			gateway:      gatewayHTTPWithAddresses,
@@ -877,6 +931,94 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 		},
 		// END TLSRoute<->Gateway selection test cases
 
+		"insert basic single route with TCP listener": {
+			gatewayclass: validClass,
+			gateway: &gatewayapi_v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "contour",
+					Namespace: "projectcontour",
+				},
+				Spec: gatewayapi_v1beta1.GatewaySpec{
+					GatewayClassName: gatewayapi_v1beta1.ObjectName(validClass.Name),
+					Listeners: []gatewayapi_v1beta1.Listener{{
+						Name:     "tcp",
+						Port:     9000,
+						Protocol: gatewayapi_v1beta1.TCPProtocolType,
+						AllowedRoutes: &gatewayapi_v1beta1.AllowedRoutes{
+							Namespaces: &gatewayapi_v1beta1.RouteNamespaces{
+								From: gatewayapi.FromNamespacesPtr(gatewayapi_v1beta1.NamespacesFromAll),
+							},
+						},
+					}},
+				},
+			},
+			objs: []interface{}{
+				kuardService,
+				&gatewayapi_v1alpha2.TCPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "basic",
+						Namespace: "projectcontour",
+					},
+					Spec: gatewayapi_v1alpha2.TCPRouteSpec{
+						CommonRouteSpec: gatewayapi_v1alpha2.CommonRouteSpec{
+							ParentRefs: []gatewayapi_v1alpha2.ParentReference{gatewayapi.GatewayParentRefV1Alpha2("projectcontour", "contour")},
+						},
+						Rules: []gatewayapi_v1alpha2.TCPRouteRule{{
+							BackendRefs: gatewayapi.TLSRouteBackendRef("kuard", 8080, nil),
+						}},
+					},
+				},
+			},
+			want: listeners(
+				&Listener{
+					Name: "ingress_tcp_9000",
+					Port: 9000,
+					TCPProxy: &TCPProxy{
+						Clusters: clustersWeight(service(kuardService)),
+					},
+				},
+			),
+		},
+		"TCPRoute with missing backend does not produce a TCP listener": {
+			gatewayclass: validClass,
+			gateway: &gatewayapi_v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "contour",
+					Namespace: "projectcontour",
+				},
+				Spec: gatewayapi_v1beta1.GatewaySpec{
+					GatewayClassName: gatewayapi_v1beta1.ObjectName(validClass.Name),
+					Listeners: []gatewayapi_v1beta1.Listener{{
+						Name:     "tcp",
+						Port:     9000,
+						Protocol: gatewayapi_v1beta1.TCPProtocolType,
+						AllowedRoutes: &gatewayapi_v1beta1.AllowedRoutes{
+							Namespaces: &gatewayapi_v1beta1.RouteNamespaces{
+								From: gatewayapi.FromNamespacesPtr(gatewayapi_v1beta1.NamespacesFromAll),
+							},
+						},
+					}},
+				},
+			},
+			objs: []interface{}{
+				&gatewayapi_v1alpha2.TCPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "basic",
+						Namespace: "projectcontour",
+					},
+					Spec: gatewayapi_v1alpha2.TCPRouteSpec{
+						CommonRouteSpec: gatewayapi_v1alpha2.CommonRouteSpec{
+							ParentRefs: []gatewayapi_v1alpha2.ParentReference{gatewayapi.GatewayParentRefV1Alpha2("projectcontour", "contour")},
+						},
+						Rules: []gatewayapi_v1alpha2.TCPRouteRule{{
+							BackendRefs: gatewayapi.TLSRouteBackendRef("kuard", 8080, nil),
+						}},
+					},
+				},
+			},
+			want: listeners(),
+		},
+
 		"TLS Listener with TLS.Mode=Passthrough is invalid if certificateRef is specified": {
 			gatewayclass: validClass,
 			gateway: &gatewayapi_v1beta1.Gateway{
@@ -886,7 +1028,7 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 				},
 				Spec: gatewayapi_v1beta1.GatewaySpec{
 					Listeners: []gatewayapi_v1beta1.Listener{{
-						Port:     80,
+						Port:     443,
 						Protocol: gatewayapi_v1beta1.TLSProtocolType,
 						TLS: &gatewayapi_v1beta1.GatewayTLSConfig{
 							Mode: gatewayapi.TLSModeTypePtr(gatewayapi_v1beta1.TLSModePassthrough),
@@ -918,7 +1060,7 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 				Spec: gatewayapi_v1beta1.GatewaySpec{
 					GatewayClassName: gatewayapi_v1beta1.ObjectName(validClass.Name),
 					Listeners: []gatewayapi_v1beta1.Listener{{
-						Port:     80,
+						Port:     443,
 						Protocol: gatewayapi_v1beta1.TLSProtocolType,
 						TLS: &gatewayapi_v1beta1.GatewayTLSConfig{
 							Mode: gatewayapi.TLSModeTypePtr(gatewayapi_v1beta1.TLSModeTerminate),
@@ -950,7 +1092,7 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 				},
 				Spec: gatewayapi_v1beta1.GatewaySpec{
 					Listeners: []gatewayapi_v1beta1.Listener{{
-						Port:     80,
+						Port:     443,
 						Protocol: gatewayapi_v1beta1.TLSProtocolType,
 						AllowedRoutes: &gatewayapi_v1beta1.AllowedRoutes{
 							Namespaces: &gatewayapi_v1beta1.RouteNamespaces{
@@ -1862,7 +2004,7 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 				},
 				Spec: gatewayapi_v1beta1.GatewaySpec{
 					Listeners: []gatewayapi_v1beta1.Listener{{
-						Port:     443,
+						Port:     80,
 						Protocol: gatewayapi_v1beta1.HTTPProtocolType,
 						TLS: &gatewayapi_v1beta1.GatewayTLSConfig{
 							CertificateRefs: []gatewayapi_v1beta1.SecretObjectReference{
@@ -3090,6 +3232,104 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 				},
 			),
 		},
+		"HTTPRoute rule with URL rewrite filter, prefix and hostname": {
+			gatewayclass: validClass,
+			gateway:      gatewayHTTPAllNamespaces,
+			objs: []interface{}{
+				kuardService,
+				&gatewayapi_v1beta1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "basic",
+						Namespace: "projectcontour",
+					},
+					Spec: gatewayapi_v1beta1.HTTPRouteSpec{
+						CommonRouteSpec: gatewayapi_v1beta1.CommonRouteSpec{
+							ParentRefs: []gatewayapi_v1beta1.ParentReference{gatewayapi.GatewayParentRef("projectcontour", "contour")},
+						},
+						Hostnames: []gatewayapi_v1beta1.Hostname{
+							"test.projectcontour.io",
+						},
+						Rules: []gatewayapi_v1beta1.HTTPRouteRule{{
+							Matches:     gatewayapi.HTTPRouteMatch(gatewayapi_v1beta1.PathMatchPathPrefix, "/foo"),
+							BackendRefs: gatewayapi.HTTPBackendRef("kuard", 8080, 1),
+							Filters: []gatewayapi_v1beta1.HTTPRouteFilter{{
+								Type: gatewayapi_v1beta1.HTTPRouteFilterURLRewrite,
+								URLRewrite: &gatewayapi_v1beta1.HTTPURLRewriteFilter{
+									Hostname: gatewayapi.PreciseHostname("rewritten.projectcontour.io"),
+									Path: &gatewayapi_v1beta1.HTTPPathModifier{
+										Type:               gatewayapi_v1beta1.PrefixMatchHTTPPathModifier,
+										ReplacePrefixMatch: pointer.String("/bar"),
+									},
+								},
+							}},
+						}},
+					},
+				},
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 80,
+					VirtualHosts: virtualhosts(virtualhost("test.projectcontour.io",
+						&Route{
+							PathMatchCondition: prefixSegment("/foo"),
+							Clusters:           clustersWeight(service(kuardService)),
+							RequestHeadersPolicy: &HeadersPolicy{
+								HostRewrite: "rewritten.projectcontour.io",
+							},
+							PrefixRewrite: "/bar",
+						},
+					)),
+				},
+			),
+		},
+		"HTTPRoute rule with URL rewrite filter, full path": {
+			gatewayclass: validClass,
+			gateway:      gatewayHTTPAllNamespaces,
+			objs: []interface{}{
+				kuardService,
+				&gatewayapi_v1beta1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "basic",
+						Namespace: "projectcontour",
+					},
+					Spec: gatewayapi_v1beta1.HTTPRouteSpec{
+						CommonRouteSpec: gatewayapi_v1beta1.CommonRouteSpec{
+							ParentRefs: []gatewayapi_v1beta1.ParentReference{gatewayapi.GatewayParentRef("projectcontour", "contour")},
+						},
+						Hostnames: []gatewayapi_v1beta1.Hostname{
+							"test.projectcontour.io",
+						},
+						Rules: []gatewayapi_v1beta1.HTTPRouteRule{{
+							Matches:     gatewayapi.HTTPRouteMatch(gatewayapi_v1beta1.PathMatchExact, "/foo"),
+							BackendRefs: gatewayapi.HTTPBackendRef("kuard", 8080, 1),
+							Filters: []gatewayapi_v1beta1.HTTPRouteFilter{{
+								Type: gatewayapi_v1beta1.HTTPRouteFilterURLRewrite,
+								URLRewrite: &gatewayapi_v1beta1.HTTPURLRewriteFilter{
+									Path: &gatewayapi_v1beta1.HTTPPathModifier{
+										Type:            gatewayapi_v1beta1.FullPathHTTPPathModifier,
+										ReplaceFullPath: pointer.String("/bar"),
+									},
+								},
+							}},
+						}},
+					},
+				},
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 80,
+					VirtualHosts: virtualhosts(virtualhost("test.projectcontour.io",
+						&Route{
+							PathMatchCondition: exact("/foo"),
+							Clusters:           clustersWeight(service(kuardService)),
+							FullPathRewrite:    "/bar",
+						},
+					)),
+				},
+			),
+		},
 		"HTTPRoute rule with request redirect filter": {
 			gatewayclass: validClass,
 			gateway:      gatewayHTTPAllNamespaces,
@@ -6152,6 +6392,22 @@ func TestDAGInsert(t *testing.T) {
 			}},
 		},
 	}
+	proxy17SubjectAltNames := proxy17.DeepCopy()
+	proxy17SubjectAltNames.Spec.Routes[0].Services[0].UpstreamValidation = &contour_api_v1.UpstreamValidation{
+		CACertificate: cert1.Name,
+		SubjectAltNames: []contour_api_v1.SubjectAltNameMatcher{
+			{Suffix: ".example.com"},
+			{Type: contour_api_v1.SubjectAltNameTypeURI, Prefix: "spiffe://cluster.local/"},
+			{Type: contour_api_v1.SubjectAltNameTypeIP, Exact: "10.0.0.1"},
+		},
+	}
+	proxy17InvalidSubjectAltNames := proxy17.DeepCopy()
+	proxy17InvalidSubjectAltNames.Spec.Routes[0].Services[0].UpstreamValidation = &contour_api_v1.UpstreamValidation{
+		CACertificate: cert1.Name,
+		SubjectAltNames: []contour_api_v1.SubjectAltNameMatcher{
+			{Prefix: "www.", Suffix: ".example.com"},
+		},
+	}
 	protocolh2 := "h2"
 	proxy17h2 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -9453,6 +9709,49 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
+		"insert httpproxy expecting upstream verification by subject alt names": {
+			objs: []interface{}{
+				cert1, proxy17SubjectAltNames, s1a,
+			},
+			want: listeners(
+				&Listener{
+					Name: HTTP_LISTENER_NAME,
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com",
+							routeCluster("/",
+								&Cluster{
+									Upstream: &Service{
+										Protocol: "tls",
+										Weighted: WeightedService{
+											Weight:           1,
+											ServiceName:      s1a.Name,
+											ServiceNamespace: s1a.Namespace,
+											ServicePort:      s1a.Spec.Ports[0],
+										},
+									},
+									Protocol: "tls",
+									UpstreamValidation: &PeerValidationContext{
+										CACertificate: secret(cert1),
+										SubjectAltNames: []SubjectAltNameMatcher{
+											{MatchType: StringMatchTypeSuffix, Value: ".example.com"},
+											{Type: contour_api_v1.SubjectAltNameTypeURI, MatchType: StringMatchTypePrefix, Value: "spiffe://cluster.local/"},
+											{Type: contour_api_v1.SubjectAltNameTypeIP, MatchType: StringMatchTypeExact, Value: "10.0.0.1"},
+										},
+									},
+								},
+							),
+						),
+					),
+				},
+			),
+		},
+		"insert httpproxy expecting upstream verification, invalid subject alt name": {
+			objs: []interface{}{
+				cert1, proxy17InvalidSubjectAltNames, s1a,
+			},
+			want: listeners(),
+		},
 		"insert httpproxy with h2 expecting upstream verification": {
 			objs: []interface{}{
 				cert1, proxy17h2, s1,
@@ -67,6 +67,7 @@ type KubernetesCache struct {
 	gateway                   *gatewayapi_v1beta1.Gateway
 	httproutes                map[types.NamespacedName]*gatewayapi_v1beta1.HTTPRoute
 	tlsroutes                 map[types.NamespacedName]*gatewayapi_v1alpha2.TLSRoute
+	tcproutes                 map[types.NamespacedName]*gatewayapi_v1alpha2.TCPRoute
 	referencepolicies         map[types.NamespacedName]*gatewayapi_v1alpha2.ReferencePolicy
 	referencegrants           map[types.NamespacedName]*gatewayapi_v1alpha2.ReferenceGrant
 	extensions                map[types.NamespacedName]*contour_api_v1alpha1.ExtensionService
@@ -90,6 +91,7 @@ func (kc *KubernetesCache) init() {
 	kc.referencepolicies = make(map[types.NamespacedName]*gatewayapi_v1alpha2.ReferencePolicy)
 	kc.referencegrants = make(map[types.NamespacedName]*gatewayapi_v1alpha2.ReferenceGrant)
 	kc.tlsroutes = make(map[types.NamespacedName]*gatewayapi_v1alpha2.TLSRoute)
+	kc.tcproutes = make(map[types.NamespacedName]*gatewayapi_v1alpha2.TCPRoute)
 	kc.extensions = make(map[types.NamespacedName]*contour_api_v1alpha1.ExtensionService)
 }
 
@@ -202,6 +204,9 @@ func (kc *KubernetesCache) Insert(obj interface{}) bool {
 		case *gatewayapi_v1alpha2.TLSRoute:
 			kc.tlsroutes[k8s.NamespacedNameOf(obj)] = obj
 			return true
+		case *gatewayapi_v1alpha2.TCPRoute:
+			kc.tcproutes[k8s.NamespacedNameOf(obj)] = obj
+			return true
 		case *gatewayapi_v1alpha2.ReferencePolicy:
 			kc.referencepolicies[k8s.NamespacedNameOf(obj)] = obj
 			return true
@@ -329,6 +334,11 @@ func (kc *KubernetesCache) remove(obj interface{}) bool {
 		_, ok := kc.tlsroutes[m]
 		delete(kc.tlsroutes, m)
 		return ok
+	case *gatewayapi_v1alpha2.TCPRoute:
+		m := k8s.NamespacedNameOf(obj)
+		_, ok := kc.tcproutes[m]
+		delete(kc.tcproutes, m)
+		return ok
 	case *gatewayapi_v1alpha2.ReferencePolicy:
 		m := k8s.NamespacedNameOf(obj)
 		_, ok := kc.referencepolicies[m]
@@ -495,6 +505,20 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 		}
 	}
 
+	// Client certificates referred by HTTPProxy services.
+	for _, proxy := range kc.httpproxies {
+		for _, route := range proxy.Spec.Routes {
+			for _, service := range route.Services {
+				if service.ClientCertificate == nil || service.ClientCertificate.SecretName == "" {
+					continue
+				}
+				if k8s.NamespacedNameFrom(service.ClientCertificate.SecretName, k8s.DefaultNamespace(proxy.Namespace)) == k8s.NamespacedNameOf(secret) {
+					return true
+				}
+			}
+		}
+	}
+
 	// Secrets referred by the configuration file shall also trigger rebuild.
 	for _, s := range kc.ConfiguredSecretRefs {
 		if s.Namespace == secret.Namespace && s.Name == secret.Name {
@@ -557,14 +581,21 @@ func (kc *KubernetesCache) LookupUpstreamValidation(uv *contour_api_v1.UpstreamV
 		return nil, fmt.Errorf("invalid CA Secret %q: %s", caCertificate, err)
 	}
 
-	if uv.SubjectName == "" {
+	if uv.SubjectName == "" && len(uv.SubjectAltNames) == 0 {
 		// UpstreamValidation is requested, but SAN is not provided
 		return nil, errors.New("missing subject alternative name")
 	}
 
+	subjectAltNames, err := subjectAltNameMatchers(uv.SubjectAltNames)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PeerValidationContext{
-		CACertificate: cacert,
-		SubjectName:   uv.SubjectName,
+		CACertificate:   cacert,
+		SubjectName:     uv.SubjectName,
+		SubjectNameType: uv.SubjectNameType,
+		SubjectAltNames: subjectAltNames,
 	}, nil
 }
 
@@ -874,6 +874,15 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			},
 			want: true,
 		},
+		"insert gateway-api TCPRoute": {
+			obj: &gatewayapi_v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tcproute",
+					Namespace: "default",
+				},
+			},
+			want: true,
+		},
 		"insert gateway-api ReferenceGrant": {
 			obj: &gatewayapi_v1alpha2.ReferenceGrant{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1234,6 +1243,21 @@ func TestKubernetesCacheRemove(t *testing.T) {
 			},
 			want: true,
 		},
+		"remove gateway-api TCPRoute": {
+			cache: cache(&gatewayapi_v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tcproute",
+					Namespace: "default",
+				},
+			}),
+			obj: &gatewayapi_v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tcproute",
+					Namespace: "default",
+				},
+			},
+			want: true,
+		},
 		"remove gateway-api ReferenceGrant": {
 			cache: cache(&gatewayapi_v1alpha2.ReferenceGrant{
 				ObjectMeta: metav1.ObjectMeta{
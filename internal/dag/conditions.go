@@ -22,17 +22,42 @@ import (
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 )
 
-// mergePathMatchConditions merges the given slice of prefix MatchConditions into a single
-// prefix Condition.
-// pathMatchConditionsValid guarantees that if a prefix is present, it will start with a
-// / character, so we can simply concatenate.
+// mergePathMatchConditions merges the given slice of path MatchConditions into a
+// single path Condition.
+// pathMatchConditionsValid guarantees that if a prefix or exact path is present, it
+// will start with a / character, so we can simply concatenate. It also guarantees
+// that an exact or regex condition is the last path condition, so the prefixes that
+// precede it are prepended to it.
 func mergePathMatchConditions(conds []contour_api_v1.MatchCondition) MatchCondition {
+	re := regexp.MustCompile(`//+`)
+
 	prefix := ""
 	for _, cond := range conds {
+		switch {
+		case cond.Exact != "":
+			return &ExactMatchCondition{
+				Path: re.ReplaceAllString(prefix+cond.Exact, `/`),
+			}
+		case cond.Regex != "":
+			if prefix == "" {
+				return &RegexMatchCondition{
+					Regex: cond.Regex,
+				}
+			}
+
+			// The regex matches the remainder of the path after
+			// the prefixes, so group it in case it's an alternation.
+			prefix = re.ReplaceAllString(prefix, `/`)
+			if strings.HasPrefix(cond.Regex, "/") {
+				prefix = strings.TrimSuffix(prefix, "/")
+			}
+			return &RegexMatchCondition{
+				Regex: regexp.QuoteMeta(prefix) + "(?:" + cond.Regex + ")",
+			}
+		}
 		prefix += cond.Prefix
 	}
 
-	re := regexp.MustCompile(`//+`)
 	prefix = re.ReplaceAllString(prefix, `/`)
 
 	// After the merge operation is done, if the string is still empty, then
@@ -50,20 +75,62 @@ func mergePathMatchConditions(conds []contour_api_v1.MatchCondition) MatchCondit
 }
 
 // pathMatchConditionsValid validates a slice of MatchConditions can be correctly merged.
-// It encodes the business rules about what is allowed for prefix MatchConditions.
+// It encodes the business rules about what is allowed for path MatchConditions.
 func pathMatchConditionsValid(conds []contour_api_v1.MatchCondition) error {
 	prefixCount := 0
+	pathCount := 0
 
 	for _, cond := range conds {
+		set := 0
 		if cond.Prefix != "" {
+			set++
 			prefixCount++
 			if cond.Prefix[0] != '/' {
 				return fmt.Errorf("prefix conditions must start with /, %s was supplied", cond.Prefix)
 			}
 		}
+		if cond.Exact != "" {
+			set++
+			if cond.Exact[0] != '/' {
+				return fmt.Errorf("exact conditions must start with /, %s was supplied", cond.Exact)
+			}
+		}
+		if cond.Regex != "" {
+			set++
+			if err := ValidateRegex(cond.Regex); err != nil {
+				return fmt.Errorf("invalid regex condition %q: %s", cond.Regex, err)
+			}
+		}
+		if set > 1 {
+			return errors.New("a condition can only specify one of prefix, exact or regex")
+		}
+		pathCount += set
+
 		if prefixCount > 1 {
 			return errors.New("more than one prefix is not allowed in a condition block")
 		}
+		if pathCount > 1 {
+			return errors.New("more than one prefix, exact or regex is not allowed in a condition block")
+		}
+	}
+
+	return nil
+}
+
+// pathMatchConditionsInheritable validates that the path MatchConditions in conds
+// can be merged with the conditions inherited from the includes above them. An
+// exact or regex condition ends the path, so no path conditions may follow it.
+func pathMatchConditionsInheritable(inherited, conds []contour_api_v1.MatchCondition) error {
+	for _, parent := range inherited {
+		if parent.Exact == "" && parent.Regex == "" {
+			continue
+		}
+
+		for _, cond := range conds {
+			if cond.Prefix != "" || cond.Exact != "" || cond.Regex != "" {
+				return errors.New("path conditions are not allowed below an include with an exact or regex condition")
+			}
+		}
 	}
 
 	return nil
@@ -98,29 +165,53 @@ func headerMatchConditions(conditions []contour_api_v1.HeaderMatchCondition) []H
 			})
 		case cond.Contains != "":
 			hc = append(hc, HeaderMatchCondition{
-				Name:      cond.Name,
-				Value:     cond.Contains,
-				MatchType: HeaderMatchTypeContains,
+				Name:       cond.Name,
+				Value:      cond.Contains,
+				MatchType:  HeaderMatchTypeContains,
+				IgnoreCase: cond.IgnoreCase,
 			})
 		case cond.NotContains != "":
 			hc = append(hc, HeaderMatchCondition{
-				Name:      cond.Name,
-				Value:     cond.NotContains,
-				MatchType: HeaderMatchTypeContains,
-				Invert:    true,
+				Name:       cond.Name,
+				Value:      cond.NotContains,
+				MatchType:  HeaderMatchTypeContains,
+				Invert:     true,
+				IgnoreCase: cond.IgnoreCase,
 			})
 		case cond.Exact != "":
 			hc = append(hc, HeaderMatchCondition{
-				Name:      cond.Name,
-				Value:     cond.Exact,
-				MatchType: HeaderMatchTypeExact,
+				Name:       cond.Name,
+				Value:      cond.Exact,
+				MatchType:  HeaderMatchTypeExact,
+				IgnoreCase: cond.IgnoreCase,
 			})
 		case cond.NotExact != "":
+			hc = append(hc, HeaderMatchCondition{
+				Name:       cond.Name,
+				Value:      cond.NotExact,
+				MatchType:  HeaderMatchTypeExact,
+				Invert:     true,
+				IgnoreCase: cond.IgnoreCase,
+			})
+		case cond.Prefix != "":
+			hc = append(hc, HeaderMatchCondition{
+				Name:       cond.Name,
+				Value:      cond.Prefix,
+				MatchType:  HeaderMatchTypePrefix,
+				IgnoreCase: cond.IgnoreCase,
+			})
+		case cond.Suffix != "":
+			hc = append(hc, HeaderMatchCondition{
+				Name:       cond.Name,
+				Value:      cond.Suffix,
+				MatchType:  HeaderMatchTypeSuffix,
+				IgnoreCase: cond.IgnoreCase,
+			})
+		case cond.Regex != "":
 			hc = append(hc, HeaderMatchCondition{
 				Name:      cond.Name,
-				Value:     cond.NotExact,
-				MatchType: HeaderMatchTypeExact,
-				Invert:    true,
+				Value:     cond.Regex,
+				MatchType: HeaderMatchTypeRegex,
 			})
 		}
 	}
@@ -134,6 +225,8 @@ func headerMatchConditions(conditions []contour_api_v1.HeaderMatchCondition) []H
 //   - a 'present' and a 'notpresent' condition for the same header
 //   - an 'exact' and a 'notexact' condition for the same header, with the same values
 //   - a 'contains' and a 'notcontains' condition for the same header, with the same values
+//   - a 'regex' condition with an invalid regular expression
+//   - an 'ignoreCase' flag on a 'present', 'notpresent' or 'regex' condition
 //
 // Note that there are additional, more complex scenarios that we could check for here. For
 // example, "exact: foo" and "notcontains: <any substring of foo>" are contradictory.
@@ -146,6 +239,10 @@ func headerMatchConditionsValid(conditions []contour_api_v1.MatchCondition) erro
 			continue
 		}
 
+		if v.Header.IgnoreCase && (v.Header.Present || v.Header.NotPresent || v.Header.Regex != "") {
+			return errors.New("cannot specify 'ignoreCase' on 'present', 'notpresent' or 'regex' header conditions")
+		}
+
 		headerName := strings.ToLower(v.Header.Name)
 		switch {
 		case v.Header.Present:
@@ -200,17 +297,124 @@ func headerMatchConditionsValid(conditions []contour_api_v1.MatchCondition) erro
 			}] {
 				return errors.New("cannot specify contradictory 'contains' and 'notcontains' conditions for the same route and header")
 			}
+		case v.Header.Regex != "":
+			if err := ValidateRegex(v.Header.Regex); err != nil {
+				return fmt.Errorf("invalid header regex condition %q: %s", v.Header.Regex, err)
+			}
 		}
 
 		key := *v.Header
 		// use the lower-cased header name so comparisons are case-insensitive
 		key.Name = headerName
+		// contradictions don't depend on whether the values are compared
+		// without regard to case
+		key.IgnoreCase = false
 		seenMatchConditions[key] = true
 	}
 
 	return nil
 }
 
+func mergeQueryParamMatchConditions(conds []contour_api_v1.MatchCondition) []QueryParamMatchCondition {
+	var qc []QueryParamMatchCondition
+
+	for _, cond := range conds {
+		if cond.QueryParameter == nil {
+			continue
+		}
+
+		q := cond.QueryParameter
+		switch {
+		case q.Exact != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      q.Name,
+				Value:     q.Exact,
+				MatchType: QueryParamMatchTypeExact,
+			})
+		case q.Prefix != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      q.Name,
+				Value:     q.Prefix,
+				MatchType: QueryParamMatchTypePrefix,
+			})
+		case q.Suffix != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      q.Name,
+				Value:     q.Suffix,
+				MatchType: QueryParamMatchTypeSuffix,
+			})
+		case q.Regex != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      q.Name,
+				Value:     q.Regex,
+				MatchType: QueryParamMatchTypeRegex,
+			})
+		case q.Contains != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      q.Name,
+				Value:     q.Contains,
+				MatchType: QueryParamMatchTypeContains,
+			})
+		case q.Present:
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      q.Name,
+				MatchType: QueryParamMatchTypePresent,
+			})
+		}
+	}
+	return qc
+}
+
+// queryParameterMatchConditionsValid validates that the query parameter
+// conditions within a slice of MatchConditions are valid. Specifically, it
+// returns an error for any of the following scenarios:
+//   - a condition without a name
+//   - a condition that doesn't specify exactly one match type
+//   - a regex condition with an invalid regular expression
+//   - more than 1 'exact' condition for the same query parameter
+func queryParameterMatchConditionsValid(conditions []contour_api_v1.MatchCondition) error {
+	queryParamsWithExactMatch := map[string]bool{}
+
+	for _, v := range conditions {
+		if v.QueryParameter == nil {
+			continue
+		}
+
+		q := v.QueryParameter
+		if q.Name == "" {
+			return errors.New("query parameter conditions must specify a name")
+		}
+
+		set := 0
+		for _, value := range []string{q.Exact, q.Prefix, q.Suffix, q.Regex, q.Contains} {
+			if value != "" {
+				set++
+			}
+		}
+		if q.Present {
+			set++
+		}
+		if set != 1 {
+			return errors.New("a query parameter condition must specify exactly one of exact, prefix, suffix, regex, contains or present")
+		}
+
+		if q.Regex != "" {
+			if err := ValidateRegex(q.Regex); err != nil {
+				return fmt.Errorf("invalid query parameter regex condition %q: %s", q.Regex, err)
+			}
+		}
+
+		if q.Exact != "" {
+			if queryParamsWithExactMatch[q.Name] {
+				return errors.New("cannot specify duplicate query parameter 'exact match' conditions in the same route")
+			}
+			queryParamsWithExactMatch[q.Name] = true
+		}
+	}
+
+	return nil
+}
+
 // ValidateRegex returns an error if the supplied
 // RE2 regex syntax is invalid.
 func ValidateRegex(regex string) error {
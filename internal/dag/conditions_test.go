@@ -82,6 +82,44 @@ func TestPathMatchCondition(t *testing.T) {
 			}},
 			want: &PrefixMatchCondition{Prefix: "/"},
 		},
+		"exact": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Exact: "/a",
+			}},
+			want: &ExactMatchCondition{Path: "/a"},
+		},
+		"exact below prefixes": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/a/",
+			}, {
+				Prefix: "/b",
+			}, {
+				Exact: "/c",
+			}},
+			want: &ExactMatchCondition{Path: "/a/b/c"},
+		},
+		"regex": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Regex: "/a/[0-9]+",
+			}},
+			want: &RegexMatchCondition{Regex: "/a/[0-9]+"},
+		},
+		"regex below prefix": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/a.b/",
+			}, {
+				Regex: "/c|/d",
+			}},
+			want: &RegexMatchCondition{Regex: `/a\.b(?:/c|/d)`},
+		},
+		"regex without slash below prefix": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/a/",
+			}, {
+				Regex: "[0-9]+",
+			}},
+			want: &RegexMatchCondition{Regex: "/a/(?:[0-9]+)"},
+		},
 	}
 
 	for name, tc := range tests {
@@ -202,6 +240,62 @@ func TestHeaderMatchConditions(t *testing.T) {
 				Invert:    true,
 			}},
 		},
+		"header prefix ignore case": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:       "user-agent",
+					Prefix:     "Mozilla/",
+					IgnoreCase: true,
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:       "user-agent",
+				MatchType:  "prefix",
+				Value:      "Mozilla/",
+				IgnoreCase: true,
+			}},
+		},
+		"header suffix": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:   "x-tenant",
+					Suffix: ".example.com",
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      "x-tenant",
+				MatchType: "suffix",
+				Value:     ".example.com",
+			}},
+		},
+		"header regex": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:  "x-tenant",
+					Regex: "tenant-[0-9]+",
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      "x-tenant",
+				MatchType: "regex",
+				Value:     "tenant-[0-9]+",
+			}},
+		},
+		"header exact ignore case": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:       "x-request-id",
+					Exact:      "ABCDEF",
+					IgnoreCase: true,
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:       "x-request-id",
+				MatchType:  "exact",
+				Value:      "ABCDEF",
+				IgnoreCase: true,
+			}},
+		},
 		"two header contains": {
 			matchconditions: []contour_api_v1.MatchCondition{{
 				Header: &contour_api_v1.HeaderMatchCondition{
@@ -317,6 +411,45 @@ func TestPrefixMatchConditionsValid(t *testing.T) {
 			}},
 			want: false,
 		},
+		"valid exact condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Exact: "/api",
+			}},
+			want: true,
+		},
+		"invalid exact condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Exact: "api",
+			}},
+			want: false,
+		},
+		"valid regex condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Regex: "/api/v[0-9]+/.*",
+			}},
+			want: true,
+		},
+		"invalid regex condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Regex: "/api/v[0-9+",
+			}},
+			want: false,
+		},
+		"prefix and exact in one condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/api",
+				Exact:  "/api",
+			}},
+			want: false,
+		},
+		"prefix and regex matchconditions": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/api",
+			}, {
+				Regex: "/v[0-9]+",
+			}},
+			want: false,
+		},
 	}
 
 	for name, tc := range tests {
@@ -327,6 +460,52 @@ func TestPrefixMatchConditionsValid(t *testing.T) {
 	}
 }
 
+func TestPathMatchConditionsInheritable(t *testing.T) {
+	tests := map[string]struct {
+		inherited       []contour_api_v1.MatchCondition
+		matchconditions []contour_api_v1.MatchCondition
+		want            bool
+	}{
+		"prefix below prefix": {
+			inherited:       []contour_api_v1.MatchCondition{{Prefix: "/api"}},
+			matchconditions: []contour_api_v1.MatchCondition{{Prefix: "/v1"}},
+			want:            true,
+		},
+		"exact below prefix": {
+			inherited:       []contour_api_v1.MatchCondition{{Prefix: "/api"}},
+			matchconditions: []contour_api_v1.MatchCondition{{Exact: "/v1"}},
+			want:            true,
+		},
+		"header below exact": {
+			inherited: []contour_api_v1.MatchCondition{{Exact: "/api"}},
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:    "x-header",
+					Present: true,
+				},
+			}},
+			want: true,
+		},
+		"prefix below exact": {
+			inherited:       []contour_api_v1.MatchCondition{{Exact: "/api"}},
+			matchconditions: []contour_api_v1.MatchCondition{{Prefix: "/v1"}},
+			want:            false,
+		},
+		"regex below regex": {
+			inherited:       []contour_api_v1.MatchCondition{{Prefix: "/"}, {Regex: "/api/.*"}},
+			matchconditions: []contour_api_v1.MatchCondition{{Regex: "/v[0-9]+"}},
+			want:            false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := pathMatchConditionsInheritable(tc.inherited, tc.matchconditions)
+			assert.Equal(t, tc.want, err == nil)
+		})
+	}
+}
+
 func TestValidateHeaderMatchConditions(t *testing.T) {
 	tests := map[string]struct {
 		matchconditions []contour_api_v1.MatchCondition
@@ -533,6 +712,80 @@ func TestValidateHeaderMatchConditions(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"valid regex, prefix and suffix matchconditions": {
+			matchconditions: []contour_api_v1.MatchCondition{
+				{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:  "x-tenant",
+						Regex: "tenant-[0-9]+",
+					},
+				}, {
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:       "user-agent",
+						Prefix:     "curl/",
+						IgnoreCase: true,
+					},
+				}, {
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:   "x-host",
+						Suffix: ".example.com",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		"invalid regex": {
+			matchconditions: []contour_api_v1.MatchCondition{
+				{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:  "x-tenant",
+						Regex: "tenant-[0-9",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		"ignore case on present": {
+			matchconditions: []contour_api_v1.MatchCondition{
+				{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:       "x-header",
+						Present:    true,
+						IgnoreCase: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		"ignore case on regex": {
+			matchconditions: []contour_api_v1.MatchCondition{
+				{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:       "x-tenant",
+						Regex:      "tenant-[0-9]+",
+						IgnoreCase: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		"exact and notexact with ignore case": {
+			matchconditions: []contour_api_v1.MatchCondition{
+				{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:       "x-header",
+						Exact:      "abc",
+						IgnoreCase: true,
+					},
+				}, {
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:     "x-header",
+						NotExact: "abc",
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -549,3 +802,214 @@ func TestValidateHeaderMatchConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryParamMatchConditions(t *testing.T) {
+	tests := map[string]struct {
+		matchconditions []contour_api_v1.MatchCondition
+		want            []QueryParamMatchCondition
+	}{
+		"empty condition list": {
+			matchconditions: nil,
+			want:            nil,
+		},
+		"prefix": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/",
+			}},
+			want: nil,
+		},
+		"query parameter exact": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "search",
+					Exact: "term",
+				},
+			}},
+			want: []QueryParamMatchCondition{{
+				Name:      "search",
+				Value:     "term",
+				MatchType: "exact",
+			}},
+		},
+		"query parameter present": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:    "debug",
+					Present: true,
+				},
+			}},
+			want: []QueryParamMatchCondition{{
+				Name:      "debug",
+				MatchType: "present",
+			}},
+		},
+		"inherited and route query parameters": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/api",
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:   "version",
+					Prefix: "v1",
+				},
+			}, {
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:    "x-request-id",
+					Present: true,
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:   "format",
+					Suffix: "json",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "id",
+					Regex: "[0-9]+",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:     "tags",
+					Contains: "blue",
+				},
+			}},
+			want: []QueryParamMatchCondition{{
+				Name:      "version",
+				Value:     "v1",
+				MatchType: "prefix",
+			}, {
+				Name:      "format",
+				Value:     "json",
+				MatchType: "suffix",
+			}, {
+				Name:      "id",
+				Value:     "[0-9]+",
+				MatchType: "regex",
+			}, {
+				Name:      "tags",
+				Value:     "blue",
+				MatchType: "contains",
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := mergeQueryParamMatchConditions(tc.matchconditions)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValidateQueryParameterMatchConditions(t *testing.T) {
+	tests := map[string]struct {
+		matchconditions []contour_api_v1.MatchCondition
+		wantErr         bool
+	}{
+		"empty condition list": {
+			matchconditions: nil,
+			wantErr:         false,
+		},
+		"prefix and header only": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/blog",
+			}, {
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:  "x-header",
+					Exact: "abc",
+				},
+			}},
+			wantErr: false,
+		},
+		"valid matchconditions": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "search",
+					Exact: "term",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:    "debug",
+					Present: true,
+				},
+			}},
+			wantErr: false,
+		},
+		"missing name": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Exact: "term",
+				},
+			}},
+			wantErr: true,
+		},
+		"no match type": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name: "search",
+				},
+			}},
+			wantErr: true,
+		},
+		"more than one match type": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:    "search",
+					Prefix:  "te",
+					Present: true,
+				},
+			}},
+			wantErr: true,
+		},
+		"invalid regex": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "id",
+					Regex: "[0-9",
+				},
+			}},
+			wantErr: true,
+		},
+		"duplicate exact conditions": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "search",
+					Exact: "term",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "search",
+					Exact: "other",
+				},
+			}},
+			wantErr: true,
+		},
+		"exact conditions on differently cased names": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "search",
+					Exact: "term",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "Search",
+					Exact: "term",
+				},
+			}},
+			wantErr: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotErr := queryParameterMatchConditionsValid(tc.matchconditions)
+
+			if !tc.wantErr {
+				assert.NoError(t, gotErr)
+			}
+
+			if tc.wantErr {
+				assert.Error(t, gotErr)
+			}
+		})
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"strings"
 	"time"
 
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/status"
 	"github.com/projectcontour/contour/internal/timeout"
 	v1 "k8s.io/api/core/v1"
@@ -132,6 +134,14 @@ const (
 	// HeaderMatchTypeRegex matches a header if it matches the provided regular
 	// expression.
 	HeaderMatchTypeRegex = "regex"
+
+	// HeaderMatchTypePrefix matches a header value if it starts with the
+	// provided value.
+	HeaderMatchTypePrefix = "prefix"
+
+	// HeaderMatchTypeSuffix matches a header value if it ends with the
+	// provided value.
+	HeaderMatchTypeSuffix = "suffix"
 )
 
 // HeaderMatchCondition matches request headers by MatchType
@@ -140,6 +150,10 @@ type HeaderMatchCondition struct {
 	Value     string
 	MatchType string
 	Invert    bool
+
+	// IgnoreCase compares the header value to Value without regard
+	// to case. It has no effect on present and regex matches.
+	IgnoreCase bool
 }
 
 func (hc *HeaderMatchCondition) String() string {
@@ -148,6 +162,7 @@ func (hc *HeaderMatchCondition) String() string {
 		"value=" + hc.Value,
 		"matchtype=", hc.MatchType,
 		"invert=", strconv.FormatBool(hc.Invert),
+		"ignorecase=", strconv.FormatBool(hc.IgnoreCase),
 	}, "&")
 
 	return "header: " + details
@@ -156,6 +171,26 @@ func (hc *HeaderMatchCondition) String() string {
 const (
 	// QueryParamMatchTypeExact matches a querystring parameter value exactly.
 	QueryParamMatchTypeExact = "exact"
+
+	// QueryParamMatchTypePrefix matches a querystring parameter value if it
+	// starts with the provided value.
+	QueryParamMatchTypePrefix = "prefix"
+
+	// QueryParamMatchTypeSuffix matches a querystring parameter value if it
+	// ends with the provided value.
+	QueryParamMatchTypeSuffix = "suffix"
+
+	// QueryParamMatchTypeRegex matches a querystring parameter value if it
+	// matches the provided regular expression.
+	QueryParamMatchTypeRegex = "regex"
+
+	// QueryParamMatchTypeContains matches a querystring parameter value if
+	// it contains the provided value.
+	QueryParamMatchTypeContains = "contains"
+
+	// QueryParamMatchTypePresent matches a querystring parameter if it is
+	// present in a request.
+	QueryParamMatchTypePresent = "present"
 )
 
 // QueryParamMatchCondition matches querystring parameters by MatchType
@@ -213,6 +248,17 @@ type Redirect struct {
 	Prefix string
 }
 
+// RegexRewrite rewrites the path of a request during forwarding by
+// substituting every match of Pattern with Substitution.
+type RegexRewrite struct {
+	// Pattern is the regular expression matched against the path.
+	Pattern string
+
+	// Substitution replaces each match of Pattern, and may refer
+	// to its capture groups.
+	Substitution string
+}
+
 // Route defines the properties of a route to a Cluster.
 type Route struct {
 
@@ -255,12 +301,23 @@ type Route struct {
 	// Indicates that during forwarding, the matched prefix (or path) should be swapped with this value
 	PrefixRewrite string
 
+	// Indicates that during forwarding, the full path should be swapped with this value
+	FullPathRewrite string
+
+	// Indicates that during forwarding, the parts of the path matching a
+	// regular expression should be substituted
+	RegexRewrite *RegexRewrite
+
 	// Mirror Policy defines the mirroring policy for this Route.
 	MirrorPolicy *MirrorPolicy
 
 	// RequestHeadersPolicy defines how headers are managed during forwarding
 	RequestHeadersPolicy *HeadersPolicy
 
+	// AutoHostRewrite indicates that the Host header should be rewritten
+	// to the DNS name of the upstream host a request is forwarded to.
+	AutoHostRewrite bool
+
 	// ResponseHeadersPolicy defines how headers are managed during forwarding
 	ResponseHeadersPolicy *HeadersPolicy
 
@@ -271,6 +328,17 @@ type Route struct {
 	// RateLimitPolicy defines if/how requests for the route are rate limited.
 	RateLimitPolicy *RateLimitPolicy
 
+	// BufferPolicy defines if/how request bodies for the route are buffered.
+	BufferPolicy *BufferPolicy
+
+	// AccessLogPolicy overrides the access log configuration
+	// of the virtual host for requests on the route.
+	AccessLogPolicy *AccessLogPolicy
+
+	// ClientCertificateAuthorization overrides the client certificate
+	// authorization of the virtual host for requests on the route.
+	ClientCertificateAuthorization *ClientCertificateAuthorization
+
 	// RequestHashPolicies is a list of policies for configuring hashes on
 	// request attributes.
 	RequestHashPolicies []RequestHashPolicy
@@ -297,6 +365,30 @@ func (r *Route) HasPathRegex() bool {
 	return ok
 }
 
+// BufferPolicy defines the request body buffering policy for a route.
+type BufferPolicy struct {
+	// MaxRequestBytes is the maximum request body size that is
+	// buffered before a 413 response is returned.
+	MaxRequestBytes uint32
+}
+
+// AccessLogPolicy overrides the global access log configuration.
+// Fields that are not set keep the global value.
+type AccessLogPolicy struct {
+	// Format is the access log format.
+	Format contour_api_v1alpha1.AccessLogType
+
+	// FormatString is the access log format string used
+	// with the envoy format.
+	FormatString string
+
+	// JSONFields are the fields logged with the json format.
+	JSONFields contour_api_v1alpha1.AccessLogJSONFields
+
+	// Level is the verbosity level of the access log.
+	Level contour_api_v1alpha1.AccessLogLevel
+}
+
 // RouteTimeoutPolicy defines the timeout policy for a route.
 type RouteTimeoutPolicy struct {
 	// ResponseTimeout is the timeout applied to the response
@@ -522,6 +614,15 @@ type PeerValidationContext struct {
 	// SubjectName holds an optional subject name which Envoy will check against the
 	// certificate presented by the upstream.
 	SubjectName string
+	// SubjectNameType is the type of subject alternative name that SubjectName
+	// is matched against. If empty, DNS names are matched.
+	SubjectNameType contour_api_v1.SubjectAltNameType
+	// SubjectAltNames holds matchers for the subject alternative names of
+	// the certificate presented by the peer, in addition to SubjectName.
+	SubjectAltNames []SubjectAltNameMatcher
+	// CertificateHashes holds the hex encoded SHA-256 fingerprints of the
+	// certificates accepted from the peer.
+	CertificateHashes []string
 	// SkipClientCertValidation when set to true will ensure Envoy requests but
 	// does not verify peer certificates.
 	SkipClientCertValidation bool
@@ -551,6 +652,52 @@ func (pvc *PeerValidationContext) GetSubjectName() string {
 	return pvc.SubjectName
 }
 
+// GetSubjectAltNames returns the subject alternative name matchers from
+// PeerValidationContext, starting with an exact match for SubjectName.
+func (pvc *PeerValidationContext) GetSubjectAltNames() []SubjectAltNameMatcher {
+	if pvc == nil {
+		// No validation required.
+		return nil
+	}
+
+	var matchers []SubjectAltNameMatcher
+	if pvc.SubjectName != "" {
+		matchers = append(matchers, SubjectAltNameMatcher{
+			Type:      pvc.SubjectNameType,
+			MatchType: StringMatchTypeExact,
+			Value:     pvc.SubjectName,
+		})
+	}
+	return append(matchers, pvc.SubjectAltNames...)
+}
+
+const (
+	// StringMatchTypeExact matches a string exactly.
+	StringMatchTypeExact = "exact"
+
+	// StringMatchTypePrefix matches a string starting with the
+	// provided value.
+	StringMatchTypePrefix = "prefix"
+
+	// StringMatchTypeSuffix matches a string ending with the
+	// provided value.
+	StringMatchTypeSuffix = "suffix"
+
+	// StringMatchTypeRegex matches a string if it matches the
+	// provided regular expression.
+	StringMatchTypeRegex = "regex"
+)
+
+// SubjectAltNameMatcher matches subject alternative names of a given
+// type by MatchType.
+type SubjectAltNameMatcher struct {
+	// Type is the type of subject alternative name matched.
+	// If empty, DNS names are matched.
+	Type      contour_api_v1.SubjectAltNameType
+	MatchType string
+	Value     string
+}
+
 // GetCRL returns the Certificate Revocation List.
 func (pvc *PeerValidationContext) GetCRL() []byte {
 	if pvc == nil || pvc.CRL == nil {
@@ -573,6 +720,14 @@ type VirtualHost struct {
 	// are rate limited.
 	RateLimitPolicy *RateLimitPolicy
 
+	// AccessLogPolicy overrides the global access log
+	// configuration for requests to the virtual host.
+	AccessLogPolicy *AccessLogPolicy
+
+	// ClientCertificateAuthorization restricts requests to the
+	// virtual host to clients presenting matching certificates.
+	ClientCertificateAuthorization *ClientCertificateAuthorization
+
 	Routes map[string]*Route
 }
 
@@ -618,6 +773,14 @@ type SecureVirtualHost struct {
 	// DownstreamValidation defines how to verify the client's certificate.
 	DownstreamValidation *PeerValidationContext
 
+	// ForwardClientCertificate, if not nil, selects the client
+	// certificate details forwarded to backends.
+	ForwardClientCertificate *ClientCertificateDetails
+
+	// OCSPStaplePolicy defines how the OCSP response of Secret is
+	// stapled. If empty, responses are stapled leniently.
+	OCSPStaplePolicy contour_api_v1.OCSPStaplePolicy
+
 	// AuthorizationService points to the extension that client
 	// requests are forwarded to for authorization. If nil, no
 	// authorization is enabled for this host.
@@ -638,6 +801,29 @@ type SecureVirtualHost struct {
 	AuthorizationServerWithRequestBody *AuthorizationServerBufferSettings
 }
 
+// ClientCertificateAuthorization allows requests from clients whose
+// certificate principal matches one of Principals.
+type ClientCertificateAuthorization struct {
+	Principals []ClientCertificatePrincipal
+}
+
+// ClientCertificatePrincipal matches the principal of a client
+// certificate by MatchType.
+type ClientCertificatePrincipal struct {
+	MatchType string
+	Value     string
+}
+
+// ClientCertificateDetails selects the client certificate details
+// forwarded to backends in addition to the certificate hash.
+type ClientCertificateDetails struct {
+	Subject bool
+	URI     bool
+	DNS     bool
+	Cert    bool
+	Chain   bool
+}
+
 // AuthorizationServerBufferSettings enables ExtAuthz filter to buffer client
 // request data and send it as part of authorization request
 type AuthorizationServerBufferSettings struct {
@@ -680,6 +866,17 @@ type Listener struct {
 
 	VirtualHosts       []*VirtualHost
 	SecureVirtualHosts []*SecureVirtualHost
+
+	// TCPProxy is the proxy for a plain TCP listener,
+	// which has no virtual hosts.
+	TCPProxy *TCPProxy
+
+	// vhostsByName and svhostsByName hold the listener's
+	// virtual hosts while the DAG is being built. The
+	// ListenerProcessor moves the valid ones into
+	// VirtualHosts and SecureVirtualHosts.
+	vhostsByName  map[string]*VirtualHost
+	svhostsByName map[string]*SecureVirtualHost
 }
 
 // TCPProxy represents a cluster of TCP endpoints.
@@ -718,6 +915,10 @@ type Service struct {
 
 	// ExternalName is an optional field referencing a dns entry for Service type "ExternalName"
 	ExternalName string
+
+	// TopologyAwareHints is true if the Kubernetes Service asks
+	// for traffic to be kept in the zone it originated in.
+	TopologyAwareHints bool
 }
 
 // Cluster holds the connection specific parameters that apply to
@@ -740,12 +941,21 @@ type Cluster struct {
 	// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/cluster/v3/cluster.proto#enum-config-cluster-v3-cluster-lbpolicy
 	LoadBalancerPolicy string
 
+	// The strategy to use when balancing requests across the topology
+	// zones of the cluster's endpoints. One of "", "LocalityWeighted"
+	// or "ZoneAware".
+	LocalityLoadBalancerPolicy string
+
 	// Cluster http health check policy
 	*HTTPHealthCheckPolicy
 
 	// Cluster tcp health check policy
 	*TCPHealthCheckPolicy
 
+	// OutlierDetectionPolicy defines how unhealthy endpoints are
+	// passively ejected from the cluster.
+	OutlierDetectionPolicy *OutlierDetectionPolicy
+
 	// RequestHeadersPolicy defines how headers are managed during forwarding
 	RequestHeadersPolicy *HeadersPolicy
 
@@ -777,10 +987,36 @@ type Cluster struct {
 	// private key to be used when establishing TLS connection to upstream cluster.
 	ClientCertificate *Secret
 
+	// ClientCertificateSDS is the optional client certificate fetched from a
+	// secret discovery service. It takes precedence over ClientCertificate.
+	ClientCertificateSDS *SDSSecret
+
 	// TimeoutPolicy specifies how to handle timeouts for this cluster.
 	TimeoutPolicy ClusterTimeoutPolicy
 }
 
+// SecretDiscoveryService defines an extension service that Envoy
+// fetches secrets from.
+type SecretDiscoveryService struct {
+	// ExtensionService identifies the extension service serving secrets.
+	ExtensionService types.NamespacedName
+
+	// SNI is the name presented to the extension service in the TLS handshake.
+	SNI string
+
+	// Timeout is the timeout for requests to the extension service.
+	Timeout timeout.Setting
+}
+
+// SDSSecret is a secret Envoy fetches by name from a secret discovery service.
+type SDSSecret struct {
+	// Name is the name of the secret requested from the service.
+	Name string
+
+	// Service is the secret discovery service serving the secret.
+	Service *SecretDiscoveryService
+}
+
 // WeightedService represents the load balancing weight of a
 // particular v1.Weighted port.
 type WeightedService struct {
@@ -904,6 +1140,17 @@ func (s *Secret) PrivateKey() []byte {
 	return s.Object.Data[v1.TLSPrivateKeyKey]
 }
 
+// OCSPStaple returns the secret's OCSP response, if any. A response
+// that is not for the secret's certificate is ignored, since Envoy
+// would reject the whole secret.
+func (s *Secret) OCSPStaple() []byte {
+	staple, ok := s.Object.Data[OCSPStapleKey]
+	if !ok || validateOCSPStaple(staple, s.Cert()) != nil {
+		return nil
+	}
+	return staple
+}
+
 // HTTPHealthCheckPolicy http health check policy
 type HTTPHealthCheckPolicy struct {
 	Path               string
@@ -914,6 +1161,16 @@ type HTTPHealthCheckPolicy struct {
 	HealthyThreshold   uint32
 }
 
+// OutlierDetectionPolicy defines the passive health checking of a cluster.
+// Zero values mean the Envoy default applies.
+type OutlierDetectionPolicy struct {
+	ConsecutiveServerErrors  uint32
+	ConsecutiveGatewayErrors uint32
+	Interval                 time.Duration
+	BaseEjectionTime         time.Duration
+	MaxEjectionPercent       uint32
+}
+
 // TCPHealthCheckPolicy tcp health check policy
 type TCPHealthCheckPolicy struct {
 	Interval           time.Duration
@@ -16,6 +16,7 @@ package dag
 import (
 	"testing"
 
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -95,6 +96,18 @@ func TestPeerValidationContext(t *testing.T) {
 	assert.Equal(t, pvc2.GetCACertificate(), []byte(nil))
 	assert.Equal(t, pvc3.GetSubjectName(), "")
 	assert.Equal(t, pvc3.GetCACertificate(), []byte(nil))
+
+	pvc1.SubjectAltNames = []SubjectAltNameMatcher{{
+		Type:      contour_api_v1.SubjectAltNameTypeURI,
+		MatchType: StringMatchTypePrefix,
+		Value:     "spiffe://cluster.local/",
+	}}
+	assert.Equal(t, []SubjectAltNameMatcher{
+		{MatchType: StringMatchTypeExact, Value: "subject"},
+		{Type: contour_api_v1.SubjectAltNameTypeURI, MatchType: StringMatchTypePrefix, Value: "spiffe://cluster.local/"},
+	}, pvc1.GetSubjectAltNames())
+	assert.Empty(t, pvc2.GetSubjectAltNames())
+	assert.Empty(t, pvc3.GetSubjectAltNames())
 }
 
 func TestObserverFunc(t *testing.T) {
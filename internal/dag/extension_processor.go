@@ -14,6 +14,7 @@
 package dag
 
 import (
+	"errors"
 	"path"
 	"strings"
 	"time"
@@ -155,6 +156,9 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 		if uv, err := cache.LookupUpstreamValidation(v, caCertNamespacedName); err != nil {
 			validCondition.AddErrorf(contour_api_v1.ConditionTypeSpecError, "TLSUpstreamValidation",
 				"TLS upstream validation policy error: %s", err.Error())
+		} else if sni, err := ExtensionServiceSNI(v); err != nil {
+			validCondition.AddErrorf(contour_api_v1.ConditionTypeSpecError, "TLSUpstreamValidation",
+				"TLS upstream validation policy error: %s", err.Error())
 		} else {
 			extension.UpstreamValidation = uv
 
@@ -165,7 +169,7 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 			// future.
 			//
 			// TODO(jpeach): expose SNI in the API, https://github.com/projectcontour/contour/issues/2893.
-			extension.SNI = uv.SubjectName
+			extension.SNI = sni
 		}
 
 		if extension.Protocol != "h2" {
@@ -205,3 +209,25 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 
 	return &extension
 }
+
+// ExtensionServiceSNI returns the SNI server name used to connect to an
+// ExtensionService with the upstream validation uv. This is the subject
+// name if it is a DNS name, otherwise the first exact DNS subject alt name.
+// URI and IP names can't be sent as SNI, so an error is returned if there
+// is no DNS name to use.
+func ExtensionServiceSNI(uv *contour_api_v1.UpstreamValidation) (string, error) {
+	isDNS := func(t contour_api_v1.SubjectAltNameType) bool {
+		return t == "" || t == contour_api_v1.SubjectAltNameTypeDNS
+	}
+
+	if uv.SubjectName != "" && isDNS(uv.SubjectNameType) {
+		return uv.SubjectName, nil
+	}
+	for _, san := range uv.SubjectAltNames {
+		if san.Exact != "" && isDNS(san.Type) {
+			return san.Exact, nil
+		}
+	}
+
+	return "", errors.New("a DNS subjectName or exact DNS subjectAltNames entry is required to use as the SNI server name")
+}
@@ -14,8 +14,10 @@
 package dag
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -37,6 +39,7 @@ import (
 const (
 	KindHTTPRoute = "HTTPRoute"
 	KindTLSRoute  = "TLSRoute"
+	KindTCPRoute  = "TCPRoute"
 	KindGateway   = "Gateway"
 )
 
@@ -55,6 +58,11 @@ type GatewayAPIProcessor struct {
 
 	// ConnectTimeout defines how long the proxy should wait when establishing connection to upstream service.
 	ConnectTimeout time.Duration
+
+	// ReservedContainerPorts holds the Envoy ports that Gateway
+	// listeners can't be served by, keyed by port, with a
+	// description of the Envoy listener using each.
+	ReservedContainerPorts map[int32]string
 }
 
 // matchConditions holds match rules.
@@ -106,7 +114,7 @@ func (p *GatewayAPIProcessor) Run(dag *DAG, source *KubernetesCache) {
 
 	// Validate listener protocols, ports and hostnames and add conditions
 	// for all invalid listeners.
-	validateListenersResult := gatewayapi.ValidateListeners(p.source.gateway.Spec.Listeners)
+	validateListenersResult := gatewayapi.ValidateListeners(p.source.gateway.Spec.Listeners, p.ReservedContainerPorts)
 	for name, cond := range validateListenersResult.InvalidListenerConditions {
 		gwAccessor.AddListenerCondition(
 			string(name),
@@ -264,6 +272,107 @@ func (p *GatewayAPIProcessor) Run(dag *DAG, source *KubernetesCache) {
 		}()
 	}
 
+	// Compute each TCPRoute for each Listener that it potentially
+	// attaches to. A TCP listener forwards all of its connections to
+	// a single TCPRoute, so the oldest route wins any conflict.
+	for _, tcpRoute := range sortTCPRoutes(p.source.tcproutes) {
+		func() {
+			routeAccessor, commit := p.dag.StatusCache.RouteConditionsAccessor(
+				k8s.NamespacedNameOf(tcpRoute),
+				tcpRoute.Generation,
+				&gatewayapi_v1alpha2.TCPRoute{},
+			)
+			defer commit()
+
+			for _, routeParentRef := range tcpRoute.Spec.ParentRefs {
+				upgradedRouteParentRef := gatewayapi.UpgradeParentRef(routeParentRef)
+
+				// If this parent ref is to a different Gateway, ignore it.
+				if !gatewayapi.IsRefToGateway(upgradedRouteParentRef, k8s.NamespacedNameOf(p.source.gateway)) {
+					continue
+				}
+
+				routeParentStatusAccessor := routeAccessor.StatusUpdateFor(upgradedRouteParentRef)
+
+				// If the Gateway is invalid, set status on the route and we're done.
+				if gatewayNotReadyCondition != nil {
+					routeParentStatusAccessor.AddCondition(gatewayapi_v1beta1.RouteConditionAccepted, metav1.ConditionFalse, status.ReasonInvalidGateway, "Invalid Gateway")
+					return
+				}
+
+				// Get the list of listeners that are (a) included by this parent ref, and
+				// (b) allow the route (based on kind, namespace).
+				allowedListeners := p.getListenersForRouteParentRef(upgradedRouteParentRef, tcpRoute.Namespace, KindTCPRoute, readyListeners, routeParentStatusAccessor)
+				if len(allowedListeners) == 0 {
+					continue
+				}
+
+				if len(tcpRoute.Spec.Rules) > 1 {
+					routeParentStatusAccessor.AddCondition(
+						gatewayapi_v1beta1.RouteConditionAccepted,
+						metav1.ConditionFalse,
+						gatewayapi_v1beta1.RouteReasonUnsupportedValue,
+						"TCPRoute must have only a single rule defined.",
+					)
+					continue
+				}
+
+				// Keep track of the number of allowed listeners that
+				// already forward to another TCPRoute so that we can
+				// set the appropriate route parent status condition
+				// if the route can't attach to any of them.
+				conflicts := 0
+				programmed := false
+
+				for _, listener := range allowedListeners {
+					if listener.dagListener.TCPProxy != nil {
+						conflicts++
+						continue
+					}
+
+					if p.computeTCPRoute(tcpRoute, routeParentStatusAccessor, listener) {
+						listenerAttachedRoutes[string(listener.listener.Name)]++
+						programmed = true
+					}
+				}
+
+				switch {
+				case conflicts == len(allowedListeners):
+					routeParentStatusAccessor.AddCondition(
+						gatewayapi_v1beta1.RouteConditionAccepted,
+						metav1.ConditionFalse,
+						gatewayapi_v1beta1.RouteReasonNotAllowedByListeners,
+						"No listener is available, each already has a TCPRoute attached.",
+					)
+				case !programmed:
+					// computeTCPRoute has already set a condition
+					// explaining why none of the backends are valid.
+					routeParentStatusAccessor.AddCondition(
+						gatewayapi_v1beta1.RouteConditionAccepted,
+						metav1.ConditionFalse,
+						status.ReasonErrorsExist,
+						"TCPRoute has no valid backends.",
+					)
+				default:
+					if !hasRouteCondition(routeParentStatusAccessor.ConditionsForParentRef(upgradedRouteParentRef), gatewayapi_v1beta1.RouteConditionResolvedRefs) {
+						routeParentStatusAccessor.AddCondition(
+							gatewayapi_v1beta1.RouteConditionResolvedRefs,
+							metav1.ConditionTrue,
+							gatewayapi_v1beta1.RouteReasonResolvedRefs,
+							"References resolved",
+						)
+					}
+					routeParentStatusAccessor.AddCondition(
+						gatewayapi_v1beta1.RouteConditionAccepted,
+						metav1.ConditionTrue,
+						gatewayapi_v1beta1.RouteReasonAccepted,
+						"Accepted TCPRoute",
+					)
+				}
+			}
+		}()
+	}
+
 	for listenerName, attachedRoutes := range listenerAttachedRoutes {
 		gwAccessor.SetListenerAttachedRoutes(listenerName, attachedRoutes)
 	}
@@ -271,6 +380,17 @@ func (p *GatewayAPIProcessor) Run(dag *DAG, source *KubernetesCache) {
 	p.computeGatewayConditions(gwAccessor, gatewayNotReadyCondition)
 }
 
+// hasRouteCondition returns true if conditions
+// contains a condition of the given type.
+func hasRouteCondition(conditions []metav1.Condition, conditionType gatewayapi_v1beta1.RouteConditionType) bool {
+	for _, c := range conditions {
+		if c.Type == string(conditionType) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *GatewayAPIProcessor) getListenersForRouteParentRef(
 	routeParentRef gatewayapi_v1beta1.ParentReference,
 	routeNamespace string,
@@ -332,6 +452,30 @@ type listenerInfo struct {
 	allowedKinds      []gatewayapi_v1beta1.Kind
 	namespaceSelector labels.Selector
 	tlsSecret         *Secret
+
+	// dagListener is the DAG listener that the Gateway listener
+	// is programmed on. It's nil for Gateway listeners served by
+	// the default HTTP and HTTPS listeners, which use the DAG's
+	// vhosts and svhosts.
+	dagListener *Listener
+}
+
+// ensureVirtualHost returns the virtual host with the provided
+// name on the listener's DAG listener, adding it if necessary.
+func (l *listenerInfo) ensureVirtualHost(dag *DAG, hostname string) *VirtualHost {
+	if l.dagListener != nil {
+		return l.dagListener.EnsureVirtualHost(hostname)
+	}
+	return dag.EnsureVirtualHost(hostname)
+}
+
+// ensureSecureVirtualHost returns the secure virtual host with the
+// provided name on the listener's DAG listener, adding it if necessary.
+func (l *listenerInfo) ensureSecureVirtualHost(dag *DAG, hostname string) *SecureVirtualHost {
+	if l.dagListener != nil {
+		return l.dagListener.EnsureSecureVirtualHost(hostname)
+	}
+	return dag.EnsureSecureVirtualHost(hostname)
 }
 
 func (l *listenerInfo) AllowsKind(kind gatewayapi_v1beta1.Kind) bool {
@@ -559,12 +703,40 @@ func (p *GatewayAPIProcessor) computeListener(
 		}
 	}
 
-	return true, &listenerInfo{
+	info := &listenerInfo{
 		listener:          listener,
 		allowedKinds:      listenerRouteKinds,
 		tlsSecret:         listenerSecret,
 		namespaceSelector: selector,
 	}
+
+	// Listeners on ports that aren't served by the default HTTP
+	// and HTTPS listeners get a DAG listener for their port.
+	if port, ok := validateListenersResult.ListenerPorts[listener.Name]; ok && port.Name != "http" && port.Name != "https" {
+		info.dagListener = p.ensureListener(port)
+	}
+
+	return true, info
+}
+
+// ensureListener returns the DAG listener for the given port, adding
+// it to the DAG if it doesn't already exist. Envoy listens on the
+// port's container port.
+func (p *GatewayAPIProcessor) ensureListener(port gatewayapi.ListenerPort) *Listener {
+	name := "ingress_" + strings.ReplaceAll(port.Name, "-", "_")
+
+	for _, listener := range p.dag.Listeners {
+		if listener.Name == name {
+			return listener
+		}
+	}
+
+	listener := &Listener{
+		Name: name,
+		Port: int(port.ContainerPort),
+	}
+	p.dag.Listeners = append(p.dag.Listeners, listener)
+	return listener
 }
 
 // getListenerRouteKinds gets a list of the valid route kinds that
@@ -580,6 +752,8 @@ func (p *GatewayAPIProcessor) getListenerRouteKinds(listener gatewayapi_v1beta1.
 			return []gatewayapi_v1beta1.Kind{KindHTTPRoute}
 		case gatewayapi_v1beta1.TLSProtocolType:
 			return []gatewayapi_v1beta1.Kind{KindTLSRoute}
+		case gatewayapi_v1beta1.TCPProtocolType:
+			return []gatewayapi_v1beta1.Kind{KindTCPRoute}
 		}
 	}
 
@@ -596,13 +770,13 @@ func (p *GatewayAPIProcessor) getListenerRouteKinds(listener gatewayapi_v1beta1.
 			)
 			continue
 		}
-		if routeKind.Kind != KindHTTPRoute && routeKind.Kind != KindTLSRoute {
+		if routeKind.Kind != KindHTTPRoute && routeKind.Kind != KindTLSRoute && routeKind.Kind != KindTCPRoute {
 			gwAccessor.AddListenerCondition(
 				string(listener.Name),
 				gatewayapi_v1beta1.ListenerConditionResolvedRefs,
 				metav1.ConditionFalse,
 				gatewayapi_v1beta1.ListenerReasonInvalidRouteKinds,
-				fmt.Sprintf("Kind %q is not supported, kind must be %q or %q", routeKind.Kind, KindHTTPRoute, KindTLSRoute),
+				fmt.Sprintf("Kind %q is not supported, kind must be %q, %q or %q", routeKind.Kind, KindHTTPRoute, KindTLSRoute, KindTCPRoute),
 			)
 			continue
 		}
@@ -616,6 +790,16 @@ func (p *GatewayAPIProcessor) getListenerRouteKinds(listener gatewayapi_v1beta1.
 			)
 			continue
 		}
+		if (routeKind.Kind == KindTCPRoute) != (listener.Protocol == gatewayapi_v1beta1.TCPProtocolType) {
+			gwAccessor.AddListenerCondition(
+				string(listener.Name),
+				gatewayapi_v1beta1.ListenerConditionResolvedRefs,
+				metav1.ConditionFalse,
+				gatewayapi_v1beta1.ListenerReasonInvalidRouteKinds,
+				fmt.Sprintf("%ss are incompatible with listener protocol %q", routeKind.Kind, listener.Protocol),
+			)
+			continue
+		}
 
 		routeKinds = append(routeKinds, routeKind.Kind)
 	}
@@ -1024,7 +1208,7 @@ func (p *GatewayAPIProcessor) computeTLSRoute(route *gatewayapi_v1alpha2.TLSRout
 		}
 
 		for host := range hosts {
-			secure := p.dag.EnsureSecureVirtualHost(host)
+			secure := listener.ensureSecureVirtualHost(p.dag, host)
 
 			if listener.tlsSecret != nil {
 				secure.Secret = listener.tlsSecret
@@ -1039,6 +1223,86 @@ func (p *GatewayAPIProcessor) computeTLSRoute(route *gatewayapi_v1alpha2.TLSRout
 	return programmed, hosts
 }
 
+// sortTCPRoutes returns the TCPRoutes ordered by creation time, oldest
+// first, with ties broken by namespace and name.
+func sortTCPRoutes(m map[types.NamespacedName]*gatewayapi_v1alpha2.TCPRoute) []*gatewayapi_v1alpha2.TCPRoute {
+	routes := make([]*gatewayapi_v1alpha2.TCPRoute, 0, len(m))
+	for _, r := range m {
+		routes = append(routes, r)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if !routes[i].CreationTimestamp.Equal(&routes[j].CreationTimestamp) {
+			return routes[i].CreationTimestamp.Before(&routes[j].CreationTimestamp)
+		}
+		return k8s.NamespacedNameOf(routes[i]).String() < k8s.NamespacedNameOf(routes[j]).String()
+	})
+
+	return routes
+}
+
+// computeTCPRoute programs the backends of the TCPRoute on the given
+// TCP listener. It returns true if the route was programmed.
+func (p *GatewayAPIProcessor) computeTCPRoute(route *gatewayapi_v1alpha2.TCPRoute, routeAccessor *status.RouteParentStatusUpdate, listener *listenerInfo) bool {
+	var programmed bool
+	for _, rule := range route.Spec.Rules {
+		if len(rule.BackendRefs) == 0 {
+			routeAccessor.AddCondition(gatewayapi_v1beta1.RouteConditionResolvedRefs, metav1.ConditionFalse, status.ReasonDegraded, "At least one Spec.Rules.BackendRef must be specified.")
+			continue
+		}
+
+		var proxy TCPProxy
+		var totalWeight uint32
+
+		for _, backendRef := range rule.BackendRefs {
+
+			service, cond := p.validateBackendRef(gatewayapi.UpgradeBackendRef(backendRef), KindTCPRoute, route.Namespace)
+			if cond != nil {
+				routeAccessor.AddCondition(gatewayapi_v1beta1.RouteConditionType(cond.Type), cond.Status, gatewayapi_v1beta1.RouteConditionReason(cond.Reason), cond.Message)
+				continue
+			}
+
+			// Route defaults to a weight of "1" unless otherwise specified.
+			routeWeight := uint32(1)
+			if backendRef.Weight != nil {
+				routeWeight = uint32(*backendRef.Weight)
+			}
+
+			// Keep track of all the weights for this set of backendRefs. This will be
+			// used later to understand if all the weights are set to zero.
+			totalWeight += routeWeight
+
+			// https://github.com/projectcontour/contour/issues/3593
+			service.Weighted.Weight = routeWeight
+			proxy.Clusters = append(proxy.Clusters, &Cluster{
+				Upstream:      service,
+				SNI:           service.ExternalName,
+				Weight:        routeWeight,
+				TimeoutPolicy: ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
+			})
+		}
+
+		// No clusters added: they were all invalid, so reject
+		// the route (it already has a relevant condition set).
+		if len(proxy.Clusters) == 0 {
+			continue
+		}
+
+		// If we have valid clusters but they all have a zero
+		// weight, reject the route.
+		if totalWeight == 0 {
+			routeAccessor.AddCondition(status.ConditionValidBackendRefs, metav1.ConditionFalse, status.ReasonAllBackendRefsHaveZeroWeights, "At least one Spec.Rules.BackendRef must have a non-zero weight.")
+			continue
+		}
+
+		listener.dagListener.TCPProxy = &proxy
+
+		programmed = true
+	}
+
+	return programmed
+}
+
 func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1beta1.HTTPRoute, routeAccessor *status.RouteParentStatusUpdate, listener *listenerInfo) (bool, sets.String) {
 	hosts, errs := p.computeHosts(route.Spec.Hostnames, gatewayapi.HostnameDeref(listener.listener.Hostname))
 	for _, err := range errs {
@@ -1099,6 +1363,7 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1beta1.HTTPRou
 			headerPolicy       *HeadersPolicy
 			headerModifierSeen bool
 			redirect           *gatewayapi_v1beta1.HTTPRequestRedirectFilter
+			urlRewrite         *gatewayapi_v1beta1.HTTPURLRewriteFilter
 			mirrorPolicy       *MirrorPolicy
 		)
 
@@ -1126,6 +1391,13 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1beta1.HTTPRou
 				if redirect == nil && filter.RequestRedirect != nil {
 					redirect = filter.RequestRedirect
 				}
+			case gatewayapi_v1beta1.HTTPRouteFilterURLRewrite:
+				// Get the URL rewrite filter if there is one. Note that per Gateway API
+				// docs, "specifying a core filter multiple times has unspecified or
+				// custom conformance.", here we choose to just select the first one.
+				if urlRewrite == nil && filter.URLRewrite != nil {
+					urlRewrite = filter.URLRewrite
+				}
 			case gatewayapi_v1beta1.HTTPRouteFilterRequestMirror:
 				// Get the mirror filter if there is one. If there are more than one
 				// mirror filters, "NotImplemented" condition on the Route is set to
@@ -1150,7 +1422,28 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1beta1.HTTPRou
 
 			default:
 				routeAccessor.AddCondition(status.ConditionNotImplemented, metav1.ConditionTrue, status.ReasonHTTPRouteFilterType,
-					fmt.Sprintf("HTTPRoute.Spec.Rules.Filters: invalid type %q: only RequestHeaderModifier, RequestRedirect and RequestMirror are supported.", filter.Type))
+					fmt.Sprintf("HTTPRoute.Spec.Rules.Filters: invalid type %q: only RequestHeaderModifier, RequestRedirect, RequestMirror and URLRewrite are supported.", filter.Type))
+			}
+		}
+
+		var prefixRewrite, fullPathRewrite string
+		if urlRewrite != nil {
+			if urlRewrite.Hostname != nil {
+				if headerPolicy == nil {
+					headerPolicy = &HeadersPolicy{}
+				}
+				headerPolicy.HostRewrite = string(*urlRewrite.Hostname)
+			}
+
+			if urlRewrite.Path != nil {
+				var err error
+				prefixRewrite, fullPathRewrite, err = gatewayPathRewrite(urlRewrite.Path)
+				if err != nil {
+					// Forwarding requests without the rewrite would send
+					// them to unexpected paths, so drop the rule instead.
+					routeAccessor.AddCondition(status.ConditionNotImplemented, metav1.ConditionTrue, status.ReasonHTTPRouteFilterType, err.Error())
+					continue
+				}
 			}
 		}
 
@@ -1163,6 +1456,11 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1beta1.HTTPRou
 			routes = p.redirectRoutes(matchconditions, headerPolicy, redirect)
 		} else {
 			routes = p.clusterRoutes(route.Namespace, matchconditions, headerPolicy, mirrorPolicy, rule.BackendRefs, routeAccessor)
+
+			for _, route := range routes {
+				route.PrefixRewrite = prefixRewrite
+				route.FullPathRewrite = fullPathRewrite
+			}
 		}
 
 		// Add each route to the relevant vhost(s)/svhosts(s).
@@ -1170,11 +1468,11 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1beta1.HTTPRou
 			for _, route := range routes {
 				switch {
 				case listener.tlsSecret != nil:
-					svhost := p.dag.EnsureSecureVirtualHost(host)
+					svhost := listener.ensureSecureVirtualHost(p.dag, host)
 					svhost.Secret = listener.tlsSecret
 					svhost.AddRoute(route)
 				default:
-					vhost := p.dag.EnsureVirtualHost(host)
+					vhost := listener.ensureVirtualHost(p.dag, host)
 					vhost.AddRoute(route)
 				}
 
@@ -1258,6 +1556,35 @@ func (p *GatewayAPIProcessor) validateBackendObjectRef(backendObjectRef gatewaya
 	return service, nil
 }
 
+// gatewayPathRewrite returns the prefix or full path rewrite for the
+// supplied HTTPPathModifier. At most one of the returned values is set.
+func gatewayPathRewrite(modifier *gatewayapi_v1beta1.HTTPPathModifier) (string, string, error) {
+	switch modifier.Type {
+	case gatewayapi_v1beta1.PrefixMatchHTTPPathModifier:
+		if modifier.ReplacePrefixMatch == nil {
+			return "", "", errors.New("HTTPRoute.Spec.Rules.Filters.URLRewrite.Path.ReplacePrefixMatch must be specified")
+		}
+
+		replacement := *modifier.ReplacePrefixMatch
+		if !strings.HasPrefix(replacement, "/") {
+			return "", "", errors.New("HTTPRoute.Spec.Rules.Filters.URLRewrite.Path.ReplacePrefixMatch must start with '/'")
+		}
+		return replacement, "", nil
+	case gatewayapi_v1beta1.FullPathHTTPPathModifier:
+		if modifier.ReplaceFullPath == nil {
+			return "", "", errors.New("HTTPRoute.Spec.Rules.Filters.URLRewrite.Path.ReplaceFullPath must be specified")
+		}
+
+		replacement := *modifier.ReplaceFullPath
+		if !strings.HasPrefix(replacement, "/") {
+			return "", "", errors.New("HTTPRoute.Spec.Rules.Filters.URLRewrite.Path.ReplaceFullPath must start with '/'")
+		}
+		return "", replacement, nil
+	default:
+		return "", "", fmt.Errorf("HTTPRoute.Spec.Rules.Filters.URLRewrite.Path: invalid type %q: only ReplacePrefixMatch and ReplaceFullPath are supported", modifier.Type)
+	}
+}
+
 func gatewayPathMatchCondition(match *gatewayapi_v1beta1.HTTPPathMatch, routeAccessor *status.RouteParentStatusUpdate) (MatchCondition, bool) {
 	if match == nil {
 		return &PrefixMatchCondition{Prefix: "/"}, true
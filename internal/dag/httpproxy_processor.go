@@ -83,6 +83,10 @@ type HTTPProxyProcessor struct {
 	// private key to be used when establishing TLS connection to upstream cluster.
 	ClientCertificate *types.NamespacedName
 
+	// SecretDiscoveryService is the optional secret discovery service
+	// that services may fetch their client certificates from.
+	SecretDiscoveryService *SecretDiscoveryService
+
 	// Request headers that will be set on all routes (optional).
 	RequestHeadersPolicy *HeadersPolicy
 
@@ -91,6 +95,14 @@ type HTTPProxyProcessor struct {
 
 	// ConnectTimeout defines how long the proxy should wait when establishing connection to upstream service.
 	ConnectTimeout time.Duration
+
+	// AccessLogService is true when access logs are sent to the
+	// access log service, which supports fewer JSON fields.
+	AccessLogService bool
+
+	// AccessLogFormat is the global access log format, used by
+	// access log policies that don't set their own.
+	AccessLogFormat contour_api_v1alpha1.AccessLogType
 }
 
 // Run translates HTTPProxies into DAG objects and
@@ -200,10 +212,45 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 				return
 			}
 
+			// The OCSP response is checked against the staple policy of
+			// this virtual host, since the Secret may be shared by other
+			// virtual hosts, Ingresses and Gateways.
+			if staple, ok := sec.Data()[OCSPStapleKey]; ok {
+				err := validateOCSPStaple(staple, sec.Cert())
+
+				switch tls.OCSPStaplePolicy {
+				case contour_api_v1.OCSPStaplePolicyStrict, contour_api_v1.OCSPStaplePolicyMust:
+					// Strict and must policies fail the handshake rather
+					// than skip a response that is revoked.
+					if err == nil {
+						err = validateOCSPStapleStatus(staple)
+					}
+					if err != nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotValid",
+							"Spec.VirtualHost.TLS Secret %q has an OCSP response that can't be used with ocspStaplePolicy %s: %s", tls.SecretName, tls.OCSPStaplePolicy, err)
+						return
+					}
+				default:
+					if err != nil {
+						validCond.AddWarningf(contour_api_v1.ConditionTypeTLSError, "OCSPStapleIgnored",
+							"Spec.VirtualHost.TLS Secret %q has an OCSP response that is not stapled: %s", tls.SecretName, err)
+					}
+				}
+			}
+
+			// Every handshake would fail if stapling is required
+			// but there is no OCSP response to staple.
+			if tls.OCSPStaplePolicy == contour_api_v1.OCSPStaplePolicyMust && len(sec.OCSPStaple()) == 0 {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotPresent",
+					"Spec.VirtualHost.TLS Secret %q has no %s key, which is required by ocspStaplePolicy %s", tls.SecretName, OCSPStapleKey, tls.OCSPStaplePolicy)
+				return
+			}
+
 			svhost := p.dag.EnsureSecureVirtualHost(host)
 			svhost.Secret = sec
 			// default to a minimum TLS version of 1.2 if it's not specified
 			svhost.MinTLSVersion = annotation.MinTLSVersion(tls.MinimumProtocolVersion, "1.2")
+			svhost.OCSPStaplePolicy = tls.OCSPStaplePolicy
 
 			// Check if FallbackCertificate && ClientValidation are both enabled in the same vhost
 			if tls.EnableFallbackCertificate && tls.ClientValidation != nil {
@@ -277,7 +324,23 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 					dv.CRL = crl
 					dv.OnlyVerifyLeafCertCrl = tls.ClientValidation.OnlyVerifyLeafCertCrl
 				}
+				if dv.SubjectAltNames, err = subjectAltNameMatchers(tls.ClientValidation.SubjectAltNames); err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+						"Spec.VirtualHost.TLS client validation is invalid: %s", err)
+					return
+				}
+				if dv.CertificateHashes, err = certificateHashes(tls.ClientValidation.CertificateHashes); err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+						"Spec.VirtualHost.TLS client validation is invalid: %s", err)
+					return
+				}
+				if svhost.ClientCertificateAuthorization, err = clientCertificateAuthorization(tls.ClientValidation.Authorization); err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+						"Spec.VirtualHost.TLS client validation is invalid: authorization: %s", err)
+					return
+				}
 				svhost.DownstreamValidation = dv
+				svhost.ForwardClientCertificate = clientCertificateDetails(tls.ClientValidation.ForwardClientCertificate)
 			}
 
 			if proxy.Spec.VirtualHost.AuthorizationConfigured() {
@@ -363,6 +426,17 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 	}
 	insecure.RateLimitPolicy = rlp
 
+	alp, err := accessLogPolicy(proxy.Spec.VirtualHost.AccessLog)
+	if err == nil {
+		err = p.validateAccessLogPolicy(alp)
+	}
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "AccessLogPolicyNotValid",
+			"Spec.VirtualHost.AccessLog is invalid: %s", err)
+		return
+	}
+	insecure.AccessLogPolicy = alp
+
 	addRoutes(insecure, routes)
 
 	// if TLS is enabled for this virtual host and there is no tcp proxy defined,
@@ -378,6 +452,7 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 			return
 		}
 		secure.RateLimitPolicy = rlp
+		secure.AccessLogPolicy = alp
 
 		addRoutes(secure, routes)
 	}
@@ -439,12 +514,24 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			continue
 		}
 
+		if err := pathMatchConditionsInheritable(conditions, include.Conditions); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "PathMatchConditionsNotValid",
+				"include: %s", err)
+			continue
+		}
+
 		if err := headerMatchConditionsValid(include.Conditions); err != nil {
 			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "HeaderMatchConditionsNotValid",
 				err.Error())
 			continue
 		}
 
+		if err := queryParameterMatchConditionsValid(include.Conditions); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "QueryParameterMatchConditionsNotValid",
+				"include: %s", err)
+			continue
+		}
+
 		includedProxy, ok := p.source.httpproxies[types.NamespacedName{Name: include.Name, Namespace: namespace}]
 		if !ok {
 			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "IncludeNotFound",
@@ -453,9 +540,10 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			// Set 502 response when include was not found but include condition was valid.
 			if len(include.Conditions) > 0 {
 				routes = append(routes, &Route{
-					PathMatchCondition:    mergePathMatchConditions(include.Conditions),
-					HeaderMatchConditions: mergeHeaderMatchConditions(include.Conditions),
-					DirectResponse:        directResponse(http.StatusBadGateway, ""),
+					PathMatchCondition:        mergePathMatchConditions(include.Conditions),
+					HeaderMatchConditions:     mergeHeaderMatchConditions(include.Conditions),
+					QueryParamMatchConditions: mergeQueryParamMatchConditions(include.Conditions),
+					DirectResponse:            directResponse(http.StatusBadGateway, ""),
 				})
 			}
 
@@ -493,6 +581,12 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		if err := pathMatchConditionsInheritable(conditions, route.Conditions); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "PathMatchConditionsNotValid",
+				"route: %s", err)
+			return nil
+		}
+
 		routeConditions := conditions
 		routeConditions = append(routeConditions, route.Conditions...)
 
@@ -503,6 +597,13 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		// Look for invalid query parameter conditions on this route
+		if err := queryParameterMatchConditionsValid(routeConditions); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "QueryParameterMatchConditionsNotValid",
+				"route: %s", err)
+			return nil
+		}
+
 		reqHP, err := headersPolicyRoute(route.RequestHeadersPolicy, true /* allow Host */, dynamicHeaders)
 		if err != nil {
 			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RequestHeadersPolicyInvalid",
@@ -510,6 +611,12 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		if route.AutoHostRewrite && reqHP != nil && reqHP.HostRewrite != "" {
+			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "AutoHostRewriteNotValid",
+				"route.autoHostRewrite cannot be combined with a Host header in route.requestHeadersPolicy")
+			return nil
+		}
+
 		respHP, err := headersPolicyRoute(route.ResponseHeadersPolicy, false /* disallow Host */, dynamicHeaders)
 		if err != nil {
 			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "ResponseHeaderPolicyInvalid",
@@ -538,6 +645,47 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		bp, err := bufferPolicy(route.BufferPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "BufferPolicyNotValid",
+				"route.bufferPolicy is invalid: %s", err)
+			return nil
+		}
+
+		// Routes only carry an access log policy when they override
+		// the virtual host's, which applies to the remaining routes.
+		var alp *AccessLogPolicy
+		if route.AccessLog != nil {
+			alp, err = accessLogPolicy(rootProxy.Spec.VirtualHost.AccessLog, route.AccessLog)
+			if err == nil {
+				err = p.validateAccessLogPolicy(alp)
+			}
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "AccessLogPolicyNotValid",
+					"route.accessLog is invalid: %s", err)
+				return nil
+			}
+		}
+
+		cca, err := clientCertificateAuthorization(route.ClientCertificateAuthorization)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "ClientCertificateAuthorizationNotValid",
+				"route.clientCertificateAuthorization is invalid: %s", err)
+			return nil
+		}
+		if cca != nil {
+			if tls := rootProxy.Spec.VirtualHost.TLS; tls == nil || tls.ClientValidation == nil {
+				validCond.AddError(contour_api_v1.ConditionTypeRouteError, "ClientCertificateAuthorizationNotValid",
+					"route.clientCertificateAuthorization requires client validation to be configured on the virtual host")
+				return nil
+			}
+			if route.PermitInsecure && !p.DisablePermitInsecure {
+				validCond.AddError(contour_api_v1.ConditionTypeRouteError, "ClientCertificateAuthorizationNotValid",
+					"route.clientCertificateAuthorization cannot be combined with permitInsecure")
+				return nil
+			}
+		}
+
 		requestHashPolicies, lbPolicy := loadBalancerRequestHashPolicies(route.LoadBalancerPolicy, validCond)
 
 		redirectPolicy, err := redirectRoutePolicy(route.RequestRedirectPolicy)
@@ -550,19 +698,24 @@ func (p *HTTPProxyProcessor) computeRoutes(
 		directPolicy := directResponsePolicy(route.DirectResponsePolicy)
 
 		r := &Route{
-			PathMatchCondition:    mergePathMatchConditions(routeConditions),
-			HeaderMatchConditions: mergeHeaderMatchConditions(routeConditions),
-			Websocket:             route.EnableWebsockets,
-			HTTPSUpgrade:          routeEnforceTLS(enforceTLS, route.PermitInsecure && !p.DisablePermitInsecure),
-			TimeoutPolicy:         rtp,
-			RetryPolicy:           retryPolicy(route.RetryPolicy),
-			RequestHeadersPolicy:  reqHP,
-			ResponseHeadersPolicy: respHP,
-			CookieRewritePolicies: cookieRP,
-			RateLimitPolicy:       rlp,
-			RequestHashPolicies:   requestHashPolicies,
-			Redirect:              redirectPolicy,
-			DirectResponse:        directPolicy,
+			PathMatchCondition:             mergePathMatchConditions(routeConditions),
+			HeaderMatchConditions:          mergeHeaderMatchConditions(routeConditions),
+			QueryParamMatchConditions:      mergeQueryParamMatchConditions(routeConditions),
+			Websocket:                      route.EnableWebsockets,
+			HTTPSUpgrade:                   routeEnforceTLS(enforceTLS, route.PermitInsecure && !p.DisablePermitInsecure),
+			TimeoutPolicy:                  rtp,
+			RetryPolicy:                    retryPolicy(route.RetryPolicy),
+			RequestHeadersPolicy:           reqHP,
+			AutoHostRewrite:                route.AutoHostRewrite,
+			ResponseHeadersPolicy:          respHP,
+			CookieRewritePolicies:          cookieRP,
+			RateLimitPolicy:                rlp,
+			BufferPolicy:                   bp,
+			AccessLogPolicy:                alp,
+			RequestHashPolicies:            requestHashPolicies,
+			ClientCertificateAuthorization: cca,
+			Redirect:                       redirectPolicy,
+			DirectResponse:                 directPolicy,
 		}
 
 		// If the enclosing root proxy enabled authorization,
@@ -587,6 +740,38 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			r.AuthContext = route.AuthorizationContext(rootProxy.Spec.VirtualHost.AuthorizationContext())
 		}
 
+		if policy := route.PathRewritePolicy; policy != nil {
+			set := 0
+			if len(policy.ReplacePrefix) > 0 {
+				set++
+			}
+			if policy.RegexRewrite != nil {
+				set++
+			}
+			if policy.ReplaceFullPath != "" {
+				set++
+			}
+			if set > 1 {
+				validCond.AddError(contour_api_v1.ConditionTypeRouteError, "PathRewritePolicyNotValid",
+					"route.pathRewritePolicy must specify only one of replacePrefix, regexRewrite or replaceFullPath")
+				return nil
+			}
+
+			r.RegexRewrite, err = regexRewrite(policy.RegexRewrite)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "PathRewritePolicyNotValid",
+					"route.pathRewritePolicy is invalid: %s", err)
+				return nil
+			}
+
+			r.FullPathRewrite, err = fullPathRewrite(policy.ReplaceFullPath)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "PathRewritePolicyNotValid",
+					"route.pathRewritePolicy is invalid: %s", err)
+				return nil
+			}
+		}
+
 		if len(route.GetPrefixReplacements()) > 0 {
 			if !r.HasPathPrefix() {
 				validCond.AddError(contour_api_v1.ConditionTypePrefixReplaceError, "MustHavePrefix",
@@ -670,12 +855,30 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			dynamicHeaders["CONTOUR_SERVICE_NAME"] = service.Name
 			dynamicHeaders["CONTOUR_SERVICE_PORT"] = strconv.Itoa(service.Port)
 
+			if route.AutoHostRewrite && s.ExternalName == "" {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "AutoHostRewriteNotValid",
+					"service %q: route.autoHostRewrite requires services of type ExternalName", service.Name)
+				return nil
+			}
+
 			reqHP, err := headersPolicyService(p.RequestHeadersPolicy, service.RequestHeadersPolicy, dynamicHeaders)
 			if err != nil {
 				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "RequestHeadersPolicyInvalid",
 					"%s on request headers", err)
 				return nil
 			}
+
+			if service.HostRewrite {
+				if s.ExternalName == "" {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "HostRewriteNotValid",
+						"service %q: hostRewrite requires a service of type ExternalName", service.Name)
+					return nil
+				}
+				if reqHP == nil {
+					reqHP = &HeadersPolicy{}
+				}
+				reqHP.HostRewrite = s.ExternalName
+			}
 			respHP, err := headersPolicyService(p.ResponseHeadersPolicy, service.ResponseHeadersPolicy, dynamicHeaders)
 			if err != nil {
 				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "ResponseHeadersPolicyInvalid",
@@ -690,6 +893,13 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				return nil
 			}
 
+			odp, err := outlierDetectionPolicy(service.OutlierDetection)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "OutlierDetectionInvalid",
+					"service %q: outlierDetection is invalid: %s", service.Name, err)
+				return nil
+			}
+
 			var clientCertSecret *Secret
 			if p.ClientCertificate != nil {
 				clientCertSecret, err = p.source.LookupSecret(*p.ClientCertificate, validTLSSecret)
@@ -700,20 +910,67 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				}
 			}
 
+			var clientCertSDS *SDSSecret
+			if cc := service.ClientCertificate; cc != nil {
+				if protocol != "tls" && protocol != "h2" {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "ClientCertificateNotValid",
+						"service %q: clientCertificate requires the tls or h2 protocol", service.Name)
+					return nil
+				}
+
+				switch {
+				case (cc.SecretName == "") == (cc.SDSName == ""):
+					validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "ClientCertificateNotValid",
+						"service %q: exactly one of clientCertificate.secretName or clientCertificate.sdsName must be specified", service.Name)
+					return nil
+				case cc.SecretName != "":
+					// A client certificate in another namespace must be
+					// delegated to the proxy's namespace.
+					secretName := k8s.NamespacedNameFrom(cc.SecretName, k8s.DefaultNamespace(proxy.Namespace))
+					if !p.source.DelegationPermitted(secretName, proxy.Namespace) {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "DelegationNotPermitted",
+							"service %q: clientCertificate Secret %q is not configured for certificate delegation", service.Name, secretName)
+						return nil
+					}
+
+					clientCertSecret, err = p.source.LookupSecret(secretName, validTLSSecret)
+					if err != nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+							"service %q: clientCertificate Secret %q is invalid: %s", service.Name, secretName, err)
+						return nil
+					}
+				default:
+					if p.SecretDiscoveryService == nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "ClientCertificateNotValid",
+							"service %q: clientCertificate.sdsName requires a secret discovery service to be configured", service.Name)
+						return nil
+					}
+
+					clientCertSecret = nil
+					clientCertSDS = &SDSSecret{
+						Name:    cc.SDSName,
+						Service: p.SecretDiscoveryService,
+					}
+				}
+			}
+
 			c := &Cluster{
-				Upstream:              s,
-				LoadBalancerPolicy:    lbPolicy,
-				Weight:                uint32(service.Weight),
-				HTTPHealthCheckPolicy: httpHealthCheckPolicy(route.HealthCheckPolicy),
-				UpstreamValidation:    uv,
-				RequestHeadersPolicy:  reqHP,
-				ResponseHeadersPolicy: respHP,
-				CookieRewritePolicies: cookieRP,
-				Protocol:              protocol,
-				SNI:                   determineSNI(r.RequestHeadersPolicy, reqHP, s),
-				DNSLookupFamily:       string(p.DNSLookupFamily),
-				ClientCertificate:     clientCertSecret,
-				TimeoutPolicy:         ctp,
+				Upstream:                   s,
+				LoadBalancerPolicy:         lbPolicy,
+				LocalityLoadBalancerPolicy: localityLoadBalancerPolicy(service.LocalityLoadBalancerPolicy),
+				Weight:                     uint32(service.Weight),
+				HTTPHealthCheckPolicy:      httpHealthCheckPolicy(route.HealthCheckPolicy),
+				OutlierDetectionPolicy:     odp,
+				UpstreamValidation:         uv,
+				RequestHeadersPolicy:       reqHP,
+				ResponseHeadersPolicy:      respHP,
+				CookieRewritePolicies:      cookieRP,
+				Protocol:                   protocol,
+				SNI:                        determineSNI(r.RequestHeadersPolicy, reqHP, r.AutoHostRewrite, s),
+				DNSLookupFamily:            string(p.DNSLookupFamily),
+				ClientCertificate:          clientCertSecret,
+				ClientCertificateSDS:       clientCertSDS,
+				TimeoutPolicy:              ctp,
 			}
 			if service.Mirror && r.MirrorPolicy != nil {
 				validCond.AddError(contour_api_v1.ConditionTypeServiceError, "OnlyOneMirror",
@@ -802,14 +1059,23 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 				return false
 			}
 
+			odp, err := outlierDetectionPolicy(service.OutlierDetection)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "OutlierDetectionInvalid",
+					"service %q: outlierDetection is invalid: %s", service.Name, err)
+				return false
+			}
+
 			proxy.Clusters = append(proxy.Clusters, &Cluster{
-				Upstream:             s,
-				Weight:               uint32(service.Weight),
-				Protocol:             protocol,
-				LoadBalancerPolicy:   lbPolicy,
-				TCPHealthCheckPolicy: tcpHealthCheckPolicy(tcpproxy.HealthCheckPolicy),
-				SNI:                  s.ExternalName,
-				TimeoutPolicy:        ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
+				Upstream:                   s,
+				Weight:                     uint32(service.Weight),
+				Protocol:                   protocol,
+				LoadBalancerPolicy:         lbPolicy,
+				LocalityLoadBalancerPolicy: localityLoadBalancerPolicy(service.LocalityLoadBalancerPolicy),
+				TCPHealthCheckPolicy:       tcpHealthCheckPolicy(tcpproxy.HealthCheckPolicy),
+				OutlierDetectionPolicy:     odp,
+				SNI:                        s.ExternalName,
+				TimeoutPolicy:              ClusterTimeoutPolicy{ConnectTimeout: p.ConnectTimeout},
 			})
 		}
 		secure := p.dag.EnsureSecureVirtualHost(host)
@@ -950,6 +1216,7 @@ func expandPrefixMatches(routes []*Route) []*Route {
 		// If there is no path prefix, we won't do any expansion, so skip it.
 		if !r.HasPathPrefix() {
 			expandedRoutes = append(expandedRoutes, r)
+			continue
 		}
 
 		routingPrefix := r.PathMatchCondition.(*PrefixMatchCondition).Prefix
@@ -1035,8 +1302,8 @@ func getProtocol(service contour_api_v1.Service, s *Service) (string, error) {
 
 // determineSNI decides what the SNI should be on the request. It is configured via RequestHeadersPolicy.Host key.
 // Policies set on service are used before policies set on a route. Otherwise the value of the externalService
-// is used if the route is configured to proxy to an externalService type.
-func determineSNI(routeRequestHeaders *HeadersPolicy, clusterRequestHeaders *HeadersPolicy, service *Service) string {
+// is used if the route is configured to proxy to an externalService type, or to rewrite the Host header to it.
+func determineSNI(routeRequestHeaders *HeadersPolicy, clusterRequestHeaders *HeadersPolicy, autoHostRewrite bool, service *Service) string {
 
 	// Service RequestHeadersPolicy take precedence
 	if clusterRequestHeaders != nil {
@@ -1045,6 +1312,12 @@ func determineSNI(routeRequestHeaders *HeadersPolicy, clusterRequestHeaders *Hea
 		}
 	}
 
+	// A route that rewrites the Host header to the upstream
+	// hostname sends that name as the SNI too.
+	if autoHostRewrite {
+		return service.ExternalName
+	}
+
 	// Route RequestHeadersPolicy take precedence after service
 	if routeRequestHeaders != nil {
 		if routeRequestHeaders.HostRewrite != "" {
@@ -1142,7 +1415,9 @@ func includeMatchConditionsIdentical(includes []contour_api_v1.Include) bool {
 		// Now compare each include's set of conditions
 		for _, cA := range includes[i].Conditions {
 			for _, cB := range includes[j].Conditions {
-				if (cA.Prefix == cB.Prefix) && equality.Semantic.DeepEqual(cA.Header, cB.Header) {
+				if (cA.Prefix == cB.Prefix) && (cA.Exact == cB.Exact) && (cA.Regex == cB.Regex) &&
+					equality.Semantic.DeepEqual(cA.Header, cB.Header) &&
+					equality.Semantic.DeepEqual(cA.QueryParameter, cB.QueryParameter) {
 					return true
 				}
 			}
@@ -1247,3 +1522,21 @@ func directResponsePolicy(direct *contour_api_v1.HTTPDirectResponsePolicy) *Dire
 
 	return directResponse(uint32(direct.StatusCode), direct.Body)
 }
+
+// validateAccessLogPolicy checks that the JSON fields of alp can be
+// sent to the access log service, if access logs are sent to it.
+func (p *HTTPProxyProcessor) validateAccessLogPolicy(alp *AccessLogPolicy) error {
+	if alp == nil || !p.AccessLogService {
+		return nil
+	}
+
+	format := p.AccessLogFormat
+	if alp.Format != "" {
+		format = alp.Format
+	}
+	if format != contour_api_v1alpha1.JSONAccessLog {
+		return nil
+	}
+
+	return alp.JSONFields.ValidateForAccessLogService()
+}
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +28,7 @@ func TestDetermineSNI(t *testing.T) {
 	tests := map[string]struct {
 		routeRequestHeaders   *HeadersPolicy
 		clusterRequestHeaders *HeadersPolicy
+		autoHostRewrite       bool
 		service               *Service
 		want                  string
 	}{
@@ -82,6 +84,28 @@ func TestDetermineSNI(t *testing.T) {
 			},
 			want: "containersteve.com",
 		},
+		"auto host rewrite uses externalName": {
+			routeRequestHeaders: &HeadersPolicy{
+				HostRewrite: "incorrect.com",
+			},
+			clusterRequestHeaders: nil,
+			autoHostRewrite:       true,
+			service: &Service{
+				ExternalName: "externalname.com",
+			},
+			want: "externalname.com",
+		},
+		"service host rewrite overrides auto host rewrite": {
+			routeRequestHeaders: nil,
+			clusterRequestHeaders: &HeadersPolicy{
+				HostRewrite: "containersteve.com",
+			},
+			autoHostRewrite: true,
+			service: &Service{
+				ExternalName: "externalname.com",
+			},
+			want: "containersteve.com",
+		},
 		"only externalName set": {
 			routeRequestHeaders:   nil,
 			clusterRequestHeaders: nil,
@@ -94,7 +118,7 @@ func TestDetermineSNI(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got := determineSNI(tc.routeRequestHeaders, tc.clusterRequestHeaders, tc.service)
+			got := determineSNI(tc.routeRequestHeaders, tc.clusterRequestHeaders, tc.autoHostRewrite, tc.service)
 			assert.Equal(t, tc.want, got)
 		})
 	}
@@ -292,3 +316,65 @@ func TestToCORSPolicy(t *testing.T) {
 	}
 
 }
+
+func TestValidateAccessLogPolicy(t *testing.T) {
+	invalidFields := contour_api_v1alpha1.AccessLogJSONFields{"@timestamp", "dog=pug"}
+
+	tests := map[string]struct {
+		accessLogService bool
+		accessLogFormat  contour_api_v1alpha1.AccessLogType
+		alp              *AccessLogPolicy
+		wantErr          bool
+	}{
+		"no policy": {
+			accessLogService: true,
+			accessLogFormat:  contour_api_v1alpha1.JSONAccessLog,
+		},
+		"no access log service": {
+			accessLogFormat: contour_api_v1alpha1.JSONAccessLog,
+			alp:             &AccessLogPolicy{JSONFields: invalidFields},
+		},
+		"global envoy format": {
+			accessLogService: true,
+			accessLogFormat:  contour_api_v1alpha1.EnvoyAccessLog,
+			alp:              &AccessLogPolicy{JSONFields: invalidFields},
+		},
+		"global json format": {
+			accessLogService: true,
+			accessLogFormat:  contour_api_v1alpha1.JSONAccessLog,
+			alp:              &AccessLogPolicy{JSONFields: invalidFields},
+			wantErr:          true,
+		},
+		"policy json format": {
+			accessLogService: true,
+			accessLogFormat:  contour_api_v1alpha1.EnvoyAccessLog,
+			alp: &AccessLogPolicy{
+				Format:     contour_api_v1alpha1.JSONAccessLog,
+				JSONFields: invalidFields,
+			},
+			wantErr: true,
+		},
+		"valid fields": {
+			accessLogService: true,
+			accessLogFormat:  contour_api_v1alpha1.JSONAccessLog,
+			alp: &AccessLogPolicy{
+				JSONFields: contour_api_v1alpha1.AccessLogJSONFields{"@timestamp", "pod=%ENVIRONMENT(ENVOY_POD_NAME)%"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &HTTPProxyProcessor{
+				AccessLogService: tc.accessLogService,
+				AccessLogFormat:  tc.accessLogFormat,
+			}
+			err := p.validateAccessLogPolicy(tc.alp)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
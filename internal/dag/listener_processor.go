@@ -23,35 +23,34 @@ const (
 
 // ListenerProcessor adds an HTTP and an HTTPS listener to
 // the DAG if there are virtual hosts and secure virtual
-// hosts already defined as roots in the DAG.
+// hosts already defined as roots in the DAG. It also
+// finalizes any listeners added to the DAG by earlier
+// processors, e.g. for Gateway listeners.
 type ListenerProcessor struct{}
 
 // Run adds HTTP and HTTPS listeners to the DAG if there are
 // virtual hosts and secure virtual hosts already defined as
 // roots in the DAG.
 func (p *ListenerProcessor) Run(dag *DAG, _ *KubernetesCache) {
+	listeners := dag.Listeners
+	dag.Listeners = nil
+
 	p.buildHTTPListener(dag)
 	p.buildHTTPSListener(dag)
+
+	for _, listener := range listeners {
+		p.buildListener(dag, listener)
+	}
 }
 
 // buildHTTPListener builds a *dag.Listener for the vhosts bound to port 80.
 // The list of virtual hosts attached to the listener will be sorted by hostname.
 func (p *ListenerProcessor) buildHTTPListener(dag *DAG) {
-	var vhosts []*VirtualHost
-	for _, vh := range dag.VirtualHosts {
-		if vh.Valid() {
-			vhosts = append(vhosts, vh)
-		}
-	}
-
+	vhosts := validVirtualHosts(dag.VirtualHosts)
 	if len(vhosts) == 0 {
 		return
 	}
 
-	sort.SliceStable(vhosts, func(i, j int) bool {
-		return vhosts[i].Name < vhosts[j].Name
-	})
-
 	http := &Listener{
 		Name:         HTTP_LISTENER_NAME,
 		Port:         80,
@@ -64,21 +63,11 @@ func (p *ListenerProcessor) buildHTTPListener(dag *DAG) {
 // buildHTTPSListener builds a *dag.Listener for the vhosts bound to port 443.
 // The list of virtual hosts attached to the listener will be sorted by hostname.
 func (p *ListenerProcessor) buildHTTPSListener(dag *DAG) {
-	var vhosts []*SecureVirtualHost
-	for _, svh := range dag.SecureVirtualHosts {
-		if svh.Valid() {
-			vhosts = append(vhosts, svh)
-		}
-	}
-
+	vhosts := validSecureVirtualHosts(dag.SecureVirtualHosts)
 	if len(vhosts) == 0 {
 		return
 	}
 
-	sort.SliceStable(vhosts, func(i, j int) bool {
-		return vhosts[i].Name < vhosts[j].Name
-	})
-
 	https := &Listener{
 		Name:               HTTPS_LISTENER_NAME,
 		Port:               443,
@@ -87,3 +76,51 @@ func (p *ListenerProcessor) buildHTTPSListener(dag *DAG) {
 
 	dag.Listeners = append(dag.Listeners, https)
 }
+
+// buildListener adds a listener that was added to the DAG by another
+// processor, attaching its valid vhosts and svhosts, sorted by hostname.
+// The listener is dropped if it has nothing to serve.
+func (p *ListenerProcessor) buildListener(dag *DAG, listener *Listener) {
+	listener.VirtualHosts = validVirtualHosts(listener.vhostsByName)
+	listener.SecureVirtualHosts = validSecureVirtualHosts(listener.svhostsByName)
+	listener.vhostsByName = nil
+	listener.svhostsByName = nil
+
+	if len(listener.VirtualHosts) == 0 && len(listener.SecureVirtualHosts) == 0 && listener.TCPProxy == nil {
+		return
+	}
+
+	dag.Listeners = append(dag.Listeners, listener)
+}
+
+// validVirtualHosts returns the valid vhosts, sorted by hostname.
+func validVirtualHosts(vhostsByName map[string]*VirtualHost) []*VirtualHost {
+	var vhosts []*VirtualHost
+	for _, vh := range vhostsByName {
+		if vh.Valid() {
+			vhosts = append(vhosts, vh)
+		}
+	}
+
+	sort.SliceStable(vhosts, func(i, j int) bool {
+		return vhosts[i].Name < vhosts[j].Name
+	})
+
+	return vhosts
+}
+
+// validSecureVirtualHosts returns the valid svhosts, sorted by hostname.
+func validSecureVirtualHosts(svhostsByName map[string]*SecureVirtualHost) []*SecureVirtualHost {
+	var vhosts []*SecureVirtualHost
+	for _, svh := range svhostsByName {
+		if svh.Valid() {
+			vhosts = append(vhosts, svh)
+		}
+	}
+
+	sort.SliceStable(vhosts, func(i, j int) bool {
+		return vhosts[i].Name < vhosts[j].Name
+	})
+
+	return vhosts
+}
@@ -14,6 +14,8 @@
 package dag
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
@@ -26,6 +28,7 @@ import (
 	gatewayapi_v1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/annotation"
 	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/sirupsen/logrus"
@@ -54,6 +57,14 @@ const (
 	// LoadBalancerPolicyRequestHash denotes request attribute hashing is used
 	// to make load balancing decisions.
 	LoadBalancerPolicyRequestHash = "RequestHash"
+
+	// LocalityLoadBalancerPolicyLocalityWeighted denotes requests will be
+	// spread evenly across zones, whatever the endpoints in each zone.
+	LocalityLoadBalancerPolicyLocalityWeighted = "LocalityWeighted"
+
+	// LocalityLoadBalancerPolicyZoneAware denotes requests will prefer
+	// endpoints in the same zone as the Envoy handling them.
+	LocalityLoadBalancerPolicyZoneAware = "ZoneAware"
 )
 
 // retryOn transforms a slice of retry on values to a comma-separated string.
@@ -485,6 +496,46 @@ func tcpHealthCheckPolicy(hc *contour_api_v1.TCPHealthCheckPolicy) *TCPHealthChe
 	}
 }
 
+func outlierDetectionPolicy(od *contour_api_v1.OutlierDetection) (*OutlierDetectionPolicy, error) {
+	if od == nil {
+		return nil, nil
+	}
+
+	if od.MaxEjectionPercent > 100 {
+		return nil, fmt.Errorf("maxEjectionPercent %d must be between 1 and 100", od.MaxEjectionPercent)
+	}
+
+	policy := &OutlierDetectionPolicy{
+		ConsecutiveServerErrors:  od.ConsecutiveServerErrors,
+		ConsecutiveGatewayErrors: od.ConsecutiveGatewayErrors,
+		MaxEjectionPercent:       od.MaxEjectionPercent,
+	}
+
+	if od.Interval != "" {
+		interval, err := time.ParseDuration(od.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval: %w", err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("interval %q must be greater than zero", od.Interval)
+		}
+		policy.Interval = interval
+	}
+
+	if od.BaseEjectionTime != "" {
+		baseEjectionTime, err := time.ParseDuration(od.BaseEjectionTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid baseEjectionTime: %w", err)
+		}
+		if baseEjectionTime <= 0 {
+			return nil, fmt.Errorf("baseEjectionTime %q must be greater than zero", od.BaseEjectionTime)
+		}
+		policy.BaseEjectionTime = baseEjectionTime
+	}
+
+	return policy, nil
+}
+
 // loadBalancerPolicy returns the load balancer strategy or
 // blank if no valid strategy is supplied.
 func loadBalancerPolicy(lbp *contour_api_v1.LoadBalancerPolicy) string {
@@ -499,6 +550,20 @@ func loadBalancerPolicy(lbp *contour_api_v1.LoadBalancerPolicy) string {
 	}
 }
 
+// localityLoadBalancerPolicy returns the locality load balancer
+// strategy or blank if no valid strategy is supplied.
+func localityLoadBalancerPolicy(llbp *contour_api_v1.LocalityLoadBalancerPolicy) string {
+	if llbp == nil {
+		return ""
+	}
+	switch llbp.Strategy {
+	case LocalityLoadBalancerPolicyLocalityWeighted, LocalityLoadBalancerPolicyZoneAware:
+		return llbp.Strategy
+	default:
+		return ""
+	}
+}
+
 func prefixReplacementsAreValid(replacements []contour_api_v1.ReplacePrefix) (string, error) {
 	prefixes := map[string]bool{}
 
@@ -518,6 +583,243 @@ func prefixReplacementsAreValid(replacements []contour_api_v1.ReplacePrefix) (st
 	return "", nil
 }
 
+// regexRewrite validates the regex path rewrite in the supplied policy
+// and converts it to its DAG representation.
+func regexRewrite(in *contour_api_v1.RegexRewrite) (*RegexRewrite, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if in.Pattern == "" {
+		return nil, errors.New("regexRewrite.pattern must be specified")
+	}
+
+	re, err := regexp.Compile(in.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regexRewrite.pattern %q: %s", in.Pattern, err)
+	}
+
+	if err := regexSubstitutionValid(in.Substitution, re.NumSubexp()); err != nil {
+		return nil, fmt.Errorf("invalid regexRewrite.substitution %q: %s", in.Substitution, err)
+	}
+
+	return &RegexRewrite{
+		Pattern:      in.Pattern,
+		Substitution: in.Substitution,
+	}, nil
+}
+
+// fullPathRewrite validates the full path replacement in the supplied
+// policy. Envoy performs the replacement as a regex substitution of the
+// whole path, so backslashes in the path are escape sequences.
+func fullPathRewrite(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("replaceFullPath must start with /, %s was supplied", path)
+	}
+
+	if err := regexSubstitutionValid(path, 0); err != nil {
+		return "", fmt.Errorf("invalid replaceFullPath %q: %s", path, err)
+	}
+
+	return path, nil
+}
+
+// regexSubstitutionValid returns an error if substitution has escape
+// sequences that can't be used with a regular expression that has the
+// given number of capture groups. \0 refers to the whole match, \1 to
+// \9 refer to capture groups and \\ is a literal backslash.
+func regexSubstitutionValid(substitution string, groups int) error {
+	for i := 0; i < len(substitution); i++ {
+		if substitution[i] != '\\' {
+			continue
+		}
+
+		i++
+		switch {
+		case i == len(substitution):
+			return errors.New("trailing backslash")
+		case substitution[i] == '\\':
+			continue
+		case substitution[i] >= '0' && substitution[i] <= '9':
+			if n := int(substitution[i] - '0'); n > groups {
+				return fmt.Errorf("refers to capture group %d but the pattern has %d", n, groups)
+			}
+		default:
+			return fmt.Errorf("invalid escape sequence \\%c", substitution[i])
+		}
+	}
+
+	return nil
+}
+
+func bufferPolicy(in *contour_api_v1.BufferPolicy) (*BufferPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if in.MaxRequestBytes == 0 {
+		return nil, errors.New("maxRequestBytes must be greater than zero")
+	}
+
+	return &BufferPolicy{
+		MaxRequestBytes: in.MaxRequestBytes,
+	}, nil
+}
+
+// accessLogPolicy merges the given access log policies, with fields
+// set in later policies overriding those set in earlier ones.
+func accessLogPolicy(policies ...*contour_api_v1.AccessLogPolicy) (*AccessLogPolicy, error) {
+	var out *AccessLogPolicy
+
+	for _, in := range policies {
+		if in == nil {
+			continue
+		}
+		if out == nil {
+			out = &AccessLogPolicy{}
+		}
+
+		if in.Format != "" {
+			out.Format = contour_api_v1alpha1.AccessLogType(in.Format)
+			if err := out.Format.Validate(); err != nil {
+				return nil, err
+			}
+		}
+		if in.FormatString != "" {
+			out.FormatString = in.FormatString
+			if err := contour_api_v1alpha1.AccessLogFormatString(out.FormatString).Validate(); err != nil {
+				return nil, err
+			}
+		}
+		if len(in.JSONFields) > 0 {
+			out.JSONFields = contour_api_v1alpha1.AccessLogJSONFields(in.JSONFields)
+			if err := out.JSONFields.Validate(); err != nil {
+				return nil, err
+			}
+		}
+		if in.Level != "" {
+			out.Level = contour_api_v1alpha1.AccessLogLevel(in.Level)
+			if err := out.Level.Validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// stringMatch returns the match type and value of a matcher that has
+// exactly one of exact, prefix, suffix or regex set.
+func stringMatch(exact, prefix, suffix, regex string) (string, string, error) {
+	var matchType, value string
+	set := 0
+
+	for _, m := range []struct{ matchType, value string }{
+		{StringMatchTypeExact, exact},
+		{StringMatchTypePrefix, prefix},
+		{StringMatchTypeSuffix, suffix},
+		{StringMatchTypeRegex, regex},
+	} {
+		if m.value != "" {
+			matchType, value = m.matchType, m.value
+			set++
+		}
+	}
+
+	if set != 1 {
+		return "", "", errors.New("exactly one of exact, prefix, suffix or regex must be specified")
+	}
+
+	if matchType == StringMatchTypeRegex {
+		if err := ValidateRegex(value); err != nil {
+			return "", "", fmt.Errorf("invalid regex %q: %s", value, err)
+		}
+	}
+
+	return matchType, value, nil
+}
+
+// subjectAltNameMatchers converts the API subject alternative name
+// matchers to their DAG representation.
+func subjectAltNameMatchers(matchers []contour_api_v1.SubjectAltNameMatcher) ([]SubjectAltNameMatcher, error) {
+	var sans []SubjectAltNameMatcher
+
+	for i, m := range matchers {
+		matchType, value, err := stringMatch(m.Exact, m.Prefix, m.Suffix, m.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("subjectAltNames[%d]: %s", i, err)
+		}
+
+		sans = append(sans, SubjectAltNameMatcher{
+			Type:      m.Type,
+			MatchType: matchType,
+			Value:     value,
+		})
+	}
+
+	return sans, nil
+}
+
+// certificateHashes validates that each hash is a hex encoded SHA-256
+// fingerprint, optionally colon separated, and returns the hashes
+// without separators.
+func certificateHashes(hashes []string) ([]string, error) {
+	var out []string
+
+	for _, h := range hashes {
+		hash := strings.ReplaceAll(h, ":", "")
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 certificate hash %q", h)
+		}
+		out = append(out, strings.ToLower(hash))
+	}
+
+	return out, nil
+}
+
+func clientCertificateAuthorization(in *contour_api_v1.ClientCertificateAuthorization) (*ClientCertificateAuthorization, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if len(in.Principals) == 0 {
+		return nil, errors.New("at least one principal must be specified")
+	}
+
+	out := &ClientCertificateAuthorization{}
+	for i, p := range in.Principals {
+		matchType, value, err := stringMatch(p.Exact, p.Prefix, p.Suffix, p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("principals[%d]: %s", i, err)
+		}
+
+		out.Principals = append(out.Principals, ClientCertificatePrincipal{
+			MatchType: matchType,
+			Value:     value,
+		})
+	}
+
+	return out, nil
+}
+
+func clientCertificateDetails(in *contour_api_v1.ClientCertificateDetails) *ClientCertificateDetails {
+	if in == nil {
+		return nil
+	}
+
+	return &ClientCertificateDetails{
+		Subject: in.Subject,
+		URI:     in.URI,
+		DNS:     in.DNS,
+		Cert:    in.Cert,
+		Chain:   in.Chain,
+	}
+}
+
 func rateLimitPolicy(in *contour_api_v1.RateLimitPolicy) (*RateLimitPolicy, error) {
 	if in == nil || (in.Local == nil && in.Global == nil) {
 		return nil, nil
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -191,6 +192,429 @@ func TestRetryPolicy(t *testing.T) {
 	}
 }
 
+func TestBufferPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.BufferPolicy
+		want    *BufferPolicy
+		wantErr bool
+	}{
+		"nil buffer policy": {
+			in:   nil,
+			want: nil,
+		},
+		"max request bytes": {
+			in: &contour_api_v1.BufferPolicy{
+				MaxRequestBytes: 1024,
+			},
+			want: &BufferPolicy{
+				MaxRequestBytes: 1024,
+			},
+		},
+		"zero max request bytes": {
+			in:      &contour_api_v1.BufferPolicy{},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := bufferPolicy(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRegexRewrite(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.RegexRewrite
+		want    *RegexRewrite
+		wantErr string
+	}{
+		"nil regex rewrite": {
+			in:   nil,
+			want: nil,
+		},
+		"capture groups": {
+			in: &contour_api_v1.RegexRewrite{
+				Pattern:      "^/service/([^/]+)(/.*)$",
+				Substitution: "\\2/instance/\\1",
+			},
+			want: &RegexRewrite{
+				Pattern:      "^/service/([^/]+)(/.*)$",
+				Substitution: "\\2/instance/\\1",
+			},
+		},
+		"whole match and literal backslash": {
+			in: &contour_api_v1.RegexRewrite{
+				Pattern:      "^/foo",
+				Substitution: "/bar\\\\\\0",
+			},
+			want: &RegexRewrite{
+				Pattern:      "^/foo",
+				Substitution: "/bar\\\\\\0",
+			},
+		},
+		"empty substitution": {
+			in: &contour_api_v1.RegexRewrite{
+				Pattern: "/v1",
+			},
+			want: &RegexRewrite{
+				Pattern: "/v1",
+			},
+		},
+		"missing pattern": {
+			in: &contour_api_v1.RegexRewrite{
+				Substitution: "/",
+			},
+			wantErr: "regexRewrite.pattern must be specified",
+		},
+		"invalid pattern": {
+			in: &contour_api_v1.RegexRewrite{
+				Pattern:      "^/(foo",
+				Substitution: "/",
+			},
+			wantErr: `invalid regexRewrite.pattern "^/(foo": error parsing regexp: missing closing ): ` + "`^/(foo`",
+		},
+		"substitution refers to missing group": {
+			in: &contour_api_v1.RegexRewrite{
+				Pattern:      "^/(foo)",
+				Substitution: "/\\2",
+			},
+			wantErr: `invalid regexRewrite.substitution "/\\2": refers to capture group 2 but the pattern has 1`,
+		},
+		"substitution has invalid escape": {
+			in: &contour_api_v1.RegexRewrite{
+				Pattern:      "^/foo",
+				Substitution: "/\\d",
+			},
+			wantErr: `invalid regexRewrite.substitution "/\\d": invalid escape sequence \d`,
+		},
+		"substitution has trailing backslash": {
+			in: &contour_api_v1.RegexRewrite{
+				Pattern:      "^/foo",
+				Substitution: "/\\",
+			},
+			wantErr: `invalid regexRewrite.substitution "/\\": trailing backslash`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := regexRewrite(tc.in)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFullPathRewrite(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		"empty": {
+			in:   "",
+			want: "",
+		},
+		"path": {
+			in:   "/v2/api",
+			want: "/v2/api",
+		},
+		"relative path": {
+			in:      "v2/api",
+			wantErr: "replaceFullPath must start with /, v2/api was supplied",
+		},
+		"refers to capture group": {
+			in:      "/v2/\\1",
+			wantErr: `invalid replaceFullPath "/v2/\\1": refers to capture group 1 but the pattern has 0`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := fullPathRewrite(tc.in)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAccessLogPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      []*contour_api_v1.AccessLogPolicy
+		want    *AccessLogPolicy
+		wantErr bool
+	}{
+		"nil access log policy": {
+			in:   []*contour_api_v1.AccessLogPolicy{nil},
+			want: nil,
+		},
+		"all fields": {
+			in: []*contour_api_v1.AccessLogPolicy{{
+				Format:     "json",
+				JSONFields: []string{"@timestamp", "method"},
+				Level:      "error",
+			}},
+			want: &AccessLogPolicy{
+				Format:     contour_api_v1alpha1.JSONAccessLog,
+				JSONFields: contour_api_v1alpha1.AccessLogJSONFields{"@timestamp", "method"},
+				Level:      contour_api_v1alpha1.LogLevelError,
+			},
+		},
+		"route overrides virtual host": {
+			in: []*contour_api_v1.AccessLogPolicy{{
+				Format: "json",
+				Level:  "error",
+			}, {
+				Level: "info",
+			}},
+			want: &AccessLogPolicy{
+				Format: contour_api_v1alpha1.JSONAccessLog,
+				Level:  contour_api_v1alpha1.LogLevelInfo,
+			},
+		},
+		"route without virtual host policy": {
+			in: []*contour_api_v1.AccessLogPolicy{nil, {
+				Level: "disabled",
+			}},
+			want: &AccessLogPolicy{
+				Level: contour_api_v1alpha1.LogLevelDisabled,
+			},
+		},
+		"invalid format": {
+			in: []*contour_api_v1.AccessLogPolicy{{
+				Format: "xml",
+			}},
+			wantErr: true,
+		},
+		"invalid format string": {
+			in: []*contour_api_v1.AccessLogPolicy{{
+				FormatString: "%START_TIME%",
+			}},
+			wantErr: true,
+		},
+		"invalid json field": {
+			in: []*contour_api_v1.AccessLogPolicy{{
+				JSONFields: []string{"nope"},
+			}},
+			wantErr: true,
+		},
+		"invalid level": {
+			in: []*contour_api_v1.AccessLogPolicy{{
+				Level: "debug",
+			}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := accessLogPolicy(tc.in...)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCertificateHashes(t *testing.T) {
+	const hash = "948fe603f61dc036b5c596dc09fe3ce3f3d30dc90f024c85f3c82db2ccab679d"
+
+	tests := map[string]struct {
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		"no hashes": {
+			in:   nil,
+			want: nil,
+		},
+		"hex": {
+			in:   []string{hash},
+			want: []string{hash},
+		},
+		"colon separated upper case": {
+			in:   []string{"94:8F:E6:03:F6:1D:C0:36:B5:C5:96:DC:09:FE:3C:E3:F3:D3:0D:C9:0F:02:4C:85:F3:C8:2D:B2:CC:AB:67:9D"},
+			want: []string{hash},
+		},
+		"not hex": {
+			in:      []string{"not-a-hash"},
+			wantErr: true,
+		},
+		"SHA-1 fingerprint": {
+			in:      []string{"da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := certificateHashes(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestClientCertificateAuthorization(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.ClientCertificateAuthorization
+		want    *ClientCertificateAuthorization
+		wantErr bool
+	}{
+		"nil authorization": {
+			in:   nil,
+			want: nil,
+		},
+		"principals": {
+			in: &contour_api_v1.ClientCertificateAuthorization{
+				Principals: []contour_api_v1.ClientCertificatePrincipal{
+					{Exact: "CN=client,O=example"},
+					{Prefix: "spiffe://cluster.local/"},
+					{Suffix: ".example.com"},
+					{Regex: "^client-[0-9]+$"},
+				},
+			},
+			want: &ClientCertificateAuthorization{
+				Principals: []ClientCertificatePrincipal{
+					{MatchType: StringMatchTypeExact, Value: "CN=client,O=example"},
+					{MatchType: StringMatchTypePrefix, Value: "spiffe://cluster.local/"},
+					{MatchType: StringMatchTypeSuffix, Value: ".example.com"},
+					{MatchType: StringMatchTypeRegex, Value: "^client-[0-9]+$"},
+				},
+			},
+		},
+		"no principals": {
+			in:      &contour_api_v1.ClientCertificateAuthorization{},
+			wantErr: true,
+		},
+		"principal with two matches": {
+			in: &contour_api_v1.ClientCertificateAuthorization{
+				Principals: []contour_api_v1.ClientCertificatePrincipal{
+					{Exact: "client", Prefix: "cl"},
+				},
+			},
+			wantErr: true,
+		},
+		"invalid regex": {
+			in: &contour_api_v1.ClientCertificateAuthorization{
+				Principals: []contour_api_v1.ClientCertificatePrincipal{
+					{Regex: "^client-[0-9+$"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := clientCertificateAuthorization(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestOutlierDetectionPolicy(t *testing.T) {
+	tests := map[string]struct {
+		od      *contour_api_v1.OutlierDetection
+		want    *OutlierDetectionPolicy
+		wantErr bool
+	}{
+		"nil outlier detection": {
+			od:   nil,
+			want: nil,
+		},
+		"empty outlier detection": {
+			od:   &contour_api_v1.OutlierDetection{},
+			want: &OutlierDetectionPolicy{},
+		},
+		"all fields": {
+			od: &contour_api_v1.OutlierDetection{
+				ConsecutiveServerErrors:  3,
+				ConsecutiveGatewayErrors: 2,
+				Interval:                 "5s",
+				BaseEjectionTime:         "1m",
+				MaxEjectionPercent:       50,
+			},
+			want: &OutlierDetectionPolicy{
+				ConsecutiveServerErrors:  3,
+				ConsecutiveGatewayErrors: 2,
+				Interval:                 5 * time.Second,
+				BaseEjectionTime:         time.Minute,
+				MaxEjectionPercent:       50,
+			},
+		},
+		"invalid interval": {
+			od: &contour_api_v1.OutlierDetection{
+				Interval: "peanut",
+			},
+			wantErr: true,
+		},
+		"invalid base ejection time": {
+			od: &contour_api_v1.OutlierDetection{
+				BaseEjectionTime: "peanut",
+			},
+			wantErr: true,
+		},
+		"zero interval": {
+			od: &contour_api_v1.OutlierDetection{
+				Interval: "0s",
+			},
+			wantErr: true,
+		},
+		"zero base ejection time": {
+			od: &contour_api_v1.OutlierDetection{
+				BaseEjectionTime: "0s",
+			},
+			wantErr: true,
+		},
+		"max ejection percent too large": {
+			od: &contour_api_v1.OutlierDetection{
+				MaxEjectionPercent: 101,
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := outlierDetectionPolicy(tc.od)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestTimeoutPolicy(t *testing.T) {
 	tests := map[string]struct {
 		tp                       *contour_api_v1.TimeoutPolicy
@@ -337,6 +761,47 @@ func TestLoadBalancerPolicy(t *testing.T) {
 	}
 }
 
+func TestLocalityLoadBalancerPolicy(t *testing.T) {
+	tests := map[string]struct {
+		llbp *contour_api_v1.LocalityLoadBalancerPolicy
+		want string
+	}{
+		"nil": {
+			llbp: nil,
+			want: "",
+		},
+		"empty": {
+			llbp: &contour_api_v1.LocalityLoadBalancerPolicy{},
+			want: "",
+		},
+		"LocalityWeighted": {
+			llbp: &contour_api_v1.LocalityLoadBalancerPolicy{
+				Strategy: "LocalityWeighted",
+			},
+			want: "LocalityWeighted",
+		},
+		"ZoneAware": {
+			llbp: &contour_api_v1.LocalityLoadBalancerPolicy{
+				Strategy: "ZoneAware",
+			},
+			want: "ZoneAware",
+		},
+		"unknown": {
+			llbp: &contour_api_v1.LocalityLoadBalancerPolicy{
+				Strategy: "please",
+			},
+			want: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := localityLoadBalancerPolicy(tc.llbp)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestHeadersPolicy(t *testing.T) {
 	tests := map[string]struct {
 		hp      *contour_api_v1.HeadersPolicy
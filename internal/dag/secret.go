@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"golang.org/x/crypto/ocsp"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -30,6 +31,10 @@ const CACertificateKey = "ca.crt"
 // CRLKey is the key name for accessing CRL bundles in Kubernetes Secrets.
 const CRLKey = "crl.pem"
 
+// OCSPStapleKey is the key name for accessing DER encoded OCSP responses
+// in Kubernetes TLS Secrets.
+const OCSPStapleKey = "ocsp.der"
+
 // validTLSSecret returns an error if the Secret is not of type TLS or if it doesn't contain certificate and private key material.
 func validTLSSecret(s *v1.Secret) error {
 	if s.Type != v1.SecretTypeTLS {
@@ -274,3 +279,53 @@ func validateCRL(data []byte) error {
 
 	return errors.New("failed to locate CRL")
 }
+
+// validateOCSPStaple validates that data is a DER encoded OCSP response
+// for the first certificate in the PEM bundle cert.
+func validateOCSPStaple(data []byte, cert []byte) error {
+	if len(data) == 0 {
+		return errors.New("can't use zero-length ocsp.der value")
+	}
+
+	resp, err := ocsp.ParseResponse(data, nil)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return errors.New("failed to locate certificate")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	if resp.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+		return errors.New("response is not for the TLS certificate")
+	}
+
+	return nil
+}
+
+// validateOCSPStapleStatus returns an error if the OCSP response in
+// data does not report the certificate as good. Unlike
+// validateOCSPStaple, this depends on the staple policy, since Envoy
+// skips such responses when stapling is lenient. Expiry is left to
+// Envoy, which checks it at handshake time, so that building the DAG
+// does not depend on the current time.
+func validateOCSPStapleStatus(data []byte) error {
+	resp, err := ocsp.ParseResponse(data, nil)
+	if err != nil {
+		return err
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return errors.New("certificate is revoked")
+	default:
+		return errors.New("certificate status is unknown")
+	}
+}
@@ -17,9 +17,11 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/projectcontour/contour/internal/fixture"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ocsp"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -181,6 +183,41 @@ func TestValidTLSSecret(t *testing.T) {
 			},
 			err: errors.New(`secret type is not "kubernetes.io/tls"`),
 		},
+		"TLS Secret, OCSP response": {
+			secret: &v1.Secret{
+				Type: v1.SecretTypeTLS,
+				Data: map[string][]byte{
+					v1.TLSCertKey:       []byte(fixture.CERTIFICATE),
+					v1.TLSPrivateKeyKey: []byte(fixture.RSA_PRIVATE_KEY),
+					OCSPStapleKey:       fixture.OCSPResponse(fixture.CERTIFICATE, ocsp.Good, time.Now().Add(time.Hour)),
+				},
+			},
+			err: nil,
+		},
+		// OCSP responses are checked against the staple policy of
+		// each virtual host, not when the Secret is loaded.
+		"TLS Secret, zero length OCSP response": {
+			secret: &v1.Secret{
+				Type: v1.SecretTypeTLS,
+				Data: map[string][]byte{
+					v1.TLSCertKey:       []byte(fixture.CERTIFICATE),
+					v1.TLSPrivateKeyKey: []byte(fixture.RSA_PRIVATE_KEY),
+					OCSPStapleKey:       []byte(""),
+				},
+			},
+			err: nil,
+		},
+		"TLS Secret, OCSP response for another certificate": {
+			secret: &v1.Secret{
+				Type: v1.SecretTypeTLS,
+				Data: map[string][]byte{
+					v1.TLSCertKey:       []byte(fixture.CERTIFICATE),
+					v1.TLSPrivateKeyKey: []byte(fixture.RSA_PRIVATE_KEY),
+					OCSPStapleKey:       fixture.OCSPResponse(fixture.CA_CERT, ocsp.Good, time.Now().Add(time.Hour)),
+				},
+			},
+			err: nil,
+		},
 		// Opaque Secret with TLS cert details won't be added.
 		"Opaque Secret, with TLS Cert and Key": {
 			secret: &v1.Secret{
@@ -206,6 +243,73 @@ func TestValidTLSSecret(t *testing.T) {
 	}
 }
 
+func TestValidateOCSPStapleStatus(t *testing.T) {
+	now := time.Now()
+
+	tests := map[string]struct {
+		data []byte
+		err  error
+	}{
+		"good": {
+			data: fixture.OCSPResponse(fixture.CERTIFICATE, ocsp.Good, now.Add(time.Hour)),
+		},
+		"revoked": {
+			data: fixture.OCSPResponse(fixture.CERTIFICATE, ocsp.Revoked, now.Add(time.Hour)),
+			err:  errors.New("certificate is revoked"),
+		},
+		"unknown": {
+			data: fixture.OCSPResponse(fixture.CERTIFICATE, ocsp.Unknown, now.Add(time.Hour)),
+			err:  errors.New("certificate status is unknown"),
+		},
+		"expired": {
+			data: fixture.OCSPResponse(fixture.CERTIFICATE, ocsp.Good, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.err, validateOCSPStapleStatus(tc.data))
+		})
+	}
+}
+
+func TestSecretOCSPStaple(t *testing.T) {
+	staple := fixture.OCSPResponse(fixture.CERTIFICATE, ocsp.Good, time.Now().Add(time.Hour))
+
+	tests := map[string]struct {
+		data map[string][]byte
+		want []byte
+	}{
+		"no OCSP response": {
+			data: map[string][]byte{
+				v1.TLSCertKey: []byte(fixture.CERTIFICATE),
+			},
+			want: nil,
+		},
+		"OCSP response": {
+			data: map[string][]byte{
+				v1.TLSCertKey: []byte(fixture.CERTIFICATE),
+				OCSPStapleKey: staple,
+			},
+			want: staple,
+		},
+		"OCSP response for another certificate": {
+			data: map[string][]byte{
+				v1.TLSCertKey: []byte(fixture.CERTIFICATE),
+				OCSPStapleKey: fixture.OCSPResponse(fixture.CA_CERT, ocsp.Good, time.Now().Add(time.Hour)),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Object: &v1.Secret{Type: v1.SecretTypeTLS, Data: tc.data}}
+			assert.Equal(t, tc.want, s.OCSPStaple())
+		})
+	}
+}
+
 func TestIsValidSecret(t *testing.T) {
 	tests := map[string]struct {
 		secret *v1.Secret
@@ -15,6 +15,7 @@ package dag
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -23,6 +24,7 @@ import (
 	"github.com/projectcontour/contour/internal/gatewayapi"
 	"github.com/projectcontour/contour/internal/status"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ocsp"
 	v1 "k8s.io/api/core/v1"
 	networking_v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -489,6 +491,68 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	proxyAutoHostRewriteWithHostHeader := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				AutoHostRewrite: true,
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "Host",
+						Value: "external.address",
+					}},
+				},
+			}},
+		},
+	}
+
+	run(t, "route autoHostRewrite cannot be combined with a Host header", testcase{
+		objs: []interface{}{proxyAutoHostRewriteWithHostHeader, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyAutoHostRewriteWithHostHeader.Name, Namespace: proxyAutoHostRewriteWithHostHeader.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "AutoHostRewriteNotValid",
+					"route.autoHostRewrite cannot be combined with a Host header in route.requestHeadersPolicy"),
+		},
+	})
+
+	proxyAutoHostRewriteWithClusterIPService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				AutoHostRewrite: true,
+			}},
+		},
+	}
+
+	run(t, "route autoHostRewrite requires ExternalName services", testcase{
+		objs: []interface{}{proxyAutoHostRewriteWithClusterIPService, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyAutoHostRewriteWithClusterIPService.Name, Namespace: proxyAutoHostRewriteWithClusterIPService.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "AutoHostRewriteNotValid",
+					`service "kuard": route.autoHostRewrite requires services of type ExternalName`),
+		},
+	})
+
 	// proxyInvalidNegativePortHomeService is invalid because it contains a service with negative port
 	proxyInvalidNegativePortHomeService := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -2135,6 +2199,69 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	revokedOCSPSecret := fixture.SecretRootsCert.DeepCopy()
+	revokedOCSPSecret.Data[OCSPStapleKey] = fixture.OCSPResponse(fixture.CERTIFICATE, ocsp.Revoked, time.Now().Add(time.Hour))
+
+	strictStaplingRevoked := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:       "ssl-cert",
+					OCSPStaplePolicy: contour_api_v1.OCSPStaplePolicyStrict,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "strict stapling with a revoked OCSP response", testcase{
+		objs: []interface{}{strictStaplingRevoked, revokedOCSPSecret, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: strictStaplingRevoked.Name, Namespace: strictStaplingRevoked.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotValid",
+					`Spec.VirtualHost.TLS Secret "ssl-cert" has an OCSP response that can't be used with ocspStaplePolicy StrictStapling: certificate is revoked`),
+		},
+	})
+
+	mismatchedOCSPSecret := fixture.SecretRootsCert.DeepCopy()
+	mismatchedOCSPSecret.Data[OCSPStapleKey] = fixture.OCSPResponse(fixture.CA_CERT, ocsp.Good, time.Now().Add(time.Hour))
+
+	lenientStaplingMismatched := strictStaplingRevoked.DeepCopy()
+	lenientStaplingMismatched.Spec.VirtualHost.TLS.OCSPStaplePolicy = contour_api_v1.OCSPStaplePolicyLenient
+
+	// The mismatched OCSP response is dropped, but the HTTPProxy is valid.
+	lenientStaplingMismatchedCondition := fixture.NewValidCondition().Valid()
+	lenientStaplingMismatchedCondition.AddWarning(contour_api_v1.ConditionTypeTLSError, "OCSPStapleIgnored",
+		`Spec.VirtualHost.TLS Secret "ssl-cert" has an OCSP response that is not stapled: response is not for the TLS certificate`)
+
+	run(t, "lenient stapling with an OCSP response for another certificate", testcase{
+		objs: []interface{}{lenientStaplingMismatched, mismatchedOCSPSecret, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: lenientStaplingMismatched.Name, Namespace: lenientStaplingMismatched.Namespace}: lenientStaplingMismatchedCondition,
+		},
+	})
+
+	strictStaplingMismatched := strictStaplingRevoked.DeepCopy()
+
+	run(t, "strict stapling with an OCSP response for another certificate", testcase{
+		objs: []interface{}{strictStaplingMismatched, mismatchedOCSPSecret, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: strictStaplingMismatched.Name, Namespace: strictStaplingMismatched.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotValid",
+					`Spec.VirtualHost.TLS Secret "ssl-cert" has an OCSP response that can't be used with ocspStaplePolicy StrictStapling: response is not for the TLS certificate`),
+		},
+	})
+
 	tlsPassthroughAndSecretName := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "invalid",
@@ -2389,6 +2516,68 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	invalidOutlierDetectionService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalidODService",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+						OutlierDetection: &contour_api_v1.OutlierDetection{
+							BaseEjectionTime: "forever",
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	run(t, "outlierDetection, invalid base ejection time", testcase{
+		objs: []interface{}{invalidOutlierDetectionService, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidOutlierDetectionService.Name, Namespace: invalidOutlierDetectionService.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "OutlierDetectionInvalid", `service "kuard": outlierDetection is invalid: invalid baseEjectionTime: time: invalid duration "forever"`),
+		},
+	})
+
+	zeroIntervalOutlierDetectionService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "zeroIntervalODService",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+						OutlierDetection: &contour_api_v1.OutlierDetection{
+							Interval: "0s",
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	run(t, "outlierDetection, zero interval", testcase{
+		objs: []interface{}{zeroIntervalOutlierDetectionService, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: zeroIntervalOutlierDetectionService.Name, Namespace: zeroIntervalOutlierDetectionService.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "OutlierDetectionInvalid", `service "kuard": outlierDetection is invalid: interval "0s" must be greater than zero`),
+		},
+	})
+
 	emptyCookieRewritePolicyRoute := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "invalidCRPRoute",
@@ -5245,6 +5434,64 @@ func TestGatewayAPIHTTPRouteDAGStatus(t *testing.T) {
 		wantGatewayStatusUpdate: validGatewayStatusUpdate("http", "HTTPRoute", 1),
 	})
 
+	run(t, "Invalid URLRewrite due to missing path replacement", testcase{
+		objs: []interface{}{
+			kuardService,
+			&gatewayapi_v1beta1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "basic",
+					Namespace: "default",
+					Labels: map[string]string{
+						"app": "contour",
+					},
+				},
+				Spec: gatewayapi_v1beta1.HTTPRouteSpec{
+					CommonRouteSpec: gatewayapi_v1beta1.CommonRouteSpec{
+						ParentRefs: []gatewayapi_v1beta1.ParentReference{gatewayapi.GatewayParentRef("projectcontour", "contour")},
+					},
+					Hostnames: []gatewayapi_v1beta1.Hostname{
+						"test.projectcontour.io",
+					},
+					Rules: []gatewayapi_v1beta1.HTTPRouteRule{{
+						Matches:     gatewayapi.HTTPRouteMatch(gatewayapi_v1beta1.PathMatchPathPrefix, "/"),
+						BackendRefs: gatewayapi.HTTPBackendRef("kuard", 8080, 1),
+						Filters: []gatewayapi_v1beta1.HTTPRouteFilter{{
+							Type: gatewayapi_v1beta1.HTTPRouteFilterURLRewrite,
+							URLRewrite: &gatewayapi_v1beta1.HTTPURLRewriteFilter{
+								Path: &gatewayapi_v1beta1.HTTPPathModifier{
+									Type: gatewayapi_v1beta1.PrefixMatchHTTPPathModifier,
+								},
+							},
+						}},
+					}},
+				},
+			}},
+		wantRouteConditions: []*status.RouteStatusUpdate{{
+			FullName: types.NamespacedName{Namespace: "default", Name: "basic"},
+			RouteParentStatuses: []*gatewayapi_v1beta1.RouteParentStatus{
+				{
+					ParentRef: gatewayapi.GatewayParentRef("projectcontour", "contour"),
+					Conditions: []metav1.Condition{
+						{
+							Type:    string(status.ConditionNotImplemented),
+							Status:  contour_api_v1.ConditionTrue,
+							Reason:  string(status.ReasonHTTPRouteFilterType),
+							Message: "HTTPRoute.Spec.Rules.Filters.URLRewrite.Path.ReplacePrefixMatch must be specified",
+						},
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionAccepted),
+							Status:  contour_api_v1.ConditionTrue,
+							Reason:  string(gatewayapi_v1beta1.RouteReasonAccepted),
+							Message: "Accepted HTTPRoute",
+						},
+					},
+				},
+			},
+		}},
+		// The rule with the invalid rewrite is not programmed.
+		wantGatewayStatusUpdate: validGatewayStatusUpdate("http", "HTTPRoute", 0),
+	})
+
 	run(t, "Invalid RequestHeaderModifier after forward due to invalid headers", testcase{
 		objs: []interface{}{
 			kuardService,
@@ -5471,7 +5718,7 @@ func TestGatewayAPIHTTPRouteDAGStatus(t *testing.T) {
 							Type:    string(gatewayapi_v1beta1.ListenerConditionResolvedRefs),
 							Status:  metav1.ConditionFalse,
 							Reason:  string(gatewayapi_v1beta1.ListenerReasonInvalidRouteKinds),
-							Message: "Kind \"FooRoute\" is not supported, kind must be \"HTTPRoute\" or \"TLSRoute\"",
+							Message: "Kind \"FooRoute\" is not supported, kind must be \"HTTPRoute\", \"TLSRoute\" or \"TCPRoute\"",
 						},
 					},
 				},
@@ -5708,7 +5955,90 @@ func TestGatewayAPIHTTPRouteDAGStatus(t *testing.T) {
 							Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
 							Status:  metav1.ConditionTrue,
 							Reason:  string(gatewayapi_v1beta1.ListenerReasonUnsupportedProtocol),
-							Message: "Listener protocol \"invalid\" is unsupported, must be one of HTTP, HTTPS or TLS",
+							Message: "Listener protocol \"invalid\" is unsupported, must be one of HTTP, HTTPS, TLS or TCP",
+						},
+					},
+				},
+			},
+		}},
+	})
+
+	run(t, "listeners on the same port with incompatible protocols results in a listener condition", testcase{
+		objs: []interface{}{},
+		gateway: &gatewayapi_v1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "contour",
+				Namespace: "projectcontour",
+			},
+			Spec: gatewayapi_v1beta1.GatewaySpec{
+				Listeners: []gatewayapi_v1beta1.Listener{
+					{
+						Name:     "http",
+						Port:     8081,
+						Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+						AllowedRoutes: &gatewayapi_v1beta1.AllowedRoutes{
+							Namespaces: &gatewayapi_v1beta1.RouteNamespaces{
+								From: gatewayapi.FromNamespacesPtr(gatewayapi_v1beta1.NamespacesFromAll),
+							},
+						},
+					},
+					{
+						Name:     "tcp",
+						Port:     8081,
+						Protocol: gatewayapi_v1beta1.TCPProtocolType,
+						AllowedRoutes: &gatewayapi_v1beta1.AllowedRoutes{
+							Namespaces: &gatewayapi_v1beta1.RouteNamespaces{
+								From: gatewayapi.FromNamespacesPtr(gatewayapi_v1beta1.NamespacesFromAll),
+							},
+						},
+					},
+				},
+			},
+		},
+		wantGatewayStatusUpdate: []*status.GatewayStatusUpdate{{
+			FullName: types.NamespacedName{Namespace: "projectcontour", Name: "contour"},
+			Conditions: map[gatewayapi_v1beta1.GatewayConditionType]metav1.Condition{
+				gatewayapi_v1beta1.GatewayConditionScheduled: gatewayScheduledCondition(),
+				gatewayapi_v1beta1.GatewayConditionReady: {
+					Type:    string(gatewayapi_v1beta1.GatewayConditionReady),
+					Status:  contour_api_v1.ConditionFalse,
+					Reason:  string(gatewayapi_v1beta1.GatewayReasonListenersNotValid),
+					Message: "Listeners are not valid",
+				},
+			},
+			ListenerStatus: map[string]*gatewayapi_v1beta1.ListenerStatus{
+				"http": {
+					Name: "http",
+					SupportedKinds: []gatewayapi_v1beta1.RouteGroupKind{
+						{
+							Group: gatewayapi.GroupPtr(gatewayapi_v1beta1.GroupName),
+							Kind:  "HTTPRoute",
+						},
+					},
+					Conditions: []metav1.Condition{
+						{
+							Type:    "Ready",
+							Status:  metav1.ConditionTrue,
+							Reason:  "Ready",
+							Message: "Valid listener",
+						},
+					},
+				},
+				"tcp": {
+					Name:           "tcp",
+					SupportedKinds: nil,
+					Conditions: []metav1.Condition{
+						{
+							Type:    "Ready",
+							Status:  metav1.ConditionFalse,
+							Reason:  "Invalid",
+							Message: "Invalid listener, see other listener conditions for details",
+						},
+						{
+							Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
+							Status:  metav1.ConditionTrue,
+							Reason:  string(gatewayapi_v1beta1.ListenerReasonProtocolConflict),
+							Message: "All listeners for a given port must use a compatible protocol",
 						},
 					},
 				},
@@ -6680,6 +7010,332 @@ func TestGatewayAPITLSRouteDAGStatus(t *testing.T) {
 	})
 }
 
+func TestGatewayAPITCPRouteDAGStatus(t *testing.T) {
+
+	type testcase struct {
+		objs                    []interface{}
+		gateway                 *gatewayapi_v1beta1.Gateway
+		wantRouteConditions     []*status.RouteStatusUpdate
+		wantGatewayStatusUpdate []*status.GatewayStatusUpdate
+	}
+
+	run := func(t *testing.T, desc string, tc testcase) {
+		t.Helper()
+		t.Run(desc, func(t *testing.T) {
+			t.Helper()
+			builder := Builder{
+				Source: KubernetesCache{
+					FieldLogger: fixture.NewTestLogger(t),
+					gateway:     tc.gateway,
+					gatewayclass: &gatewayapi_v1beta1.GatewayClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test-gc",
+						},
+						Spec: gatewayapi_v1beta1.GatewayClassSpec{
+							ControllerName: "projectcontour.io/contour",
+						},
+						Status: gatewayapi_v1beta1.GatewayClassStatus{
+							Conditions: []metav1.Condition{
+								{
+									Type:   string(gatewayapi_v1beta1.GatewayClassConditionStatusAccepted),
+									Status: metav1.ConditionTrue,
+								},
+							},
+						},
+					},
+				},
+				Processors: []Processor{
+					&GatewayAPIProcessor{
+						FieldLogger: fixture.NewTestLogger(t),
+					},
+					&ListenerProcessor{},
+				},
+			}
+
+			for _, o := range tc.objs {
+				builder.Source.Insert(o)
+			}
+			dag := builder.Build()
+			gotRouteUpdates := dag.StatusCache.GetRouteUpdates()
+			gotGatewayUpdates := dag.StatusCache.GetGatewayUpdates()
+
+			ops := []cmp.Option{
+				cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"),
+				cmpopts.IgnoreFields(status.RouteStatusUpdate{}, "GatewayRef"),
+				cmpopts.IgnoreFields(status.RouteStatusUpdate{}, "Generation"),
+				cmpopts.IgnoreFields(status.RouteStatusUpdate{}, "TransitionTime"),
+				cmpopts.IgnoreFields(status.RouteStatusUpdate{}, "Resource"),
+				cmpopts.IgnoreFields(status.GatewayStatusUpdate{}, "ExistingConditions"),
+				cmpopts.IgnoreFields(status.GatewayStatusUpdate{}, "Generation"),
+				cmpopts.IgnoreFields(status.GatewayStatusUpdate{}, "TransitionTime"),
+				cmpopts.SortSlices(func(i, j metav1.Condition) bool {
+					return i.Message < j.Message
+				}),
+				cmpopts.SortSlices(func(i, j *status.RouteStatusUpdate) bool {
+					return i.FullName.String() < j.FullName.String()
+				}),
+			}
+
+			for _, u := range tc.wantRouteConditions {
+				u.GatewayController = builder.Source.gatewayclass.Spec.ControllerName
+
+				for _, rps := range u.RouteParentStatuses {
+					rps.ControllerName = builder.Source.gatewayclass.Spec.ControllerName
+				}
+			}
+
+			if diff := cmp.Diff(tc.wantRouteConditions, gotRouteUpdates, ops...); diff != "" {
+				t.Fatalf("expected route status: %v, got %v", tc.wantRouteConditions, diff)
+			}
+
+			if diff := cmp.Diff(tc.wantGatewayStatusUpdate, gotGatewayUpdates, ops...); diff != "" {
+				t.Fatalf("expected gateway status: %v, got %v", tc.wantGatewayStatusUpdate, diff)
+			}
+		})
+	}
+
+	gw := &gatewayapi_v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "contour",
+			Namespace: "projectcontour",
+		},
+		Spec: gatewayapi_v1beta1.GatewaySpec{
+			Listeners: []gatewayapi_v1beta1.Listener{{
+				Name:     "tcp",
+				Port:     9000,
+				Protocol: gatewayapi_v1beta1.TCPProtocolType,
+				AllowedRoutes: &gatewayapi_v1beta1.AllowedRoutes{
+					Namespaces: &gatewayapi_v1beta1.RouteNamespaces{
+						From: gatewayapi.FromNamespacesPtr(gatewayapi_v1beta1.NamespacesFromAll),
+					},
+				},
+			}},
+		},
+	}
+
+	kuardService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:       "http",
+				Protocol:   "TCP",
+				Port:       8080,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+
+	run(t, "TCPRoute: valid route attaches to the listener", testcase{
+		gateway: gw,
+		objs: []interface{}{
+			kuardService,
+			&gatewayapi_v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "basic",
+					Namespace: "default",
+				},
+				Spec: gatewayapi_v1alpha2.TCPRouteSpec{
+					CommonRouteSpec: gatewayapi_v1alpha2.CommonRouteSpec{
+						ParentRefs: []gatewayapi_v1alpha2.ParentReference{
+							gatewayapi.GatewayParentRefV1Alpha2("projectcontour", "contour"),
+						},
+					},
+					Rules: []gatewayapi_v1alpha2.TCPRouteRule{{
+						BackendRefs: gatewayapi.TLSRouteBackendRef("kuard", 8080, nil),
+					}},
+				},
+			}},
+		wantRouteConditions: []*status.RouteStatusUpdate{{
+			FullName: types.NamespacedName{Namespace: "default", Name: "basic"},
+			RouteParentStatuses: []*gatewayapi_v1beta1.RouteParentStatus{
+				{
+					ParentRef: gatewayapi.GatewayParentRef("projectcontour", "contour"),
+					Conditions: []metav1.Condition{
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionResolvedRefs),
+							Status:  contour_api_v1.ConditionTrue,
+							Reason:  string(gatewayapi_v1beta1.RouteReasonResolvedRefs),
+							Message: "References resolved",
+						},
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionAccepted),
+							Status:  contour_api_v1.ConditionTrue,
+							Reason:  string(gatewayapi_v1beta1.RouteReasonAccepted),
+							Message: "Accepted TCPRoute",
+						},
+					},
+				},
+			},
+		}},
+		wantGatewayStatusUpdate: validGatewayStatusUpdate(string(gw.Spec.Listeners[0].Name), "TCPRoute", 1),
+	})
+
+	run(t, "TCPRoute: spec.rules.backendRef.name not found", testcase{
+		gateway: gw,
+		objs: []interface{}{
+			&gatewayapi_v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "basic",
+					Namespace: "default",
+				},
+				Spec: gatewayapi_v1alpha2.TCPRouteSpec{
+					CommonRouteSpec: gatewayapi_v1alpha2.CommonRouteSpec{
+						ParentRefs: []gatewayapi_v1alpha2.ParentReference{
+							gatewayapi.GatewayParentRefV1Alpha2("projectcontour", "contour"),
+						},
+					},
+					Rules: []gatewayapi_v1alpha2.TCPRouteRule{{
+						BackendRefs: gatewayapi.TLSRouteBackendRef("invalid", 8080, nil),
+					}},
+				},
+			}},
+		wantRouteConditions: []*status.RouteStatusUpdate{{
+			FullName: types.NamespacedName{Namespace: "default", Name: "basic"},
+			RouteParentStatuses: []*gatewayapi_v1beta1.RouteParentStatus{
+				{
+					ParentRef: gatewayapi.GatewayParentRef("projectcontour", "contour"),
+					Conditions: []metav1.Condition{
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionResolvedRefs),
+							Status:  contour_api_v1.ConditionFalse,
+							Reason:  string(gatewayapi_v1beta1.RouteReasonBackendNotFound),
+							Message: "service \"invalid\" is invalid: service \"default/invalid\" not found",
+						},
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionAccepted),
+							Status:  contour_api_v1.ConditionFalse,
+							Reason:  string(status.ReasonErrorsExist),
+							Message: "TCPRoute has no valid backends.",
+						},
+					},
+				},
+			},
+		}},
+		wantGatewayStatusUpdate: validGatewayStatusUpdate(string(gw.Spec.Listeners[0].Name), "TCPRoute", 0),
+	})
+
+	run(t, "TCPRoute: newer route for the same listener is not accepted", testcase{
+		gateway: gw,
+		objs: []interface{}{
+			kuardService,
+			&gatewayapi_v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "newer",
+					Namespace:         "default",
+					CreationTimestamp: metav1.Date(2022, time.June, 2, 0, 0, 0, 0, time.UTC),
+				},
+				Spec: gatewayapi_v1alpha2.TCPRouteSpec{
+					CommonRouteSpec: gatewayapi_v1alpha2.CommonRouteSpec{
+						ParentRefs: []gatewayapi_v1alpha2.ParentReference{
+							gatewayapi.GatewayParentRefV1Alpha2("projectcontour", "contour"),
+						},
+					},
+					Rules: []gatewayapi_v1alpha2.TCPRouteRule{{
+						BackendRefs: gatewayapi.TLSRouteBackendRef("kuard", 8080, nil),
+					}},
+				},
+			},
+			&gatewayapi_v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "older",
+					Namespace:         "default",
+					CreationTimestamp: metav1.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC),
+				},
+				Spec: gatewayapi_v1alpha2.TCPRouteSpec{
+					CommonRouteSpec: gatewayapi_v1alpha2.CommonRouteSpec{
+						ParentRefs: []gatewayapi_v1alpha2.ParentReference{
+							gatewayapi.GatewayParentRefV1Alpha2("projectcontour", "contour"),
+						},
+					},
+					Rules: []gatewayapi_v1alpha2.TCPRouteRule{{
+						BackendRefs: gatewayapi.TLSRouteBackendRef("kuard", 8080, nil),
+					}},
+				},
+			}},
+		wantRouteConditions: []*status.RouteStatusUpdate{{
+			FullName: types.NamespacedName{Namespace: "default", Name: "older"},
+			RouteParentStatuses: []*gatewayapi_v1beta1.RouteParentStatus{
+				{
+					ParentRef: gatewayapi.GatewayParentRef("projectcontour", "contour"),
+					Conditions: []metav1.Condition{
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionResolvedRefs),
+							Status:  contour_api_v1.ConditionTrue,
+							Reason:  string(gatewayapi_v1beta1.RouteReasonResolvedRefs),
+							Message: "References resolved",
+						},
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionAccepted),
+							Status:  contour_api_v1.ConditionTrue,
+							Reason:  string(gatewayapi_v1beta1.RouteReasonAccepted),
+							Message: "Accepted TCPRoute",
+						},
+					},
+				},
+			},
+		}, {
+			FullName: types.NamespacedName{Namespace: "default", Name: "newer"},
+			RouteParentStatuses: []*gatewayapi_v1beta1.RouteParentStatus{
+				{
+					ParentRef: gatewayapi.GatewayParentRef("projectcontour", "contour"),
+					Conditions: []metav1.Condition{
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionAccepted),
+							Status:  contour_api_v1.ConditionFalse,
+							Reason:  string(gatewayapi_v1beta1.RouteReasonNotAllowedByListeners),
+							Message: "No listener is available, each already has a TCPRoute attached.",
+						},
+					},
+				},
+			},
+		}},
+		wantGatewayStatusUpdate: validGatewayStatusUpdate(string(gw.Spec.Listeners[0].Name), "TCPRoute", 1),
+	})
+
+	run(t, "TCPRoute: more than one rule is not accepted", testcase{
+		gateway: gw,
+		objs: []interface{}{
+			kuardService,
+			&gatewayapi_v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "basic",
+					Namespace: "default",
+				},
+				Spec: gatewayapi_v1alpha2.TCPRouteSpec{
+					CommonRouteSpec: gatewayapi_v1alpha2.CommonRouteSpec{
+						ParentRefs: []gatewayapi_v1alpha2.ParentReference{
+							gatewayapi.GatewayParentRefV1Alpha2("projectcontour", "contour"),
+						},
+					},
+					Rules: []gatewayapi_v1alpha2.TCPRouteRule{
+						{BackendRefs: gatewayapi.TLSRouteBackendRef("kuard", 8080, nil)},
+						{BackendRefs: gatewayapi.TLSRouteBackendRef("kuard", 8080, nil)},
+					},
+				},
+			}},
+		wantRouteConditions: []*status.RouteStatusUpdate{{
+			FullName: types.NamespacedName{Namespace: "default", Name: "basic"},
+			RouteParentStatuses: []*gatewayapi_v1beta1.RouteParentStatus{
+				{
+					ParentRef: gatewayapi.GatewayParentRef("projectcontour", "contour"),
+					Conditions: []metav1.Condition{
+						{
+							Type:    string(gatewayapi_v1beta1.RouteConditionAccepted),
+							Status:  contour_api_v1.ConditionFalse,
+							Reason:  string(gatewayapi_v1beta1.RouteReasonUnsupportedValue),
+							Message: "TCPRoute must have only a single rule defined.",
+						},
+					},
+				},
+			},
+		}},
+		wantGatewayStatusUpdate: validGatewayStatusUpdate(string(gw.Spec.Listeners[0].Name), "TCPRoute", 0),
+	})
+}
+
 func gatewayScheduledCondition() metav1.Condition {
 	return metav1.Condition{
 		Type:    string(gatewayapi_v1beta1.GatewayConditionScheduled),
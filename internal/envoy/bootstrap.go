@@ -36,6 +36,10 @@ const SDSTLSCertificateFile = "xds-tls-certificate.json"
 // CA certificates for Envoy to use for the XDS gRPC connection.
 const SDSValidationContextFile = "xds-validation-context.json"
 
+// LocalClusterName is the name of the cluster holding the Envoy pods
+// themselves, which Envoy needs for zone aware routing.
+const LocalClusterName = "envoy-local"
+
 // BootstrapConfig holds configuration values for a Bootstrap configuration.
 type BootstrapConfig struct {
 	// AdminAccessLogPath is the path to write the access log for the administration server.
@@ -91,6 +95,11 @@ type BootstrapConfig struct {
 	// Either v4, v6 or auto.
 	DNSLookupFamily string
 
+	// Zone is the topology zone that Envoy runs in. When set, Envoy
+	// is configured with its locality and a local cluster so that
+	// zone aware routing can be used.
+	Zone string
+
 	// MaximumHeapSizeBytes specifies the number of bytes that overload manager allows heap to grow to.
 	// When reaching the set threshold, new connections are denied.
 	MaximumHeapSizeBytes uint64
@@ -31,6 +31,7 @@ import (
 func Clustername(cluster *dag.Cluster) string {
 	service := cluster.Upstream
 	buf := cluster.LoadBalancerPolicy
+	buf += cluster.LocalityLoadBalancerPolicy
 	if hc := cluster.HTTPHealthCheckPolicy; hc != nil {
 		if hc.Timeout > 0 {
 			buf += hc.Timeout.String()
@@ -46,9 +47,23 @@ func Clustername(cluster *dag.Cluster) string {
 		}
 		buf += hc.Path
 	}
+	if od := cluster.OutlierDetectionPolicy; od != nil {
+		buf += fmt.Sprintf("%d%d%s%s%d", od.ConsecutiveServerErrors, od.ConsecutiveGatewayErrors,
+			od.Interval, od.BaseEjectionTime, od.MaxEjectionPercent)
+	}
 	if uv := cluster.UpstreamValidation; uv != nil {
 		buf += uv.CACertificate.Object.ObjectMeta.Name
 		buf += uv.SubjectName
+		buf += string(uv.SubjectNameType)
+		for _, san := range uv.SubjectAltNames {
+			buf += string(san.Type) + san.MatchType + san.Value
+		}
+	}
+	if cc := cluster.ClientCertificate; cc != nil {
+		buf += cc.Object.ObjectMeta.Namespace + cc.Object.ObjectMeta.Name
+	}
+	if sds := cluster.ClientCertificateSDS; sds != nil {
+		buf += sds.Name
 	}
 	buf += cluster.Protocol + cluster.SNI
 	if !cluster.TimeoutPolicy.IdleConnectionTimeout.UseDefault() {
@@ -14,16 +14,54 @@
 package v3
 
 import (
+	"crypto/sha1" // nolint:gosec
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
 	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_config_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_file_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
+	envoy_grpc_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/grpc/v3"
+	envoy_header_to_metadata_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/header_to_metadata/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_req_without_query_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/formatter/req_without_query/v3"
+	envoy_matcher_v3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_tracing_v3 "github.com/envoyproxy/go-control-plane/envoy/type/tracing/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes/any"
 	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// TCPGRPCAccessLog is the name of the TCP gRPC access log sink.
+const TCPGRPCAccessLog = "envoy.access_loggers.tcp_grpc"
+
+const (
+	// headerToMetadataFilterName is the name of the HTTP filter
+	// used to record the access log policy of a request.
+	headerToMetadataFilterName = "envoy.filters.http.header_to_metadata"
+
+	// accessLogPolicyMetadataKey is the dynamic metadata key
+	// holding the name of the access log policy of a request.
+	accessLogPolicyMetadataKey = "access_log_policy"
+)
+
+// GrpcAccessLogConfig stores configuration for sending
+// access logs to a gRPC access log service.
+type GrpcAccessLogConfig struct {
+	ExtensionService types.NamespacedName
+	SNI              string
+	Timeout          timeout.Setting
+	LogName          string
+}
+
 // FileAccessLogEnvoy returns a new file based access log filter
 func FileAccessLogEnvoy(path string, format string, extensions []string, level contour_api_v1alpha1.AccessLogLevel) []*envoy_accesslog_v3.AccessLog {
 	if level == contour_api_v1alpha1.LogLevelDisabled {
@@ -104,6 +142,131 @@ func FileAccessLogJSON(path string, fields contour_api_v1alpha1.AccessLogJSONFie
 	}}
 }
 
+// HTTPGrpcAccessLog returns a new access log filter that sends HTTP
+// access logs to a gRPC access log service. Request header, response
+// header, response trailer, environment and gRPC status operators in
+// fields are logged in addition to the standard access log entry.
+// Configuration validation rejects any other fields whose value is not
+// part of the standard entry.
+func HTTPGrpcAccessLog(config *GrpcAccessLogConfig, fields contour_api_v1alpha1.AccessLogJSONFields, level contour_api_v1alpha1.AccessLogLevel) []*envoy_accesslog_v3.AccessLog {
+	if config == nil || level == contour_api_v1alpha1.LogLevelDisabled {
+		return nil
+	}
+
+	var filter *envoy_accesslog_v3.AccessLogFilter
+	if level == contour_api_v1alpha1.LogLevelError {
+		filter = filterOnlyErrors()
+	}
+
+	common := commonGrpcAccessLogConfig(config)
+	httpConfig := &envoy_grpc_v3.HttpGrpcAccessLogConfig{
+		CommonConfig: common,
+	}
+
+	fieldMap := fields.AsFieldMap()
+	names := make([]string, 0, len(fieldMap))
+	for name := range fieldMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		operator, arg := commandOperator(fieldMap[name])
+		switch operator {
+		case "REQ":
+			common.CustomTags = append(common.CustomTags, &envoy_tracing_v3.CustomTag{
+				Tag: name,
+				Type: &envoy_tracing_v3.CustomTag_RequestHeader{
+					RequestHeader: &envoy_tracing_v3.CustomTag_Header{
+						Name: strings.ToLower(arg),
+					},
+				},
+			})
+		case "ENVIRONMENT":
+			common.CustomTags = append(common.CustomTags, &envoy_tracing_v3.CustomTag{
+				Tag: name,
+				Type: &envoy_tracing_v3.CustomTag_Environment_{
+					Environment: &envoy_tracing_v3.CustomTag_Environment{
+						Name: arg,
+					},
+				},
+			})
+		case "RESP":
+			httpConfig.AdditionalResponseHeadersToLog = appendUnique(httpConfig.AdditionalResponseHeadersToLog, strings.ToLower(arg))
+		case "TRAILER":
+			httpConfig.AdditionalResponseTrailersToLog = appendUnique(httpConfig.AdditionalResponseTrailersToLog, strings.ToLower(arg))
+		case "GRPC_STATUS", "GRPC_STATUS_NUMBER":
+			// The status is sent in the trailers, or in the headers
+			// of trailers-only responses.
+			httpConfig.AdditionalResponseHeadersToLog = appendUnique(httpConfig.AdditionalResponseHeadersToLog, "grpc-status")
+			httpConfig.AdditionalResponseTrailersToLog = appendUnique(httpConfig.AdditionalResponseTrailersToLog, "grpc-status")
+		}
+	}
+
+	return []*envoy_accesslog_v3.AccessLog{{
+		Name: wellknown.HTTPGRPCAccessLog,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(httpConfig),
+		},
+		Filter: filter,
+	}}
+}
+
+// TCPGrpcAccessLog returns a new access log filter that sends TCP
+// access logs to a gRPC access log service.
+func TCPGrpcAccessLog(config *GrpcAccessLogConfig, level contour_api_v1alpha1.AccessLogLevel) []*envoy_accesslog_v3.AccessLog {
+	if config == nil || level == contour_api_v1alpha1.LogLevelDisabled {
+		return nil
+	}
+
+	var filter *envoy_accesslog_v3.AccessLogFilter
+	if level == contour_api_v1alpha1.LogLevelError {
+		filter = filterOnlyErrors()
+	}
+
+	return []*envoy_accesslog_v3.AccessLog{{
+		Name: TCPGRPCAccessLog,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_v3.TcpGrpcAccessLogConfig{
+				CommonConfig: commonGrpcAccessLogConfig(config),
+			}),
+		},
+		Filter: filter,
+	}}
+}
+
+func commonGrpcAccessLogConfig(config *GrpcAccessLogConfig) *envoy_grpc_v3.CommonGrpcAccessLogConfig {
+	return &envoy_grpc_v3.CommonGrpcAccessLogConfig{
+		LogName:             config.LogName,
+		GrpcService:         GrpcService(dag.ExtensionClusterName(config.ExtensionService), config.SNI, config.Timeout),
+		TransportApiVersion: envoy_config_core_v3.ApiVersion_V3,
+	}
+}
+
+// commandOperatorRegex matches format strings consisting of a single
+// command operator, such as "%REQ(X-ENVOY-ORIGINAL-PATH?:PATH):10%".
+var commandOperatorRegex = regexp.MustCompile(`^%([A-Z_]+)(?:\(([^?)]+)(\?[^)]*)?\))?(:[0-9]+)?%$`)
+
+// commandOperator returns the operator and its first argument, if any,
+// of a format string consisting of a single command operator. Other
+// format strings return empty strings.
+func commandOperator(format string) (string, string) {
+	match := commandOperatorRegex.FindStringSubmatch(format)
+	if match == nil {
+		return "", ""
+	}
+	return match[1], match[2]
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
 func sv(s string) *_struct.Value {
 	return &_struct.Value{
 		Kind: &_struct.Value_StringValue{
@@ -161,3 +324,115 @@ func filterOnlyErrors() *envoy_accesslog_v3.AccessLogFilter {
 		},
 	}
 }
+
+// AccessLogPolicyName returns a name that identifies the access log policy.
+func AccessLogPolicyName(policy *dag.AccessLogPolicy) string {
+	buf := fmt.Sprintf("%s/%q/%q/%s", policy.Format, policy.FormatString, policy.JSONFields, policy.Level)
+
+	// This isn't a crypto hash, we just want a unique name.
+	hash := sha1.Sum([]byte(buf)) // nolint:gosec
+	return fmt.Sprintf("%x", hash[:5])
+}
+
+// FilterAccessLogPolicy returns an HTTP filter that records the access
+// log policy configured on the virtual host or route of a request in
+// the request's dynamic metadata. The filter has no global rules, the
+// rules are configured per virtual host and per route.
+func FilterAccessLogPolicy() *http.HttpFilter {
+	return &http.HttpFilter{
+		Name: headerToMetadataFilterName,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_header_to_metadata_v3.Config{}),
+		},
+	}
+}
+
+// accessLogPolicyConfig returns a per-filter config that records the
+// named access log policy in the request's dynamic metadata.
+func accessLogPolicyConfig(name string) *any.Any {
+	// The :path header is present on every request
+	// except CONNECT, so record the policy either way.
+	policy := &envoy_header_to_metadata_v3.Config_KeyValuePair{
+		MetadataNamespace: headerToMetadataFilterName,
+		Key:               accessLogPolicyMetadataKey,
+		Value:             name,
+	}
+
+	return protobuf.MustMarshalAny(&envoy_header_to_metadata_v3.Config{
+		RequestRules: []*envoy_header_to_metadata_v3.Config_Rule{{
+			Header:          ":path",
+			OnHeaderPresent: policy,
+			OnHeaderMissing: policy,
+		}},
+	})
+}
+
+// AccessLogsWithoutPolicy restricts the access logs to requests that
+// have no access log policy.
+func AccessLogsWithoutPolicy(logs []*envoy_accesslog_v3.AccessLog) []*envoy_accesslog_v3.AccessLog {
+	return withAccessLogFilter(logs, &envoy_accesslog_v3.AccessLogFilter{
+		FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_MetadataFilter{
+			MetadataFilter: &envoy_accesslog_v3.MetadataFilter{
+				Matcher: accessLogPolicyMatcher(&envoy_matcher_v3.ValueMatcher{
+					MatchPattern: &envoy_matcher_v3.ValueMatcher_PresentMatch{
+						PresentMatch: true,
+					},
+				}, true),
+			},
+		},
+	})
+}
+
+// AccessLogsForPolicy restricts the access logs to requests that have
+// the named access log policy.
+func AccessLogsForPolicy(name string, logs []*envoy_accesslog_v3.AccessLog) []*envoy_accesslog_v3.AccessLog {
+	return withAccessLogFilter(logs, &envoy_accesslog_v3.AccessLogFilter{
+		FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_MetadataFilter{
+			MetadataFilter: &envoy_accesslog_v3.MetadataFilter{
+				Matcher: accessLogPolicyMatcher(&envoy_matcher_v3.ValueMatcher{
+					MatchPattern: &envoy_matcher_v3.ValueMatcher_StringMatch{
+						StringMatch: &envoy_matcher_v3.StringMatcher{
+							MatchPattern: &envoy_matcher_v3.StringMatcher_Exact{
+								Exact: name,
+							},
+						},
+					},
+				}, false),
+				MatchIfKeyNotFound: &wrappers.BoolValue{Value: false},
+			},
+		},
+	})
+}
+
+func accessLogPolicyMatcher(value *envoy_matcher_v3.ValueMatcher, invert bool) *envoy_matcher_v3.MetadataMatcher {
+	return &envoy_matcher_v3.MetadataMatcher{
+		Filter: headerToMetadataFilterName,
+		Path: []*envoy_matcher_v3.MetadataMatcher_PathSegment{{
+			Segment: &envoy_matcher_v3.MetadataMatcher_PathSegment_Key{
+				Key: accessLogPolicyMetadataKey,
+			},
+		}},
+		Value:  value,
+		Invert: invert,
+	}
+}
+
+// withAccessLogFilter adds the filter to each access log, combining it
+// with any existing filter.
+func withAccessLogFilter(logs []*envoy_accesslog_v3.AccessLog, filter *envoy_accesslog_v3.AccessLogFilter) []*envoy_accesslog_v3.AccessLog {
+	for _, log := range logs {
+		if log.Filter == nil {
+			log.Filter = filter
+			continue
+		}
+
+		log.Filter = &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_AndFilter{
+				AndFilter: &envoy_accesslog_v3.AndFilter{
+					Filters: []*envoy_accesslog_v3.AccessLogFilter{filter, log.Filter},
+				},
+			},
+		}
+	}
+	return logs
+}
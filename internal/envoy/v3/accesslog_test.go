@@ -15,15 +15,24 @@ package v3
 
 import (
 	"testing"
+	"time"
 
 	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_config_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_file_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
+	envoy_grpc_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/grpc/v3"
+	envoy_header_to_metadata_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/header_to_metadata/v3"
 	envoy_req_without_query_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/formatter/req_without_query/v3"
+	envoy_matcher_v3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_tracing_v3 "github.com/envoyproxy/go-control-plane/envoy/type/tracing/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/k8s"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -264,3 +273,224 @@ func TestAccessLogLevel(t *testing.T) {
 	// Log level disabled should return nil.
 	assert.Nil(t, FileAccessLogJSON("/dev/stdout", nil, nil, contour_api_v1alpha1.LogLevelDisabled))
 }
+
+func TestGrpcAccessLog(t *testing.T) {
+	config := &GrpcAccessLogConfig{
+		ExtensionService: k8s.NamespacedNameFrom("projectcontour/als"),
+		SNI:              "als.example.com",
+		Timeout:          timeout.DurationSetting(5 * time.Second),
+		LogName:          "contour",
+	}
+
+	common := func(tags ...*envoy_tracing_v3.CustomTag) *envoy_grpc_v3.CommonGrpcAccessLogConfig {
+		return &envoy_grpc_v3.CommonGrpcAccessLogConfig{
+			LogName: "contour",
+			GrpcService: &envoy_config_core_v3.GrpcService{
+				TargetSpecifier: &envoy_config_core_v3.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &envoy_config_core_v3.GrpcService_EnvoyGrpc{
+						ClusterName: "extension/projectcontour/als",
+						Authority:   "als.example.com",
+					},
+				},
+				Timeout: protobuf.Duration(5 * time.Second),
+			},
+			TransportApiVersion: envoy_config_core_v3.ApiVersion_V3,
+			CustomTags:          tags,
+		}
+	}
+
+	requestHeaderTag := func(tag, header string) *envoy_tracing_v3.CustomTag {
+		return &envoy_tracing_v3.CustomTag{
+			Tag: tag,
+			Type: &envoy_tracing_v3.CustomTag_RequestHeader{
+				RequestHeader: &envoy_tracing_v3.CustomTag_Header{
+					Name: header,
+				},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		fields contour_api_v1alpha1.AccessLogJSONFields
+		want   []*envoy_accesslog_v3.AccessLog
+	}{
+		"no fields": {
+			want: []*envoy_accesslog_v3.AccessLog{{
+				Name: wellknown.HTTPGRPCAccessLog,
+				ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_v3.HttpGrpcAccessLogConfig{
+						CommonConfig: common(),
+					}),
+				},
+			}},
+		},
+		"header fields": {
+			fields: contour_api_v1alpha1.AccessLogJSONFields{
+				"@timestamp",
+				"path",
+				"user_agent",
+				"upstream_service_time",
+				"grpc_status=%TRAILER(GRPC-STATUS)%",
+				"duration",
+			},
+			want: []*envoy_accesslog_v3.AccessLog{{
+				Name: wellknown.HTTPGRPCAccessLog,
+				ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_v3.HttpGrpcAccessLogConfig{
+						CommonConfig: common(
+							requestHeaderTag("path", "x-envoy-original-path"),
+							requestHeaderTag("user_agent", "user-agent"),
+						),
+						AdditionalResponseHeadersToLog:  []string{"x-envoy-upstream-service-time"},
+						AdditionalResponseTrailersToLog: []string{"grpc-status"},
+					}),
+				},
+			}},
+		},
+		"environment and gRPC status fields": {
+			fields: contour_api_v1alpha1.AccessLogJSONFields{
+				"pod=%ENVIRONMENT(POD_NAME)%",
+				"grpc_status",
+				"status_trailer=%TRAILER(GRPC-STATUS)%",
+			},
+			want: []*envoy_accesslog_v3.AccessLog{{
+				Name: wellknown.HTTPGRPCAccessLog,
+				ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_v3.HttpGrpcAccessLogConfig{
+						CommonConfig: common(&envoy_tracing_v3.CustomTag{
+							Tag: "pod",
+							Type: &envoy_tracing_v3.CustomTag_Environment_{
+								Environment: &envoy_tracing_v3.CustomTag_Environment{
+									Name: "POD_NAME",
+								},
+							},
+						}),
+						AdditionalResponseHeadersToLog:  []string{"grpc-status"},
+						AdditionalResponseTrailersToLog: []string{"grpc-status"},
+					}),
+				},
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, tc.want, HTTPGrpcAccessLog(config, tc.fields, contour_api_v1alpha1.LogLevelInfo))
+		})
+	}
+
+	protobuf.ExpectEqual(t, []*envoy_accesslog_v3.AccessLog{{
+		Name: "envoy.access_loggers.tcp_grpc",
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_grpc_v3.TcpGrpcAccessLogConfig{
+				CommonConfig: common(),
+			}),
+		},
+	}}, TCPGrpcAccessLog(config, contour_api_v1alpha1.LogLevelInfo))
+
+	// Log level disabled should return nil.
+	assert.Nil(t, HTTPGrpcAccessLog(config, nil, contour_api_v1alpha1.LogLevelDisabled))
+	assert.Nil(t, TCPGrpcAccessLog(config, contour_api_v1alpha1.LogLevelDisabled))
+}
+
+func TestAccessLogPolicy(t *testing.T) {
+	policy := &dag.AccessLogPolicy{
+		Format: contour_api_v1alpha1.JSONAccessLog,
+		Level:  contour_api_v1alpha1.LogLevelError,
+	}
+	name := AccessLogPolicyName(policy)
+
+	// Names are stable and differ between policies.
+	assert.Equal(t, name, AccessLogPolicyName(&dag.AccessLogPolicy{
+		Format: contour_api_v1alpha1.JSONAccessLog,
+		Level:  contour_api_v1alpha1.LogLevelError,
+	}))
+	assert.NotEqual(t, name, AccessLogPolicyName(&dag.AccessLogPolicy{
+		Format: contour_api_v1alpha1.JSONAccessLog,
+	}))
+
+	keyValue := &envoy_header_to_metadata_v3.Config_KeyValuePair{
+		MetadataNamespace: "envoy.filters.http.header_to_metadata",
+		Key:               "access_log_policy",
+		Value:             name,
+	}
+	protobuf.ExpectEqual(t, protobuf.MustMarshalAny(&envoy_header_to_metadata_v3.Config{
+		RequestRules: []*envoy_header_to_metadata_v3.Config_Rule{{
+			Header:          ":path",
+			OnHeaderPresent: keyValue,
+			OnHeaderMissing: keyValue,
+		}},
+	}), accessLogPolicyConfig(name))
+
+	policyMatcher := func(value *envoy_matcher_v3.ValueMatcher, invert bool) *envoy_matcher_v3.MetadataMatcher {
+		return &envoy_matcher_v3.MetadataMatcher{
+			Filter: "envoy.filters.http.header_to_metadata",
+			Path: []*envoy_matcher_v3.MetadataMatcher_PathSegment{{
+				Segment: &envoy_matcher_v3.MetadataMatcher_PathSegment_Key{
+					Key: "access_log_policy",
+				},
+			}},
+			Value:  value,
+			Invert: invert,
+		}
+	}
+
+	// Requests without a policy are logged by the default access logs.
+	got := AccessLogsWithoutPolicy(FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelInfo))
+	protobuf.ExpectEqual(t, []*envoy_accesslog_v3.AccessLog{{
+		Name: wellknown.FileAccessLog,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_file_v3.FileAccessLog{
+				Path: "/dev/stdout",
+			}),
+		},
+		Filter: &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_MetadataFilter{
+				MetadataFilter: &envoy_accesslog_v3.MetadataFilter{
+					Matcher: policyMatcher(&envoy_matcher_v3.ValueMatcher{
+						MatchPattern: &envoy_matcher_v3.ValueMatcher_PresentMatch{
+							PresentMatch: true,
+						},
+					}, true),
+				},
+			},
+		},
+	}}, got)
+
+	// Requests with a policy are logged by the policy's access
+	// logs, combined with the filter for the log level.
+	got = AccessLogsForPolicy(name, FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelError))
+	protobuf.ExpectEqual(t, []*envoy_accesslog_v3.AccessLog{{
+		Name: wellknown.FileAccessLog,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_file_v3.FileAccessLog{
+				Path: "/dev/stdout",
+			}),
+		},
+		Filter: &envoy_accesslog_v3.AccessLogFilter{
+			FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_AndFilter{
+				AndFilter: &envoy_accesslog_v3.AndFilter{
+					Filters: []*envoy_accesslog_v3.AccessLogFilter{
+						{
+							FilterSpecifier: &envoy_accesslog_v3.AccessLogFilter_MetadataFilter{
+								MetadataFilter: &envoy_accesslog_v3.MetadataFilter{
+									Matcher: policyMatcher(&envoy_matcher_v3.ValueMatcher{
+										MatchPattern: &envoy_matcher_v3.ValueMatcher_StringMatch{
+											StringMatch: &envoy_matcher_v3.StringMatcher{
+												MatchPattern: &envoy_matcher_v3.StringMatcher_Exact{
+													Exact: name,
+												},
+											},
+										},
+									}, false),
+									MatchIfKeyNotFound: &wrappers.BoolValue{Value: false},
+								},
+							},
+						},
+						filterOnlyErrors(),
+					},
+				},
+			},
+		},
+	}}, got)
+}
@@ -15,13 +15,20 @@ package v3
 
 import (
 	envoy_api_v3_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoy_rbac_filter_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_v3_tls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
 )
 
+const rbacFilterName = "envoy.filters.http.rbac"
+
 // UpstreamTLSContext creates an envoy_v3_tls.UpstreamTlsContext. By default
 // UpstreamTLSContext returns a HTTP/1.1 TLS enabled context. A list of
 // additional ALPN protocols can be provided.
@@ -42,14 +49,14 @@ func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni st
 		Sni: sni,
 	}
 
-	if peerValidationContext.GetCACertificate() != nil && len(peerValidationContext.GetSubjectName()) > 0 {
+	if peerValidationContext.GetCACertificate() != nil && len(peerValidationContext.GetSubjectAltNames()) > 0 {
 		// We have to explicitly assign the value from validationContext
 		// to context.CommonTlsContext.ValidationContextType because the
 		// latter is an interface. Returning nil from validationContext
 		// directly into this field boxes the nil into the unexported
 		// type of this grpc OneOf field which causes proto marshaling
 		// to explode later on.
-		vc := validationContext(peerValidationContext.GetCACertificate(), peerValidationContext.GetSubjectName(), false, nil, false)
+		vc := validationContext(peerValidationContext.GetCACertificate(), peerValidationContext.GetSubjectAltNames(), nil, false, nil, false)
 		if vc != nil {
 			// TODO: update this for SDS (CommonTlsContext_ValidationContextSdsSecretConfig) instead of inlining it.
 			context.CommonTlsContext.ValidationContextType = vc
@@ -59,8 +66,65 @@ func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni st
 	return context
 }
 
+// SDSSecretConfig returns an envoy_v3_tls.SdsSecretConfig that fetches
+// the secret from its secret discovery service.
+func SDSSecretConfig(secret *dag.SDSSecret) *envoy_v3_tls.SdsSecretConfig {
+	return &envoy_v3_tls.SdsSecretConfig{
+		Name: secret.Name,
+		SdsConfig: &envoy_api_v3_core.ConfigSource{
+			ResourceApiVersion: envoy_api_v3_core.ApiVersion_V3,
+			ConfigSourceSpecifier: &envoy_api_v3_core.ConfigSource_ApiConfigSource{
+				ApiConfigSource: &envoy_api_v3_core.ApiConfigSource{
+					ApiType:             envoy_api_v3_core.ApiConfigSource_GRPC,
+					TransportApiVersion: envoy_api_v3_core.ApiVersion_V3,
+					GrpcServices: []*envoy_api_v3_core.GrpcService{
+						GrpcService(dag.ExtensionClusterName(secret.Service.ExtensionService), secret.Service.SNI, secret.Service.Timeout),
+					},
+				},
+			},
+		},
+	}
+}
+
+// subjectAltNameType returns the Envoy SAN type for t, defaulting to DNS.
+func subjectAltNameType(t contour_api_v1.SubjectAltNameType) envoy_v3_tls.SubjectAltNameMatcher_SanType {
+	switch t {
+	case contour_api_v1.SubjectAltNameTypeURI:
+		return envoy_v3_tls.SubjectAltNameMatcher_URI
+	case contour_api_v1.SubjectAltNameTypeIP:
+		return envoy_v3_tls.SubjectAltNameMatcher_IP_ADDRESS
+	default:
+		return envoy_v3_tls.SubjectAltNameMatcher_DNS
+	}
+}
+
+// stringMatcher returns the Envoy string matcher for the dag.StringMatchType
+// matchType and value.
+func stringMatcher(matchType, value string) *matcher.StringMatcher {
+	m := &matcher.StringMatcher{}
+	switch matchType {
+	case dag.StringMatchTypePrefix:
+		m.MatchPattern = &matcher.StringMatcher_Prefix{Prefix: value}
+	case dag.StringMatchTypeSuffix:
+		m.MatchPattern = &matcher.StringMatcher_Suffix{Suffix: value}
+	case dag.StringMatchTypeRegex:
+		m.MatchPattern = &matcher.StringMatcher_SafeRegex{SafeRegex: SafeRegexMatch(value)}
+	default:
+		m.MatchPattern = &matcher.StringMatcher_Exact{Exact: value}
+	}
+	return m
+}
+
+// subjectAltNameMatcher returns the Envoy SAN matcher for san.
+func subjectAltNameMatcher(san dag.SubjectAltNameMatcher) *envoy_v3_tls.SubjectAltNameMatcher {
+	return &envoy_v3_tls.SubjectAltNameMatcher{
+		SanType: subjectAltNameType(san.Type),
+		Matcher: stringMatcher(san.MatchType, san.Value),
+	}
+}
+
 // TODO: update this for SDS (CommonTlsContext_ValidationContextSdsSecretConfig) instead of inlining it.
-func validationContext(ca []byte, subjectName string, skipVerifyPeerCert bool, crl []byte, onlyVerifyLeafCertCrl bool) *envoy_v3_tls.CommonTlsContext_ValidationContext {
+func validationContext(ca []byte, subjectAltNames []dag.SubjectAltNameMatcher, certificateHashes []string, skipVerifyPeerCert bool, crl []byte, onlyVerifyLeafCertCrl bool) *envoy_v3_tls.CommonTlsContext_ValidationContext {
 	vc := &envoy_v3_tls.CommonTlsContext_ValidationContext{
 		ValidationContext: &envoy_v3_tls.CertificateValidationContext{
 			TrustChainVerification: envoy_v3_tls.CertificateValidationContext_VERIFY_TRUST_CHAIN,
@@ -79,19 +143,12 @@ func validationContext(ca []byte, subjectName string, skipVerifyPeerCert bool, c
 		}
 	}
 
-	if len(subjectName) > 0 {
-		vc.ValidationContext.MatchTypedSubjectAltNames = []*envoy_v3_tls.SubjectAltNameMatcher{
-			{
-				SanType: envoy_v3_tls.SubjectAltNameMatcher_DNS,
-				Matcher: &matcher.StringMatcher{
-					MatchPattern: &matcher.StringMatcher_Exact{
-						Exact: subjectName,
-					},
-				},
-			},
-		}
+	for _, san := range subjectAltNames {
+		vc.ValidationContext.MatchTypedSubjectAltNames = append(vc.ValidationContext.MatchTypedSubjectAltNames, subjectAltNameMatcher(san))
 	}
 
+	vc.ValidationContext.VerifyCertificateHash = certificateHashes
+
 	if len(crl) > 0 {
 		vc.ValidationContext.Crl = &envoy_api_v3_core.DataSource{
 			Specifier: &envoy_api_v3_core.DataSource_InlineBytes{
@@ -104,6 +161,19 @@ func validationContext(ca []byte, subjectName string, skipVerifyPeerCert bool, c
 	return vc
 }
 
+// OCSPStaplePolicy returns the Envoy OCSP staple policy for policy,
+// defaulting to lenient stapling.
+func OCSPStaplePolicy(policy contour_api_v1.OCSPStaplePolicy) envoy_v3_tls.DownstreamTlsContext_OcspStaplePolicy {
+	switch policy {
+	case contour_api_v1.OCSPStaplePolicyStrict:
+		return envoy_v3_tls.DownstreamTlsContext_STRICT_STAPLING
+	case contour_api_v1.OCSPStaplePolicyMust:
+		return envoy_v3_tls.DownstreamTlsContext_MUST_STAPLE
+	default:
+		return envoy_v3_tls.DownstreamTlsContext_LENIENT_STAPLING
+	}
+}
+
 // DownstreamTLSContext creates a new DownstreamTlsContext.
 func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_v3_tls.TlsParameters_TlsProtocol, cipherSuites []string, peerValidationContext *dag.PeerValidationContext, alpnProtos ...string) *envoy_v3_tls.DownstreamTlsContext {
 	context := &envoy_v3_tls.DownstreamTlsContext{
@@ -121,7 +191,8 @@ func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_v3_
 		},
 	}
 	if peerValidationContext != nil {
-		vc := validationContext(peerValidationContext.GetCACertificate(), "", peerValidationContext.SkipClientCertValidation,
+		vc := validationContext(peerValidationContext.GetCACertificate(), peerValidationContext.SubjectAltNames, peerValidationContext.CertificateHashes,
+			peerValidationContext.SkipClientCertValidation,
 			peerValidationContext.GetCRL(), peerValidationContext.OnlyVerifyLeafCertCrl)
 		if vc != nil {
 			context.CommonTlsContext.ValidationContextType = vc
@@ -131,3 +202,48 @@ func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_v3_
 
 	return context
 }
+
+// FilterClientCertificateAuthorization returns an HTTP filter that
+// authorizes requests by the principal of the client certificate. The
+// filter has no global rules, the rules are configured per virtual host
+// and per route.
+func FilterClientCertificateAuthorization() *http.HttpFilter {
+	return &http.HttpFilter{
+		Name: rbacFilterName,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_rbac_filter_v3.RBAC{}),
+		},
+	}
+}
+
+// clientCertificateAuthorizationConfig returns a per-filter config that
+// allows only requests whose client certificate principal matches one
+// of the principals of cca.
+func clientCertificateAuthorizationConfig(cca *dag.ClientCertificateAuthorization) *any.Any {
+	var principals []*envoy_rbac_v3.Principal
+	for _, p := range cca.Principals {
+		principals = append(principals, &envoy_rbac_v3.Principal{
+			Identifier: &envoy_rbac_v3.Principal_Authenticated_{
+				Authenticated: &envoy_rbac_v3.Principal_Authenticated{
+					PrincipalName: stringMatcher(p.MatchType, p.Value),
+				},
+			},
+		})
+	}
+
+	return protobuf.MustMarshalAny(&envoy_rbac_filter_v3.RBACPerRoute{
+		Rbac: &envoy_rbac_filter_v3.RBAC{
+			Rules: &envoy_rbac_v3.RBAC{
+				Action: envoy_rbac_v3.RBAC_ALLOW,
+				Policies: map[string]*envoy_rbac_v3.Policy{
+					"client-certificate": {
+						Permissions: []*envoy_rbac_v3.Permission{{
+							Rule: &envoy_rbac_v3.Permission_Any{Any: true},
+						}},
+						Principals: principals,
+					},
+				},
+			},
+		},
+	})
+}
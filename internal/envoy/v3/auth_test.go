@@ -19,6 +19,7 @@ import (
 	envoy_api_v3_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_v3_tls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/protobuf"
 	v1 "k8s.io/api/core/v1"
@@ -101,6 +102,67 @@ func TestUpstreamTLSContext(t *testing.T) {
 				},
 			},
 		},
+		"subject alt name matchers": {
+			validation: &dag.PeerValidationContext{
+				CACertificate:   secret,
+				SubjectName:     "spiffe://cluster.local/ns/default/sa/backend",
+				SubjectNameType: contour_api_v1.SubjectAltNameTypeURI,
+				SubjectAltNames: []dag.SubjectAltNameMatcher{
+					{MatchType: dag.StringMatchTypeSuffix, Value: ".example.com"},
+					{MatchType: dag.StringMatchTypeRegex, Value: "^backend-[0-9]+\\.local$"},
+					{Type: contour_api_v1.SubjectAltNameTypeIP, MatchType: dag.StringMatchTypePrefix, Value: "10.0."},
+				},
+			},
+			want: &envoy_v3_tls.UpstreamTlsContext{
+				CommonTlsContext: &envoy_v3_tls.CommonTlsContext{
+					ValidationContextType: &envoy_v3_tls.CommonTlsContext_ValidationContext{
+						ValidationContext: &envoy_v3_tls.CertificateValidationContext{
+							TrustedCa: &envoy_api_v3_core.DataSource{
+								Specifier: &envoy_api_v3_core.DataSource_InlineBytes{
+									InlineBytes: []byte("ca"),
+								},
+							},
+							MatchTypedSubjectAltNames: []*envoy_v3_tls.SubjectAltNameMatcher{
+								{
+									SanType: envoy_v3_tls.SubjectAltNameMatcher_URI,
+									Matcher: &matcher.StringMatcher{
+										MatchPattern: &matcher.StringMatcher_Exact{
+											Exact: "spiffe://cluster.local/ns/default/sa/backend",
+										},
+									},
+								},
+								{
+									SanType: envoy_v3_tls.SubjectAltNameMatcher_DNS,
+									Matcher: &matcher.StringMatcher{
+										MatchPattern: &matcher.StringMatcher_Suffix{
+											Suffix: ".example.com",
+										},
+									},
+								},
+								{
+									SanType: envoy_v3_tls.SubjectAltNameMatcher_DNS,
+									Matcher: &matcher.StringMatcher{
+										MatchPattern: &matcher.StringMatcher_SafeRegex{
+											SafeRegex: &matcher.RegexMatcher{
+												Regex: "^backend-[0-9]+\\.local$",
+											},
+										},
+									},
+								},
+								{
+									SanType: envoy_v3_tls.SubjectAltNameMatcher_IP_ADDRESS,
+									Matcher: &matcher.StringMatcher{
+										MatchPattern: &matcher.StringMatcher_Prefix{
+											Prefix: "10.0.",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		"external name sni": {
 			externalName: "projectcontour.local",
 			want: &envoy_v3_tls.UpstreamTlsContext{
@@ -253,6 +253,26 @@ func bootstrapConfig(c *envoy.BootstrapConfig) *envoy_bootstrap_v3.Bootstrap {
 			Address:   UnixSocketAddress(c.GetAdminAddress(), c.GetAdminPort()),
 		},
 	}
+	if c.Zone != "" {
+		// Zone aware routing needs Envoy to know its own zone and
+		// the zones of its peers, which Contour serves over EDS.
+		bootstrap.Node = &envoy_core_v3.Node{
+			Locality: &envoy_core_v3.Locality{Zone: c.Zone},
+		}
+		bootstrap.ClusterManager = &envoy_bootstrap_v3.ClusterManager{
+			LocalClusterName: envoy.LocalClusterName,
+		}
+		bootstrap.StaticResources.Clusters = append(bootstrap.StaticResources.Clusters, &envoy_cluster_v3.Cluster{
+			Name:                 envoy.LocalClusterName,
+			AltStatName:          strings.Join([]string{c.Namespace, envoy.LocalClusterName}, "_"),
+			ConnectTimeout:       protobuf.Duration(250 * time.Millisecond),
+			ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+			EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+				EdsConfig: ConfigSource("contour"),
+			},
+			LbPolicy: envoy_cluster_v3.Cluster_ROUND_ROBIN,
+		})
+	}
 	if c.MaximumHeapSizeBytes > 0 {
 		bootstrap.OverloadManager = &envoy_config_overload_v3.OverloadManager{
 			RefreshInterval: protobuf.Duration(250 * time.Millisecond),
@@ -21,6 +21,7 @@ import (
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	envoy_v3_tls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	envoy_extensions_upstream_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/golang/protobuf/ptypes/any"
@@ -56,6 +57,14 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 		// external name not set, cluster will be discovered via EDS
 		cluster.ClusterDiscoveryType = ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS)
 		cluster.EdsClusterConfig = edsconfig("contour", service)
+
+		// Services that ask for topology aware hints keep traffic
+		// in the local zone unless a policy says otherwise.
+		strategy := c.LocalityLoadBalancerPolicy
+		if strategy == "" && service.TopologyAwareHints {
+			strategy = dag.LocalityLoadBalancerPolicyZoneAware
+		}
+		setLocalityLbConfig(cluster.CommonLbConfig, strategy)
 	default:
 		// external name set, use hard coded DNS name
 		cluster.ClusterDiscoveryType = ClusterDiscoveryType(envoy_cluster_v3.Cluster_STRICT_DNS)
@@ -67,6 +76,8 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 		cluster.IgnoreHealthOnHostRemoval = true
 	}
 
+	cluster.OutlierDetection = outlierDetection(c.OutlierDetectionPolicy)
+
 	if envoy.AnyPositive(service.MaxConnections, service.MaxPendingRequests, service.MaxRequests, service.MaxRetries) {
 		cluster.CircuitBreakers = &envoy_cluster_v3.CircuitBreakers{
 			Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
@@ -82,21 +93,12 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 	switch c.Protocol {
 	case "tls":
 		cluster.TransportSocket = UpstreamTLSTransportSocket(
-			UpstreamTLSContext(
-				c.UpstreamValidation,
-				c.SNI,
-				c.ClientCertificate,
-			),
+			upstreamTLSContext(c),
 		)
 	case "h2":
 		httpVersion = HTTPVersion2
 		cluster.TransportSocket = UpstreamTLSTransportSocket(
-			UpstreamTLSContext(
-				c.UpstreamValidation,
-				c.SNI,
-				c.ClientCertificate,
-				"h2",
-			),
+			upstreamTLSContext(c, "h2"),
 		)
 	case "h2c":
 		httpVersion = HTTPVersion2
@@ -111,6 +113,19 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 	return cluster
 }
 
+// upstreamTLSContext returns the upstream TLS context for c. If c has a
+// client certificate from a secret discovery service, Envoy fetches it
+// from that service rather than from Contour.
+func upstreamTLSContext(c *dag.Cluster, alpnProtocols ...string) *envoy_v3_tls.UpstreamTlsContext {
+	tlsContext := UpstreamTLSContext(c.UpstreamValidation, c.SNI, c.ClientCertificate, alpnProtocols...)
+	if c.ClientCertificateSDS != nil {
+		tlsContext.CommonTlsContext.TlsCertificateSdsSecretConfigs = []*envoy_v3_tls.SdsSecretConfig{
+			SDSSecretConfig(c.ClientCertificateSDS),
+		}
+	}
+	return tlsContext
+}
+
 // ExtensionCluster builds a envoy_cluster_v3.Cluster struct for the given extension service.
 func ExtensionCluster(ext *dag.ExtensionCluster) *envoy_cluster_v3.Cluster {
 	cluster := clusterDefaults()
@@ -197,6 +212,52 @@ func lbPolicy(strategy string) envoy_cluster_v3.Cluster_LbPolicy {
 	}
 }
 
+// setLocalityLbConfig configures how Envoy balances requests
+// across the localities of a cluster for the given strategy.
+func setLocalityLbConfig(config *envoy_cluster_v3.Cluster_CommonLbConfig, strategy string) {
+	switch strategy {
+	case dag.LocalityLoadBalancerPolicyLocalityWeighted:
+		config.LocalityConfigSpecifier = &envoy_cluster_v3.Cluster_CommonLbConfig_LocalityWeightedLbConfig_{
+			LocalityWeightedLbConfig: &envoy_cluster_v3.Cluster_CommonLbConfig_LocalityWeightedLbConfig{},
+		}
+	case dag.LocalityLoadBalancerPolicyZoneAware:
+		config.LocalityConfigSpecifier = &envoy_cluster_v3.Cluster_CommonLbConfig_ZoneAwareLbConfig_{
+			ZoneAwareLbConfig: &envoy_cluster_v3.Cluster_CommonLbConfig_ZoneAwareLbConfig{},
+		}
+	}
+}
+
+// outlierDetection returns the outlier detection configuration
+// for the given policy, or nil if there is no policy.
+func outlierDetection(policy *dag.OutlierDetectionPolicy) *envoy_cluster_v3.OutlierDetection {
+	if policy == nil {
+		return nil
+	}
+
+	od := &envoy_cluster_v3.OutlierDetection{
+		Consecutive_5Xx:    protobuf.UInt32OrNil(policy.ConsecutiveServerErrors),
+		MaxEjectionPercent: protobuf.UInt32OrNil(policy.MaxEjectionPercent),
+		// Ejecting on the success rate is enforced by default,
+		// but it can't be configured, so don't enforce it.
+		EnforcingSuccessRate: protobuf.UInt32(0),
+	}
+
+	// Ejecting on gateway errors is not enforced by default,
+	// so enforce it whenever a threshold is given.
+	if policy.ConsecutiveGatewayErrors > 0 {
+		od.ConsecutiveGatewayFailure = protobuf.UInt32(policy.ConsecutiveGatewayErrors)
+		od.EnforcingConsecutiveGatewayFailure = protobuf.UInt32(100)
+	}
+	if policy.Interval > 0 {
+		od.Interval = protobuf.Duration(policy.Interval)
+	}
+	if policy.BaseEjectionTime > 0 {
+		od.BaseEjectionTime = protobuf.Duration(policy.BaseEjectionTime)
+	}
+
+	return od
+}
+
 func edshealthcheck(c *dag.Cluster) []*envoy_core_v3.HealthCheck {
 	if c.HTTPHealthCheckPolicy == nil && c.TCPHealthCheckPolicy == nil {
 		return nil
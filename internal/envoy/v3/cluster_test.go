@@ -19,10 +19,13 @@ import (
 
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	envoy_extensions_upstream_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
+	envoy_matcher_v3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
@@ -445,6 +448,121 @@ func TestCluster(t *testing.T) {
 				LbPolicy: envoy_cluster_v3.Cluster_RING_HASH,
 			},
 		},
+		"cluster with locality weighted policy": {
+			cluster: &dag.Cluster{
+				Upstream:                   service(s1),
+				LocalityLoadBalancerPolicy: "LocalityWeighted",
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/531ef9517c",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				CommonLbConfig: &envoy_cluster_v3.Cluster_CommonLbConfig{
+					LocalityConfigSpecifier: &envoy_cluster_v3.Cluster_CommonLbConfig_LocalityWeightedLbConfig_{
+						LocalityWeightedLbConfig: &envoy_cluster_v3.Cluster_CommonLbConfig_LocalityWeightedLbConfig{},
+					},
+				},
+			},
+		},
+		"cluster with outlier detection": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1),
+				OutlierDetectionPolicy: &dag.OutlierDetectionPolicy{
+					ConsecutiveServerErrors:  3,
+					ConsecutiveGatewayErrors: 2,
+					Interval:                 5 * time.Second,
+					BaseEjectionTime:         time.Minute,
+					MaxEjectionPercent:       50,
+				},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/78e8c79c80",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				OutlierDetection: &envoy_cluster_v3.OutlierDetection{
+					Consecutive_5Xx:                    protobuf.UInt32(3),
+					ConsecutiveGatewayFailure:          protobuf.UInt32(2),
+					EnforcingConsecutiveGatewayFailure: protobuf.UInt32(100),
+					Interval:                           protobuf.Duration(5 * time.Second),
+					BaseEjectionTime:                   protobuf.Duration(time.Minute),
+					MaxEjectionPercent:                 protobuf.UInt32(50),
+					EnforcingSuccessRate:               protobuf.UInt32(0),
+				},
+			},
+		},
+		"cluster with outlier detection defaults": {
+			cluster: &dag.Cluster{
+				Upstream:               service(s1),
+				OutlierDetectionPolicy: &dag.OutlierDetectionPolicy{},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/9c3e9241d8",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				OutlierDetection: &envoy_cluster_v3.OutlierDetection{
+					EnforcingSuccessRate: protobuf.UInt32(0),
+				},
+			},
+		},
+		"cluster with zone aware policy": {
+			cluster: &dag.Cluster{
+				Upstream:                   service(s1),
+				LocalityLoadBalancerPolicy: "ZoneAware",
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/f1a79cadea",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				CommonLbConfig: &envoy_cluster_v3.Cluster_CommonLbConfig{
+					LocalityConfigSpecifier: &envoy_cluster_v3.Cluster_CommonLbConfig_ZoneAwareLbConfig_{
+						ZoneAwareLbConfig: &envoy_cluster_v3.Cluster_CommonLbConfig_ZoneAwareLbConfig{},
+					},
+				},
+			},
+		},
+		"cluster with topology aware hints": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Weighted: dag.WeightedService{
+						Weight:           1,
+						ServiceName:      s1.Name,
+						ServiceNamespace: s1.Namespace,
+						ServicePort:      s1.Spec.Ports[0],
+					},
+					TopologyAwareHints: true,
+				},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/da39a3ee5e",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				CommonLbConfig: &envoy_cluster_v3.Cluster_CommonLbConfig{
+					LocalityConfigSpecifier: &envoy_cluster_v3.Cluster_CommonLbConfig_ZoneAwareLbConfig_{
+						ZoneAwareLbConfig: &envoy_cluster_v3.Cluster_CommonLbConfig_ZoneAwareLbConfig{},
+					},
+				},
+			},
+		},
 
 		"tcp service": {
 			cluster: &dag.Cluster{
@@ -497,7 +615,7 @@ func TestCluster(t *testing.T) {
 				ClientCertificate: clientSecret,
 			},
 			want: &envoy_cluster_v3.Cluster{
-				Name:                 "default/kuard/443/4929fca9d4",
+				Name:                 "default/kuard/443/b7539ca9d8",
 				AltStatName:          "default_kuard_443",
 				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
 				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
@@ -509,6 +627,77 @@ func TestCluster(t *testing.T) {
 				),
 			},
 		},
+		"use client certificate from secret discovery service with URI subject name": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1, "tls"),
+				Protocol: "tls",
+				UpstreamValidation: &dag.PeerValidationContext{
+					CACertificate:   secret,
+					SubjectName:     "spiffe://cluster.local/ns/default/sa/kuard",
+					SubjectNameType: contour_api_v1.SubjectAltNameTypeURI,
+				},
+				ClientCertificateSDS: &dag.SDSSecret{
+					Name: "spiffe://cluster.local/ns/projectcontour/sa/envoy",
+					Service: &dag.SecretDiscoveryService{
+						ExtensionService: types.NamespacedName{Namespace: "spire", Name: "spire-agent"},
+						Timeout:          timeout.DurationSetting(5 * time.Second),
+					},
+				},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/24c1b751f9",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				TransportSocket: UpstreamTLSTransportSocket(
+					&envoy_tls_v3.UpstreamTlsContext{
+						CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
+							TlsCertificateSdsSecretConfigs: []*envoy_tls_v3.SdsSecretConfig{{
+								Name: "spiffe://cluster.local/ns/projectcontour/sa/envoy",
+								SdsConfig: &envoy_core_v3.ConfigSource{
+									ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+									ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_ApiConfigSource{
+										ApiConfigSource: &envoy_core_v3.ApiConfigSource{
+											ApiType:             envoy_core_v3.ApiConfigSource_GRPC,
+											TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+											GrpcServices: []*envoy_core_v3.GrpcService{{
+												TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+													EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+														ClusterName: "extension/spire/spire-agent",
+														Authority:   "extension.spire.spire-agent",
+													},
+												},
+												Timeout: protobuf.Duration(5 * time.Second),
+											}},
+										},
+									},
+								},
+							}},
+							ValidationContextType: &envoy_tls_v3.CommonTlsContext_ValidationContext{
+								ValidationContext: &envoy_tls_v3.CertificateValidationContext{
+									TrustedCa: &envoy_core_v3.DataSource{
+										Specifier: &envoy_core_v3.DataSource_InlineBytes{
+											InlineBytes: []byte("cacert"),
+										},
+									},
+									MatchTypedSubjectAltNames: []*envoy_tls_v3.SubjectAltNameMatcher{{
+										SanType: envoy_tls_v3.SubjectAltNameMatcher_URI,
+										Matcher: &envoy_matcher_v3.StringMatcher{
+											MatchPattern: &envoy_matcher_v3.StringMatcher_Exact{
+												Exact: "spiffe://cluster.local/ns/default/sa/kuard",
+											},
+										},
+									}},
+								},
+							},
+						},
+					},
+				),
+			},
+		},
 		"cluster with connect timeout set": {
 			cluster: &dag.Cluster{
 				Upstream:      service(s1),
@@ -24,6 +24,7 @@ import (
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	envoy_gzip_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/gzip/compressor/v3"
+	envoy_config_filter_http_buffer_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
 	envoy_compressor_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
 	envoy_cors_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cors/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
@@ -47,6 +48,11 @@ import (
 	"github.com/projectcontour/contour/internal/timeout"
 )
 
+// defaultBufferMaxRequestBytes is the global request size limit of
+// the buffer filter. It only applies to requests that don't match a
+// route, since virtual hosts disable the filter by default.
+const defaultBufferMaxRequestBytes = 1024 * 1024
+
 type HTTPVersionType = http.HttpConnectionManager_CodecType
 
 const (
@@ -144,6 +150,24 @@ func Listener(name, address string, port int, lf []*envoy_listener_v3.ListenerFi
 	return l
 }
 
+// QUICListener returns a new envoy_listener_v3.Listener that serves
+// HTTP/3 over QUIC on the supplied UDP address and port.
+func QUICListener(name, address string, port int) *envoy_listener_v3.Listener {
+	addr := SocketAddress(address, port)
+	addr.GetSocketAddress().Protocol = envoy_core_v3.SocketAddress_UDP
+
+	return &envoy_listener_v3.Listener{
+		Name:    name,
+		Address: addr,
+		UdpListenerConfig: &envoy_listener_v3.UdpListenerConfig{
+			QuicOptions: &envoy_listener_v3.QuicProtocolOptions{},
+			DownstreamSocketConfig: &envoy_core_v3.UdpSocketConfig{
+				PreferGro: protobuf.Bool(true),
+			},
+		},
+	}
+}
+
 type httpConnectionManagerBuilder struct {
 	routeConfigName               string
 	metricsPrefix                 string
@@ -159,6 +183,8 @@ type httpConnectionManagerBuilder struct {
 	allowChunkedLength            bool
 	mergeSlashes                  bool
 	numTrustedHops                uint32
+	tracingConfig                 *http.HttpConnectionManager_Tracing
+	forwardClientCertificate      *dag.ClientCertificateDetails
 }
 
 // RouteConfigName sets the name of the RDS element that contains
@@ -242,6 +268,20 @@ func (b *httpConnectionManagerBuilder) NumTrustedHops(num uint32) *httpConnectio
 	return b
 }
 
+// Tracing sets the tracing configuration on the connection manager.
+func (b *httpConnectionManagerBuilder) Tracing(tracing *http.HttpConnectionManager_Tracing) *httpConnectionManagerBuilder {
+	b.tracingConfig = tracing
+	return b
+}
+
+// ForwardClientCertificate sets the client certificate details that are
+// forwarded to backends in the x-forwarded-client-cert header. If details
+// is nil, the header is removed from requests.
+func (b *httpConnectionManagerBuilder) ForwardClientCertificate(details *dag.ClientCertificateDetails) *httpConnectionManagerBuilder {
+	b.forwardClientCertificate = details
+	return b
+}
+
 func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBuilder {
 
 	// Add a default set of ordered http filters.
@@ -450,6 +490,8 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 		StreamIdleTimeout:   envoy.Timeout(b.streamIdleTimeout),
 		DrainTimeout:        envoy.Timeout(b.connectionShutdownGracePeriod),
 		DelayedCloseTimeout: envoy.Timeout(b.delayedCloseTimeout),
+
+		Tracing: b.tracingConfig,
 	}
 
 	// Max connection duration is infinite/disabled by default in Envoy, so if the timeout setting
@@ -464,6 +506,17 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 		cm.AccessLog = b.accessLoggers
 	}
 
+	if details := b.forwardClientCertificate; details != nil {
+		cm.ForwardClientCertDetails = http.HttpConnectionManager_SANITIZE_SET
+		cm.SetCurrentClientCertDetails = &http.HttpConnectionManager_SetCurrentClientCertDetails{
+			Subject: protobuf.Bool(details.Subject),
+			Cert:    details.Cert,
+			Chain:   details.Chain,
+			Dns:     details.DNS,
+			Uri:     details.URI,
+		}
+	}
+
 	// If there's no explicit metrics prefix, default it to the
 	// route config name.
 	if b.metricsPrefix != "" {
@@ -639,6 +692,24 @@ func FilterChains(filters ...*envoy_listener_v3.Filter) []*envoy_listener_v3.Fil
 	}
 }
 
+// FilterBuffer returns an HTTP filter that buffers request bodies.
+// The filter can't be disabled globally, so it should only be added
+// to HTTP connection managers whose routes have a buffer policy, and
+// the virtual hosts of their route configurations should disable it
+// with DisableBuffer.
+func FilterBuffer() *http.HttpFilter {
+	return &http.HttpFilter{
+		Name: "envoy.filters.http.buffer",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(
+				&envoy_config_filter_http_buffer_v3.Buffer{
+					MaxRequestBytes: protobuf.UInt32(defaultBufferMaxRequestBytes),
+				},
+			),
+		},
+	}
+}
+
 func FilterMisdirectedRequests(fqdn string) *http.HttpFilter {
 	var target string
 
@@ -749,6 +820,28 @@ func FilterChainTLS(domain string, downstream *envoy_tls_v3.DownstreamTlsContext
 	return fc
 }
 
+// FilterChainQUIC returns a QUIC enabled envoy_listener_v3.FilterChain.
+func FilterChainQUIC(domain string, downstream *envoy_tls_v3.DownstreamTlsContext, filters []*envoy_listener_v3.Filter) *envoy_listener_v3.FilterChain {
+	fc := &envoy_listener_v3.FilterChain{
+		Filters:         filters,
+		TransportSocket: DownstreamQUICTransportSocket(downstream),
+	}
+
+	// As with TLS, a vhost without a specific SNI
+	// matches any QUIC connection to this listener.
+	if domain == "*" {
+		fc.FilterChainMatch = &envoy_listener_v3.FilterChainMatch{
+			TransportProtocol: "quic",
+		}
+	} else {
+		fc.FilterChainMatch = &envoy_listener_v3.FilterChainMatch{
+			ServerNames: []string{domain},
+		}
+	}
+
+	return fc
+}
+
 // FilterChainTLSFallback returns a TLS enabled envoy_listener_v3.FilterChain conifgured for FallbackCertificate.
 func FilterChainTLSFallback(downstream *envoy_tls_v3.DownstreamTlsContext, filters []*envoy_listener_v3.Filter) *envoy_listener_v3.FilterChain {
 	fc := &envoy_listener_v3.FilterChain{
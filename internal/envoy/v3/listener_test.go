@@ -32,9 +32,11 @@ import (
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_tcp_proxy_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
@@ -403,6 +405,32 @@ func TestDownstreamTLSContext(t *testing.T) {
 		},
 	}
 
+	peerValidationContextWithSANsAndHashes := &dag.PeerValidationContext{
+		CACertificate: peerValidationContext.CACertificate,
+		SubjectAltNames: []dag.SubjectAltNameMatcher{{
+			Type:      contour_api_v1.SubjectAltNameTypeURI,
+			MatchType: dag.StringMatchTypePrefix,
+			Value:     "spiffe://cluster.local/",
+		}},
+		CertificateHashes: []string{"948fe603f61dc036b5c596dc09fe3ce3f3d30dc90f024c85f3c82db2ccab679d"},
+	}
+	validationContextWithSANsAndHashes := &envoy_tls_v3.CommonTlsContext_ValidationContext{
+		ValidationContext: &envoy_tls_v3.CertificateValidationContext{
+			TrustedCa: &envoy_core_v3.DataSource{
+				Specifier: &envoy_core_v3.DataSource_InlineBytes{
+					InlineBytes: ca,
+				},
+			},
+			MatchTypedSubjectAltNames: []*envoy_tls_v3.SubjectAltNameMatcher{{
+				SanType: envoy_tls_v3.SubjectAltNameMatcher_URI,
+				Matcher: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Prefix{Prefix: "spiffe://cluster.local/"},
+				},
+			}},
+			VerifyCertificateHash: []string{"948fe603f61dc036b5c596dc09fe3ce3f3d30dc90f024c85f3c82db2ccab679d"},
+		},
+	}
+
 	tests := map[string]struct {
 		got  *envoy_tls_v3.DownstreamTlsContext
 		want *envoy_tls_v3.DownstreamTlsContext
@@ -489,6 +517,18 @@ func TestDownstreamTLSContext(t *testing.T) {
 				RequireClientCertificate: protobuf.Bool(true),
 			},
 		},
+		"Downstream validation with subject alt names and certificate hashes": {
+			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithSANsAndHashes, "h2", "http/1.1"),
+			&envoy_tls_v3.DownstreamTlsContext{
+				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
+					TlsParams:                      tlsParams,
+					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
+					AlpnProtocols:                  alpnProtocols,
+					ValidationContextType:          validationContextWithSANsAndHashes,
+				},
+				RequireClientCertificate: protobuf.Bool(true),
+			},
+		},
 	}
 
 	for name, tc := range tests {
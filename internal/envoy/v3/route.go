@@ -23,6 +23,7 @@ import (
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_config_filter_http_buffer_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
@@ -38,7 +39,20 @@ import (
 func VirtualHostAndRoutes(vh *dag.VirtualHost, dagRoutes []*dag.Route, secure bool, authService *dag.ExtensionCluster) *envoy_route_v3.VirtualHost {
 	var envoyRoutes []*envoy_route_v3.Route
 	for _, route := range dagRoutes {
-		envoyRoutes = append(envoyRoutes, buildRoute(route, vh.Name, secure, authService))
+		rt := buildRoute(route, vh.Name, secure, authService)
+		if route.AccessLogPolicy != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig[headerToMetadataFilterName] = accessLogPolicyConfig(AccessLogPolicyName(route.AccessLogPolicy))
+		}
+		if secure && route.ClientCertificateAuthorization != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig[rbacFilterName] = clientCertificateAuthorizationConfig(route.ClientCertificateAuthorization)
+		}
+		envoyRoutes = append(envoyRoutes, rt)
 	}
 
 	evh := VirtualHost(vh.Name, envoyRoutes...)
@@ -57,6 +71,20 @@ func VirtualHostAndRoutes(vh *dag.VirtualHost, dagRoutes []*dag.Route, secure bo
 		evh.RateLimits = GlobalRateLimits(vh.RateLimitPolicy.Global.Descriptors)
 	}
 
+	if vh.AccessLogPolicy != nil {
+		if evh.TypedPerFilterConfig == nil {
+			evh.TypedPerFilterConfig = map[string]*any.Any{}
+		}
+		evh.TypedPerFilterConfig[headerToMetadataFilterName] = accessLogPolicyConfig(AccessLogPolicyName(vh.AccessLogPolicy))
+	}
+
+	if secure && vh.ClientCertificateAuthorization != nil {
+		if evh.TypedPerFilterConfig == nil {
+			evh.TypedPerFilterConfig = map[string]*any.Any{}
+		}
+		evh.TypedPerFilterConfig[rbacFilterName] = clientCertificateAuthorizationConfig(vh.ClientCertificateAuthorization)
+	}
+
 	return evh
 }
 
@@ -104,6 +132,13 @@ func buildRoute(dagRoute *dag.Route, vhostName string, secure bool, authService
 			rt.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"] = LocalRateLimitConfig(dagRoute.RateLimitPolicy.Local, "vhost."+vhostName)
 		}
 
+		if dagRoute.BufferPolicy != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig["envoy.filters.http.buffer"] = routeBuffer(dagRoute.BufferPolicy)
+		}
+
 		// If authorization is enabled on this host, we may need to set per-route filter overrides.
 		if authService != nil {
 			// Apply per-route authorization policy modifications.
@@ -124,6 +159,31 @@ func buildRoute(dagRoute *dag.Route, vhostName string, secure bool, authService
 	}
 }
 
+// routeBuffer returns a per-route config to buffer request bodies
+// up to the policy's maximum size.
+func routeBuffer(policy *dag.BufferPolicy) *any.Any {
+	return protobuf.MustMarshalAny(
+		&envoy_config_filter_http_buffer_v3.BufferPerRoute{
+			Override: &envoy_config_filter_http_buffer_v3.BufferPerRoute_Buffer{
+				Buffer: &envoy_config_filter_http_buffer_v3.Buffer{
+					MaxRequestBytes: protobuf.UInt32(policy.MaxRequestBytes),
+				},
+			},
+		},
+	)
+}
+
+// bufferDisabled returns a per-filter config to disable request buffering.
+func bufferDisabled() *any.Any {
+	return protobuf.MustMarshalAny(
+		&envoy_config_filter_http_buffer_v3.BufferPerRoute{
+			Override: &envoy_config_filter_http_buffer_v3.BufferPerRoute_Disabled{
+				Disabled: true,
+			},
+		},
+	)
+}
+
 // routeAuthzDisabled returns a per-route config to disable authorization.
 func routeAuthzDisabled() *any.Any {
 	return protobuf.MustMarshalAny(
@@ -263,6 +323,40 @@ func routeRedirect(redirect *dag.Redirect) *envoy_route_v3.Route_Redirect {
 	return r
 }
 
+// pathRewrite configures the path rewrite for the route on the supplied
+// RouteAction.
+func pathRewrite(r *dag.Route, ra *envoy_route_v3.RouteAction) {
+	switch {
+	case r.RegexRewrite != nil:
+		ra.RegexRewrite = &matcher.RegexMatchAndSubstitute{
+			Pattern:      SafeRegexMatch(r.RegexRewrite.Pattern),
+			Substitution: r.RegexRewrite.Substitution,
+		}
+	case len(r.FullPathRewrite) > 0:
+		ra.RegexRewrite = &matcher.RegexMatchAndSubstitute{
+			Pattern:      SafeRegexMatch("^/.*$"),
+			Substitution: r.FullPathRewrite,
+		}
+	case len(r.PrefixRewrite) > 0:
+		// Envoy swaps only the matched prefix, so a segment prefix
+		// match of "/foo" with a replacement ending in '/' would turn
+		// "/foo/bar" into "//bar". Consume the path separators after
+		// the prefix with a regex rewrite instead.
+		if prefix, ok := r.PathMatchCondition.(*dag.PrefixMatchCondition); ok &&
+			prefix.PrefixMatchType == dag.PrefixMatchSegment &&
+			prefix.Prefix != "/" &&
+			strings.HasSuffix(r.PrefixRewrite, "/") {
+			ra.RegexRewrite = &matcher.RegexMatchAndSubstitute{
+				Pattern:      SafeRegexMatch("^" + regexp.QuoteMeta(strings.TrimRight(prefix.Prefix, "/")) + "/*"),
+				Substitution: r.PrefixRewrite,
+			}
+			return
+		}
+
+		ra.PrefixRewrite = r.PrefixRewrite
+	}
+}
+
 // routeRoute creates a *envoy_route_v3.Route_Route for the services supplied.
 // If len(services) is greater than one, the route's action will be a
 // weighted cluster.
@@ -271,11 +365,12 @@ func routeRoute(r *dag.Route) *envoy_route_v3.Route_Route {
 		RetryPolicy:           retryPolicy(r),
 		Timeout:               envoy.Timeout(r.TimeoutPolicy.ResponseTimeout),
 		IdleTimeout:           envoy.Timeout(r.TimeoutPolicy.IdleStreamTimeout),
-		PrefixRewrite:         r.PrefixRewrite,
 		HashPolicy:            hashPolicy(r.RequestHashPolicies),
 		RequestMirrorPolicies: mirrorPolicy(r),
 	}
 
+	pathRewrite(r, &ra)
+
 	if r.RateLimitPolicy != nil && r.RateLimitPolicy.Global != nil {
 		ra.RateLimits = GlobalRateLimits(r.RateLimitPolicy.Global.Descriptors)
 	}
@@ -285,6 +380,10 @@ func routeRoute(r *dag.Route) *envoy_route_v3.Route_Route {
 		ra.HostRewriteSpecifier = &envoy_route_v3.RouteAction_HostRewriteLiteral{
 			HostRewriteLiteral: val,
 		}
+	} else if r.AutoHostRewrite {
+		ra.HostRewriteSpecifier = &envoy_route_v3.RouteAction_AutoHostRewrite{
+			AutoHostRewrite: protobuf.Bool(true),
+		}
 	}
 
 	if r.Websocket {
@@ -420,6 +519,14 @@ func headerValueList(hvm map[string]string, app bool) []*envoy_core_v3.HeaderVal
 	return hvs
 }
 
+// AltSvcHTTP3 returns the response header that advertises HTTP/3
+// support on the supplied UDP port.
+func AltSvcHTTP3(port int) []*envoy_core_v3.HeaderValueOption {
+	return headerValueList(map[string]string{
+		"alt-svc": fmt.Sprintf(`h3=":%d"; ma=86400`, port),
+	}, false)
+}
+
 // weightedClusters returns a route.WeightedCluster for multiple services.
 func weightedClusters(route *dag.Route) *envoy_route_v3.WeightedCluster {
 	var wc envoy_route_v3.WeightedCluster
@@ -434,6 +541,11 @@ func weightedClusters(route *dag.Route) *envoy_route_v3.WeightedCluster {
 		if cluster.RequestHeadersPolicy != nil {
 			c.RequestHeadersToAdd = append(headerValueList(cluster.RequestHeadersPolicy.Set, false), headerValueList(cluster.RequestHeadersPolicy.Add, true)...)
 			c.RequestHeadersToRemove = cluster.RequestHeadersPolicy.Remove
+			if val := envoy.HostReplaceHeader(cluster.RequestHeadersPolicy); val != "" {
+				c.HostRewriteSpecifier = &envoy_route_v3.WeightedCluster_ClusterWeight_HostRewriteLiteral{
+					HostRewriteLiteral: val,
+				}
+			}
 		}
 		if cluster.ResponseHeadersPolicy != nil {
 			c.ResponseHeadersToAdd = headerValueList(cluster.ResponseHeadersPolicy.Set, false)
@@ -469,6 +581,17 @@ func VirtualHost(hostname string, routes ...*envoy_route_v3.Route) *envoy_route_
 	}
 }
 
+// DisableBuffer disables request buffering on each of the virtual
+// hosts, so that only routes with a buffer policy buffer requests.
+func DisableBuffer(vhosts ...*envoy_route_v3.VirtualHost) {
+	for _, vh := range vhosts {
+		if vh.TypedPerFilterConfig == nil {
+			vh.TypedPerFilterConfig = map[string]*any.Any{}
+		}
+		vh.TypedPerFilterConfig["envoy.filters.http.buffer"] = bufferDisabled()
+	}
+}
+
 // CORSVirtualHost creates a new route.VirtualHost with a CORS policy.
 func CORSVirtualHost(hostname string, corspolicy *envoy_route_v3.CorsPolicy, routes ...*envoy_route_v3.Route) *envoy_route_v3.VirtualHost {
 	vh := VirtualHost(hostname, routes...)
@@ -554,10 +677,25 @@ func headerMatcher(headers []dag.HeaderMatchCondition) []*envoy_route_v3.HeaderM
 			header.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_StringMatch{
 				StringMatch: &matcher.StringMatcher{
 					MatchPattern: &matcher.StringMatcher_Exact{Exact: h.Value},
+					IgnoreCase:   h.IgnoreCase,
+				},
+			}
+		case dag.HeaderMatchTypePrefix:
+			header.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Prefix{Prefix: h.Value},
+					IgnoreCase:   h.IgnoreCase,
+				},
+			}
+		case dag.HeaderMatchTypeSuffix:
+			header.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Suffix{Suffix: h.Value},
+					IgnoreCase:   h.IgnoreCase,
 				},
 			}
 		case dag.HeaderMatchTypeContains:
-			header.HeaderMatchSpecifier = containsMatch(h.Value)
+			header.HeaderMatchSpecifier = containsMatch(h.Value, h.IgnoreCase)
 		case dag.HeaderMatchTypePresent:
 			header.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_PresentMatch{PresentMatch: true}
 		case dag.HeaderMatchTypeRegex:
@@ -582,12 +720,41 @@ func queryParamMatcher(queryParams []dag.QueryParamMatchCondition) []*envoy_rout
 			Name: q.Name,
 		}
 
-		if q.MatchType == dag.QueryParamMatchTypeExact {
+		switch q.MatchType {
+		case dag.QueryParamMatchTypeExact:
 			queryParam.QueryParameterMatchSpecifier = &envoy_route_v3.QueryParameterMatcher_StringMatch{
 				StringMatch: &matcher.StringMatcher{
 					MatchPattern: &matcher.StringMatcher_Exact{Exact: q.Value},
 				},
 			}
+		case dag.QueryParamMatchTypePrefix:
+			queryParam.QueryParameterMatchSpecifier = &envoy_route_v3.QueryParameterMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Prefix{Prefix: q.Value},
+				},
+			}
+		case dag.QueryParamMatchTypeSuffix:
+			queryParam.QueryParameterMatchSpecifier = &envoy_route_v3.QueryParameterMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Suffix{Suffix: q.Value},
+				},
+			}
+		case dag.QueryParamMatchTypeRegex:
+			queryParam.QueryParameterMatchSpecifier = &envoy_route_v3.QueryParameterMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_SafeRegex{
+						SafeRegex: SafeRegexMatch(q.Value),
+					},
+				},
+			}
+		case dag.QueryParamMatchTypeContains:
+			queryParam.QueryParameterMatchSpecifier = &envoy_route_v3.QueryParameterMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Contains{Contains: q.Value},
+				},
+			}
+		case dag.QueryParamMatchTypePresent:
+			queryParam.QueryParameterMatchSpecifier = &envoy_route_v3.QueryParameterMatcher_PresentMatch{PresentMatch: true}
 		}
 
 		envoyQueryParamMatchers = append(envoyQueryParamMatchers, queryParam)
@@ -597,12 +764,15 @@ func queryParamMatcher(queryParams []dag.QueryParamMatchCondition) []*envoy_rout
 }
 
 // containsMatch returns a HeaderMatchSpecifier which will match the
-// supplied substring
-func containsMatch(s string) *envoy_route_v3.HeaderMatcher_StringMatch {
+// supplied substring, optionally without regard to case.
+func containsMatch(s string, ignoreCase bool) *envoy_route_v3.HeaderMatcher_StringMatch {
 	// convert the substring s into a regular expression that matches s.
 	// note that Envoy expects the expression to match the entire string, not just the substring
 	// formed from s. see [projectcontour/contour/#1751 & envoyproxy/envoy#8283]
 	regex := fmt.Sprintf(".*%s.*", regexp.QuoteMeta(s))
+	if ignoreCase {
+		regex = "(?i)" + regex
+	}
 
 	return &envoy_route_v3.HeaderMatcher_StringMatch{
 		StringMatch: &matcher.StringMatcher{
@@ -587,6 +587,134 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"auto host rewrite": {
+			route: &dag.Route{
+				AutoHostRewrite: true,
+				Clusters:        []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					HostRewriteSpecifier: &envoy_route_v3.RouteAction_AutoHostRewrite{AutoHostRewrite: protobuf.Bool(true)},
+				},
+			},
+		},
+		"service host header rewrite": {
+			route: &dag.Route{
+				Clusters: []*dag.Cluster{{
+					Upstream: c1.Upstream,
+					Weight:   1,
+					RequestHeadersPolicy: &dag.HeadersPolicy{
+						HostRewrite: "external.com",
+					},
+				}},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_WeightedClusters{
+						WeightedClusters: &envoy_route_v3.WeightedCluster{
+							Clusters: []*envoy_route_v3.WeightedCluster_ClusterWeight{{
+								Name:   "default/kuard/8080/da39a3ee5e",
+								Weight: protobuf.UInt32(1),
+								HostRewriteSpecifier: &envoy_route_v3.WeightedCluster_ClusterWeight_HostRewriteLiteral{
+									HostRewriteLiteral: "external.com",
+								},
+							}},
+							TotalWeight: protobuf.UInt32(1),
+						},
+					},
+				},
+			},
+		},
+		"prefix rewrite": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.PrefixMatchCondition{Prefix: "/foo", PrefixMatchType: dag.PrefixMatchSegment},
+				PrefixRewrite:      "/bar",
+				Clusters:           []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					PrefixRewrite: "/bar",
+				},
+			},
+		},
+		"prefix rewrite with trailing slash on segment prefix": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.PrefixMatchCondition{Prefix: "/foo.bar", PrefixMatchType: dag.PrefixMatchSegment},
+				PrefixRewrite:      "/",
+				Clusters:           []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RegexRewrite: &matcher.RegexMatchAndSubstitute{
+						Pattern:      SafeRegexMatch(`^/foo\.bar/*`),
+						Substitution: "/",
+					},
+				},
+			},
+		},
+		"prefix rewrite with trailing slash on string prefix": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.PrefixMatchCondition{Prefix: "/foo/"},
+				PrefixRewrite:      "/",
+				Clusters:           []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					PrefixRewrite: "/",
+				},
+			},
+		},
+		"full path rewrite": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.ExactMatchCondition{Path: "/foo"},
+				FullPathRewrite:    "/bar",
+				Clusters:           []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RegexRewrite: &matcher.RegexMatchAndSubstitute{
+						Pattern:      SafeRegexMatch("^/.*$"),
+						Substitution: "/bar",
+					},
+				},
+			},
+		},
+		"regex rewrite": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.RegexMatchCondition{Regex: "/service/[^/]+/.*"},
+				RegexRewrite: &dag.RegexRewrite{
+					Pattern:      `^/service/([^/]+)(/.*)$`,
+					Substitution: `\2/instance/\1`,
+				},
+				Clusters: []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RegexRewrite: &matcher.RegexMatchAndSubstitute{
+						Pattern:      SafeRegexMatch(`^/service/([^/]+)(/.*)$`),
+						Substitution: `\2/instance/\1`,
+					},
+				},
+			},
+		},
 		"mirror": {
 			route: &dag.Route{
 				Clusters: []*dag.Cluster{{
@@ -1311,6 +1439,79 @@ func TestRouteMatch(t *testing.T) {
 				}},
 			},
 		},
+		"header prefix and suffix match ignoring case": {
+			route: &dag.Route{
+				HeaderMatchConditions: []dag.HeaderMatchCondition{{
+					Name:       "user-agent",
+					Value:      "Mozilla/",
+					MatchType:  "prefix",
+					IgnoreCase: true,
+				}, {
+					Name:      "x-tenant",
+					Value:     ".example.com",
+					MatchType: "suffix",
+				}},
+			},
+			want: &envoy_route_v3.RouteMatch{
+				Headers: []*envoy_route_v3.HeaderMatcher{{
+					Name: "user-agent",
+					HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_Prefix{
+								Prefix: "Mozilla/",
+							},
+							IgnoreCase: true,
+						},
+					},
+				}, {
+					Name: "x-tenant",
+					HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_Suffix{
+								Suffix: ".example.com",
+							},
+						},
+					},
+				}},
+			},
+		},
+		"header exact and contains match ignoring case": {
+			route: &dag.Route{
+				HeaderMatchConditions: []dag.HeaderMatchCondition{{
+					Name:       "x-header",
+					Value:      "abc",
+					MatchType:  "exact",
+					IgnoreCase: true,
+				}, {
+					Name:       "x-other-header",
+					Value:      "a.b",
+					MatchType:  "contains",
+					IgnoreCase: true,
+				}},
+			},
+			want: &envoy_route_v3.RouteMatch{
+				Headers: []*envoy_route_v3.HeaderMatcher{{
+					Name: "x-header",
+					HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_Exact{
+								Exact: "abc",
+							},
+							IgnoreCase: true,
+						},
+					},
+				}, {
+					Name: "x-other-header",
+					HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_SafeRegex{
+								SafeRegex: SafeRegexMatch("(?i).*a\\.b.*"),
+							},
+						},
+					},
+				}},
+			},
+		},
 		"query param exact match": {
 			route: &dag.Route{
 				QueryParamMatchConditions: []dag.QueryParamMatchCondition{
@@ -1336,6 +1537,96 @@ func TestRouteMatch(t *testing.T) {
 				},
 			},
 		},
+		"query param prefix, suffix, regex and contains match": {
+			route: &dag.Route{
+				QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+					{
+						Name:      "query-param-1",
+						Value:     "pre",
+						MatchType: "prefix",
+					},
+					{
+						Name:      "query-param-2",
+						Value:     "suf",
+						MatchType: "suffix",
+					},
+					{
+						Name:      "query-param-3",
+						Value:     "[a-z]+",
+						MatchType: "regex",
+					},
+					{
+						Name:      "query-param-4",
+						Value:     "sub",
+						MatchType: "contains",
+					},
+				},
+			},
+			want: &envoy_route_v3.RouteMatch{
+				QueryParameters: []*envoy_route_v3.QueryParameterMatcher{
+					{
+						Name: "query-param-1",
+						QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+							StringMatch: &matcher.StringMatcher{
+								MatchPattern: &matcher.StringMatcher_Prefix{
+									Prefix: "pre",
+								},
+							},
+						},
+					},
+					{
+						Name: "query-param-2",
+						QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+							StringMatch: &matcher.StringMatcher{
+								MatchPattern: &matcher.StringMatcher_Suffix{
+									Suffix: "suf",
+								},
+							},
+						},
+					},
+					{
+						Name: "query-param-3",
+						QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+							StringMatch: &matcher.StringMatcher{
+								MatchPattern: &matcher.StringMatcher_SafeRegex{
+									SafeRegex: SafeRegexMatch("[a-z]+"),
+								},
+							},
+						},
+					},
+					{
+						Name: "query-param-4",
+						QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+							StringMatch: &matcher.StringMatcher{
+								MatchPattern: &matcher.StringMatcher_Contains{
+									Contains: "sub",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"query param present match": {
+			route: &dag.Route{
+				QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+					{
+						Name:      "query-param-1",
+						MatchType: "present",
+					},
+				},
+			},
+			want: &envoy_route_v3.RouteMatch{
+				QueryParameters: []*envoy_route_v3.QueryParameterMatcher{
+					{
+						Name: "query-param-1",
+						QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_PresentMatch{
+							PresentMatch: true,
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -15,18 +15,23 @@ package v3
 
 import (
 	"testing"
+	"time"
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
 	"github.com/projectcontour/contour/internal/protobuf"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ocsp"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestSecret(t *testing.T) {
+	staple := fixture.OCSPResponse(fixture.CERTIFICATE, ocsp.Good, time.Now().Add(time.Hour))
+
 	tests := map[string]struct {
 		secret *dag.Secret
 		want   *envoy_tls_v3.Secret
@@ -62,6 +67,75 @@ func TestSecret(t *testing.T) {
 				},
 			},
 		},
+		"secret with OCSP response": {
+			secret: &dag.Secret{
+				Object: &v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Data: map[string][]byte{
+						v1.TLSCertKey:       []byte(fixture.CERTIFICATE),
+						v1.TLSPrivateKeyKey: []byte("key"),
+						dag.OCSPStapleKey:   staple,
+					},
+				},
+			},
+			want: &envoy_tls_v3.Secret{
+				Name: "default/simple/0567f551af",
+				Type: &envoy_tls_v3.Secret_TlsCertificate{
+					TlsCertificate: &envoy_tls_v3.TlsCertificate{
+						PrivateKey: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_InlineBytes{
+								InlineBytes: []byte("key"),
+							},
+						},
+						CertificateChain: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_InlineBytes{
+								InlineBytes: []byte(fixture.CERTIFICATE),
+							},
+						},
+						OcspStaple: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_InlineBytes{
+								InlineBytes: staple,
+							},
+						},
+					},
+				},
+			},
+		},
+		"secret with OCSP response for another certificate": {
+			secret: &dag.Secret{
+				Object: &v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Data: map[string][]byte{
+						v1.TLSCertKey:       []byte(fixture.CERTIFICATE),
+						v1.TLSPrivateKeyKey: []byte("key"),
+						dag.OCSPStapleKey:   fixture.OCSPResponse(fixture.CA_CERT, ocsp.Good, time.Now().Add(time.Hour)),
+					},
+				},
+			},
+			want: &envoy_tls_v3.Secret{
+				Name: "default/simple/0567f551af",
+				Type: &envoy_tls_v3.Secret_TlsCertificate{
+					TlsCertificate: &envoy_tls_v3.TlsCertificate{
+						PrivateKey: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_InlineBytes{
+								InlineBytes: []byte("key"),
+							},
+						},
+						CertificateChain: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_InlineBytes{
+								InlineBytes: []byte(fixture.CERTIFICATE),
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
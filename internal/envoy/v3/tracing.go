@@ -0,0 +1,101 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	envoy_trace_v3 "github.com/envoyproxy/go-control-plane/envoy/config/trace/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_tracing_v3 "github.com/envoyproxy/go-control-plane/envoy/type/tracing/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// EnvoyTracingConfig stores configuration for exporting
+// trace data to an OpenTelemetry collector.
+type EnvoyTracingConfig struct {
+	ExtensionService types.NamespacedName
+	SNI              string
+	Timeout          timeout.Setting
+	OverallSampling  float64
+	MaxPathTagLength uint32
+	CustomTags       []*CustomTag
+}
+
+// CustomTag defines a tag that is added to each span, with
+// either a literal value or the value of a request header.
+type CustomTag struct {
+	TagName           string
+	Literal           string
+	RequestHeaderName string
+}
+
+// TracingConfig returns the tracing configuration for an HTTP
+// connection manager, or nil if config is nil.
+func TracingConfig(config *EnvoyTracingConfig) *http.HttpConnectionManager_Tracing {
+	if config == nil {
+		return nil
+	}
+
+	var customTags []*envoy_tracing_v3.CustomTag
+	for _, tag := range config.CustomTags {
+		if customTag := customTag(tag); customTag != nil {
+			customTags = append(customTags, customTag)
+		}
+	}
+
+	return &http.HttpConnectionManager_Tracing{
+		OverallSampling: &envoy_type_v3.Percent{
+			Value: config.OverallSampling,
+		},
+		MaxPathTagLength: protobuf.UInt32OrNil(config.MaxPathTagLength),
+		CustomTags:       customTags,
+		Provider: &envoy_trace_v3.Tracing_Http{
+			Name: "envoy.tracers.opentelemetry",
+			ConfigType: &envoy_trace_v3.Tracing_Http_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_trace_v3.OpenTelemetryConfig{
+					GrpcService: GrpcService(dag.ExtensionClusterName(config.ExtensionService), config.SNI, config.Timeout),
+				}),
+			},
+		},
+	}
+}
+
+func customTag(tag *CustomTag) *envoy_tracing_v3.CustomTag {
+	switch {
+	case tag == nil:
+		return nil
+	case tag.Literal != "":
+		return &envoy_tracing_v3.CustomTag{
+			Tag: tag.TagName,
+			Type: &envoy_tracing_v3.CustomTag_Literal_{
+				Literal: &envoy_tracing_v3.CustomTag_Literal{
+					Value: tag.Literal,
+				},
+			},
+		}
+	case tag.RequestHeaderName != "":
+		return &envoy_tracing_v3.CustomTag{
+			Tag: tag.TagName,
+			Type: &envoy_tracing_v3.CustomTag_RequestHeader{
+				RequestHeader: &envoy_tracing_v3.CustomTag_Header{
+					Name: tag.RequestHeaderName,
+				},
+			},
+		}
+	}
+	return nil
+}
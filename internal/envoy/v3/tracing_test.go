@@ -0,0 +1,133 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_trace_v3 "github.com/envoyproxy/go-control-plane/envoy/config/trace/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_tracing_v3 "github.com/envoyproxy/go-control-plane/envoy/type/tracing/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+)
+
+func TestTracingConfig(t *testing.T) {
+	tests := map[string]struct {
+		cfg  *EnvoyTracingConfig
+		want *http.HttpConnectionManager_Tracing
+	}{
+		"nil config produces nil tracing": {
+			cfg:  nil,
+			want: nil,
+		},
+		"all fields configured": {
+			cfg: &EnvoyTracingConfig{
+				ExtensionService: k8s.NamespacedNameFrom("projectcontour/otel-collector"),
+				SNI:              "otel.example.com",
+				Timeout:          timeout.DurationSetting(5 * time.Second),
+				OverallSampling:  50,
+				MaxPathTagLength: 128,
+				CustomTags: []*CustomTag{
+					{
+						TagName: "literal",
+						Literal: "foo",
+					},
+					{
+						TagName:           "header",
+						RequestHeaderName: ":method",
+					},
+				},
+			},
+			want: &http.HttpConnectionManager_Tracing{
+				OverallSampling: &envoy_type_v3.Percent{
+					Value: 50,
+				},
+				MaxPathTagLength: protobuf.UInt32(128),
+				CustomTags: []*envoy_tracing_v3.CustomTag{
+					{
+						Tag: "literal",
+						Type: &envoy_tracing_v3.CustomTag_Literal_{
+							Literal: &envoy_tracing_v3.CustomTag_Literal{
+								Value: "foo",
+							},
+						},
+					},
+					{
+						Tag: "header",
+						Type: &envoy_tracing_v3.CustomTag_RequestHeader{
+							RequestHeader: &envoy_tracing_v3.CustomTag_Header{
+								Name: ":method",
+							},
+						},
+					},
+				},
+				Provider: &envoy_trace_v3.Tracing_Http{
+					Name: "envoy.tracers.opentelemetry",
+					ConfigType: &envoy_trace_v3.Tracing_Http_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&envoy_trace_v3.OpenTelemetryConfig{
+							GrpcService: &envoy_core_v3.GrpcService{
+								TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+									EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+										ClusterName: "extension/projectcontour/otel-collector",
+										Authority:   "otel.example.com",
+									},
+								},
+								Timeout: protobuf.Duration(5 * time.Second),
+							},
+						}),
+					},
+				},
+			},
+		},
+		"default timeout and no custom tags": {
+			cfg: &EnvoyTracingConfig{
+				ExtensionService: k8s.NamespacedNameFrom("projectcontour/otel-collector"),
+				OverallSampling:  100,
+				MaxPathTagLength: 256,
+			},
+			want: &http.HttpConnectionManager_Tracing{
+				OverallSampling: &envoy_type_v3.Percent{
+					Value: 100,
+				},
+				MaxPathTagLength: protobuf.UInt32(256),
+				Provider: &envoy_trace_v3.Tracing_Http{
+					Name: "envoy.tracers.opentelemetry",
+					ConfigType: &envoy_trace_v3.Tracing_Http_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&envoy_trace_v3.OpenTelemetryConfig{
+							GrpcService: &envoy_core_v3.GrpcService{
+								TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+									EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+										ClusterName: "extension/projectcontour/otel-collector",
+										Authority:   "extension.projectcontour.otel-collector",
+									},
+								},
+							},
+						}),
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, tc.want, TracingConfig(tc.cfg))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_header_to_metadata_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/header_to_metadata/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func accessLogPolicyConfig(name string) *any.Any {
+	keyValue := &envoy_header_to_metadata_v3.Config_KeyValuePair{
+		MetadataNamespace: "envoy.filters.http.header_to_metadata",
+		Key:               "access_log_policy",
+		Value:             name,
+	}
+	return protobuf.MustMarshalAny(&envoy_header_to_metadata_v3.Config{
+		RequestRules: []*envoy_header_to_metadata_v3.Config_Rule{{
+			Header:          ":path",
+			OnHeaderPresent: keyValue,
+			OnHeaderMissing: keyValue,
+		}},
+	})
+}
+
+func TestAccessLogPolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("s1").WithPorts(corev1.ServicePort{Port: 80}))
+	rh.OnAdd(fixture.NewService("s2").WithPorts(corev1.ServicePort{Port: 80}))
+
+	p := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "proxy1",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "foo.com",
+				AccessLog: &contour_api_v1.AccessLogPolicy{
+					Level: "error",
+				},
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Conditions: matchconditions(prefixMatchCondition("/payments")),
+					Services: []contour_api_v1.Service{
+						{
+							Name: "s1",
+							Port: 80,
+						},
+					},
+					AccessLog: &contour_api_v1.AccessLogPolicy{
+						Format: "json",
+						Level:  "info",
+					},
+				},
+				{
+					Services: []contour_api_v1.Service{
+						{
+							Name: "s2",
+							Port: 80,
+						},
+					},
+				},
+			},
+		},
+	}
+	rh.OnAdd(p)
+
+	vhostPolicy := envoy_v3.AccessLogPolicyName(&dag.AccessLogPolicy{
+		Level: contour_api_v1alpha1.LogLevelError,
+	})
+	routePolicy := envoy_v3.AccessLogPolicyName(&dag.AccessLogPolicy{
+		Format: contour_api_v1alpha1.JSONAccessLog,
+		Level:  contour_api_v1alpha1.LogLevelInfo,
+	})
+
+	vhost := envoy_v3.VirtualHost("foo.com",
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/payments"),
+			Action: routeCluster("default/s1/80/da39a3ee5e"),
+			TypedPerFilterConfig: map[string]*any.Any{
+				"envoy.filters.http.header_to_metadata": accessLogPolicyConfig(routePolicy),
+			},
+		},
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/s2/80/da39a3ee5e"),
+		},
+	)
+	vhost.TypedPerFilterConfig = map[string]*any.Any{
+		"envoy.filters.http.header_to_metadata": accessLogPolicyConfig(vhostPolicy),
+	}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http", vhost),
+		),
+	}).Status(p).IsValid()
+
+	// Policies are added to the access logs in name order.
+	policyLogs := map[string][]*envoy_accesslog_v3.AccessLog{
+		vhostPolicy: envoy_v3.AccessLogsForPolicy(vhostPolicy,
+			envoy_v3.FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelError)),
+		routePolicy: envoy_v3.AccessLogsForPolicy(routePolicy,
+			envoy_v3.FileAccessLogJSON("/dev/stdout", contour_api_v1alpha1.DefaultAccessLogJSONFields, nil, contour_api_v1alpha1.LogLevelInfo)),
+	}
+	first, second := vhostPolicy, routePolicy
+	if second < first {
+		first, second = second, first
+	}
+
+	accessLogs := envoy_v3.AccessLogsWithoutPolicy(envoy_v3.FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelInfo))
+	accessLogs = append(accessLogs, policyLogs[first]...)
+	accessLogs = append(accessLogs, policyLogs[second]...)
+
+	httpListener := defaultHTTPListener()
+	httpListener.FilterChains = envoy_v3.FilterChains(
+		envoy_v3.HTTPConnectionManagerBuilder().
+			RouteConfigName("ingress_http").
+			MetricsPrefix("ingress_http").
+			AccessLoggers(accessLogs).
+			DefaultFilters().
+			AddFilter(envoy_v3.FilterAccessLogPolicy()).
+			Get(),
+	)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			httpListener,
+			statsListener(),
+		),
+	})
+
+	// An invalid access log level is rejected.
+	invalid := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "proxy1",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "foo.com",
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Services: []contour_api_v1.Service{
+						{
+							Name: "s1",
+							Port: 80,
+						},
+					},
+					AccessLog: &contour_api_v1.AccessLogPolicy{
+						Level: "debug",
+					},
+				},
+			},
+		},
+	}
+	rh.OnUpdate(p, invalid)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeRouteError, "AccessLogPolicyNotValid", `route.accessLog is invalid: invalid access log level "debug"`)
+}
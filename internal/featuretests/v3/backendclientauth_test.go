@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
@@ -116,7 +117,7 @@ func TestBackendClientAuthenticationWithHTTPProxy(t *testing.T) {
 
 	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
 		Resources: resources(t,
-			tlsCluster(cluster("default/backend/443/950c17581f", "default/backend/http", "default_backend_443"), []byte(featuretests.CERTIFICATE), "subjname", "", sec1),
+			tlsCluster(cluster("default/backend/443/089ccb84d8", "default/backend/http", "default_backend_443"), []byte(featuretests.CERTIFICATE), "subjname", "", sec1),
 		),
 		TypeUrl: clusterType,
 	})
@@ -154,7 +155,7 @@ func TestBackendClientAuthenticationWithIngress(t *testing.T) {
 
 	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
 		Resources: resources(t,
-			tlsClusterWithoutValidation(cluster("default/backend/443/4929fca9d4", "default/backend/http", "default_backend_443"), "", sec1),
+			tlsClusterWithoutValidation(cluster("default/backend/443/1377c557b1", "default/backend/http", "default_backend_443"), "", sec1),
 		),
 		TypeUrl: clusterType,
 	})
@@ -229,3 +230,109 @@ func TestBackendClientAuthenticationWithExtensionService(t *testing.T) {
 		TypeUrl:   clusterType,
 	})
 }
+
+func TestBackendClientAuthenticationPerService(t *testing.T) {
+	sds := &dag.SecretDiscoveryService{
+		ExtensionService: types.NamespacedName{Namespace: "spire", Name: "spire-agent"},
+		SNI:              "spire.example.com",
+	}
+
+	rh, c, done := setup(t, proxyClientCertificateOpt(t), func(b *dag.Builder) {
+		for _, p := range b.Processors {
+			if p, ok := p.(*dag.HTTPProxyProcessor); ok {
+				p.SecretDiscoveryService = sds
+			}
+		}
+	})
+	defer done()
+
+	sec1 := clientSecret()
+	sec2 := caSecret()
+	sec3 := clientSecret()
+	sec3.Name = "backendclientsecret"
+	rh.OnAdd(sec1)
+	rh.OnAdd(sec2)
+	rh.OnAdd(sec3)
+
+	svc := fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 443})
+	rh.OnAdd(svc)
+
+	validation := &projcontour.UpstreamValidation{
+		CACertificate:   sec2.Name,
+		SubjectName:     "spiffe://cluster.local/ns/default/sa/backend",
+		SubjectNameType: projcontour.SubjectAltNameTypeURI,
+	}
+	peerValidation := &dag.PeerValidationContext{
+		CACertificate:   &dag.Secret{Object: sec2},
+		SubjectName:     "spiffe://cluster.local/ns/default/sa/backend",
+		SubjectNameType: projcontour.SubjectAltNameTypeURI,
+	}
+
+	// The service's client certificate overrides Contour's.
+	proxy := fixture.NewProxy("authenticated").WithSpec(
+		projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "www.example.com",
+			},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name:               svc.Name,
+					Port:               443,
+					Protocol:           pointer.StringPtr("tls"),
+					UpstreamValidation: validation,
+					ClientCertificate: &projcontour.ClientCertificate{
+						SecretName: sec3.Name,
+					},
+				}},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c1 := cluster("default/backend/443/b2fbb6ee57", "default/backend/http", "default_backend_443")
+	c1.TransportSocket = envoy_v3.UpstreamTLSTransportSocket(
+		envoy_v3.UpstreamTLSContext(peerValidation, "", &dag.Secret{Object: sec3}),
+	)
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t, c1),
+		TypeUrl:   clusterType,
+	}).Status(proxy).IsValid()
+
+	// The client certificate can be fetched from the secret
+	// discovery service instead.
+	proxySDS := proxy.DeepCopy()
+	proxySDS.Spec.Routes[0].Services[0].ClientCertificate = &projcontour.ClientCertificate{
+		SDSName: "spiffe://cluster.local/ns/projectcontour/sa/envoy",
+	}
+	rh.OnUpdate(proxy, proxySDS)
+
+	tlsContext := envoy_v3.UpstreamTLSContext(peerValidation, "", nil)
+	tlsContext.CommonTlsContext.TlsCertificateSdsSecretConfigs = []*envoy_tls_v3.SdsSecretConfig{
+		envoy_v3.SDSSecretConfig(&dag.SDSSecret{
+			Name:    "spiffe://cluster.local/ns/projectcontour/sa/envoy",
+			Service: sds,
+		}),
+	}
+	c2 := cluster("default/backend/443/d04ca4016c", "default/backend/http", "default_backend_443")
+	c2.TransportSocket = envoy_v3.UpstreamTLSTransportSocket(tlsContext)
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t, c2),
+		TypeUrl:   clusterType,
+	}).Status(proxySDS).IsValid()
+
+	// Only one source of client certificate may be given.
+	proxyInvalid := proxy.DeepCopy()
+	proxyInvalid.Spec.Routes[0].Services[0].ClientCertificate = &projcontour.ClientCertificate{
+		SecretName: sec3.Name,
+		SDSName:    "spiffe://cluster.local/ns/projectcontour/sa/envoy",
+	}
+	rh.OnUpdate(proxySDS, proxyInvalid)
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: nil,
+		TypeUrl:   clusterType,
+	}).Status(proxyInvalid).HasError(projcontour.ConditionTypeServiceError, "ClientCertificateNotValid",
+		`service "backend": exactly one of clientCertificate.secretName or clientCertificate.sdsName must be specified`)
+}
@@ -0,0 +1,200 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_config_filter_http_buffer_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRouteBufferPolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("s1").WithPorts(corev1.ServicePort{Port: 80}))
+	rh.OnAdd(fixture.NewService("s2").WithPorts(corev1.ServicePort{Port: 80}))
+
+	p := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "proxy1",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "foo.com",
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Conditions: matchconditions(prefixMatchCondition("/upload")),
+					Services: []contour_api_v1.Service{
+						{
+							Name: "s1",
+							Port: 80,
+						},
+					},
+					BufferPolicy: &contour_api_v1.BufferPolicy{
+						MaxRequestBytes: 8192,
+					},
+				},
+				{
+					Services: []contour_api_v1.Service{
+						{
+							Name: "s2",
+							Port: 80,
+						},
+					},
+				},
+			},
+		},
+	}
+	rh.OnAdd(p)
+
+	// A virtual host without a buffer policy on the same route
+	// configuration doesn't buffer requests either.
+	p2 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "proxy2",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "bar.com",
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Services: []contour_api_v1.Service{
+						{
+							Name: "s2",
+							Port: 80,
+						},
+					},
+				},
+			},
+		},
+	}
+	rh.OnAdd(p2)
+
+	bufferDisabled := withFilterConfig("envoy.filters.http.buffer",
+		&envoy_config_filter_http_buffer_v3.BufferPerRoute{
+			Override: &envoy_config_filter_http_buffer_v3.BufferPerRoute_Disabled{
+				Disabled: true,
+			},
+		})
+
+	bar := envoy_v3.VirtualHost("bar.com",
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/s2/80/da39a3ee5e"),
+		},
+	)
+	bar.TypedPerFilterConfig = bufferDisabled
+
+	foo := envoy_v3.VirtualHost("foo.com",
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/upload"),
+			Action: routeCluster("default/s1/80/da39a3ee5e"),
+			TypedPerFilterConfig: withFilterConfig("envoy.filters.http.buffer",
+				&envoy_config_filter_http_buffer_v3.BufferPerRoute{
+					Override: &envoy_config_filter_http_buffer_v3.BufferPerRoute_Buffer{
+						Buffer: &envoy_config_filter_http_buffer_v3.Buffer{
+							MaxRequestBytes: protobuf.UInt32(8192),
+						},
+					},
+				}),
+		},
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/s2/80/da39a3ee5e"),
+		},
+	)
+	foo.TypedPerFilterConfig = bufferDisabled
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http", bar, foo),
+		),
+	}).Status(p).IsValid()
+
+	httpListener := defaultHTTPListener()
+	httpListener.FilterChains = envoy_v3.FilterChains(
+		envoy_v3.HTTPConnectionManagerBuilder().
+			RouteConfigName("ingress_http").
+			MetricsPrefix("ingress_http").
+			AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo)).
+			DefaultFilters().
+			AddFilter(envoy_v3.FilterBuffer()).
+			Get(),
+	)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			httpListener,
+			statsListener(),
+		),
+	})
+
+	rh.OnDelete(p2)
+
+	// A zero maxRequestBytes is rejected.
+	invalid := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "proxy1",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "foo.com",
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Services: []contour_api_v1.Service{
+						{
+							Name: "s1",
+							Port: 80,
+						},
+					},
+					BufferPolicy: &contour_api_v1.BufferPolicy{},
+				},
+			},
+		},
+	}
+	rh.OnUpdate(p, invalid)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeRouteError, "BufferPolicyNotValid", "route.bufferPolicy is invalid: maxRequestBytes must be greater than zero")
+
+	// Without a buffer policy, the buffer filter isn't added.
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			statsListener(),
+		),
+	})
+}
@@ -0,0 +1,286 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"path"
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_rbac_filter_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func clientCertificateAuthorizationConfig(principals ...*matcher.StringMatcher) *any.Any {
+	rbac := &envoy_rbac_v3.Policy{
+		Permissions: []*envoy_rbac_v3.Permission{{
+			Rule: &envoy_rbac_v3.Permission_Any{Any: true},
+		}},
+	}
+	for _, p := range principals {
+		rbac.Principals = append(rbac.Principals, &envoy_rbac_v3.Principal{
+			Identifier: &envoy_rbac_v3.Principal_Authenticated_{
+				Authenticated: &envoy_rbac_v3.Principal_Authenticated{
+					PrincipalName: p,
+				},
+			},
+		})
+	}
+
+	return protobuf.MustMarshalAny(&envoy_rbac_filter_v3.RBACPerRoute{
+		Rbac: &envoy_rbac_filter_v3.RBAC{
+			Rules: &envoy_rbac_v3.RBAC{
+				Action: envoy_rbac_v3.RBAC_ALLOW,
+				Policies: map[string]*envoy_rbac_v3.Policy{
+					"client-certificate": rbac,
+				},
+			},
+		},
+	})
+}
+
+func TestClientCertificateAuthorization(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	serverTLSSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "serverTLSSecret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(serverTLSSecret)
+
+	clientCASecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "clientCASecret",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			dag.CACertificateKey: []byte(featuretests.CERTIFICATE),
+		},
+	}
+	rh.OnAdd(clientCASecret)
+
+	rh.OnAdd(fixture.NewService("kuard").WithPorts(v1.ServicePort{Port: 8080}))
+
+	proxy1 := fixture.NewProxy("example.com").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: serverTLSSecret.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: clientCASecret.Name,
+						SubjectAltNames: []contour_api_v1.SubjectAltNameMatcher{{
+							Type:   contour_api_v1.SubjectAltNameTypeURI,
+							Prefix: "spiffe://cluster.local/",
+						}},
+						CertificateHashes: []string{
+							"94:8F:E6:03:F6:1D:C0:36:B5:C5:96:DC:09:FE:3C:E3:F3:D3:0D:C9:0F:02:4C:85:F3:C8:2D:B2:CC:AB:67:9D",
+						},
+						Authorization: &contour_api_v1.ClientCertificateAuthorization{
+							Principals: []contour_api_v1.ClientCertificatePrincipal{{
+								Prefix: "spiffe://cluster.local/ns/default/",
+							}},
+						},
+						ForwardClientCertificate: &contour_api_v1.ClientCertificateDetails{
+							Subject: true,
+							URI:     true,
+						},
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{
+				{
+					Conditions: matchconditions(prefixMatchCondition("/admin")),
+					Services: []contour_api_v1.Service{{
+						Name: "kuard",
+						Port: 8080,
+					}},
+					ClientCertificateAuthorization: &contour_api_v1.ClientCertificateAuthorization{
+						Principals: []contour_api_v1.ClientCertificatePrincipal{{
+							Exact: "spiffe://cluster.local/ns/default/sa/admin",
+						}},
+					},
+				},
+				{
+					Services: []contour_api_v1.Service{{
+						Name: "kuard",
+						Port: 8080,
+					}},
+				},
+			},
+		})
+	rh.OnAdd(proxy1)
+
+	vhost := envoy_v3.VirtualHost("example.com",
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/admin"),
+			Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+			TypedPerFilterConfig: map[string]*any.Any{
+				"envoy.filters.http.rbac": clientCertificateAuthorizationConfig(&matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Exact{Exact: "spiffe://cluster.local/ns/default/sa/admin"},
+				}),
+			},
+		},
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+		},
+	)
+	vhost.TypedPerFilterConfig = map[string]*any.Any{
+		"envoy.filters.http.rbac": clientCertificateAuthorizationConfig(&matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Prefix{Prefix: "spiffe://cluster.local/ns/default/"},
+		}),
+	}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("https/example.com", vhost),
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("example.com",
+					upgradeHTTPS(routePrefix("/admin")),
+					upgradeHTTPS(routePrefix("/")),
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy1).IsValid()
+
+	ingressHTTPS := &envoy_listener_v3.Listener{
+		Name:    "ingress_https",
+		Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+		ListenerFilters: envoy_v3.ListenerFilters(
+			envoy_v3.TLSInspector(),
+		),
+		FilterChains: appendFilterChains(
+			filterchaintls("example.com", serverTLSSecret,
+				envoy_v3.HTTPConnectionManagerBuilder().
+					AddFilter(envoy_v3.FilterMisdirectedRequests("example.com")).
+					DefaultFilters().
+					AddFilter(envoy_v3.FilterClientCertificateAuthorization()).
+					RouteConfigName(path.Join("https", "example.com")).
+					MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+					AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout", "", nil, contour_api_v1alpha1.LogLevelInfo)).
+					ForwardClientCertificate(&dag.ClientCertificateDetails{
+						Subject: true,
+						URI:     true,
+					}).
+					Get(),
+				&dag.PeerValidationContext{
+					CACertificate: &dag.Secret{
+						Object: clientCASecret,
+					},
+					SubjectAltNames: []dag.SubjectAltNameMatcher{{
+						Type:      contour_api_v1.SubjectAltNameTypeURI,
+						MatchType: dag.StringMatchTypePrefix,
+						Value:     "spiffe://cluster.local/",
+					}},
+					CertificateHashes: []string{
+						"948fe603f61dc036b5c596dc09fe3ce3f3d30dc90f024c85f3c82db2ccab679d",
+					},
+				},
+				"h2", "http/1.1",
+			),
+		),
+		SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+	}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			defaultHTTPListener(),
+			ingressHTTPS,
+			statsListener(),
+		),
+		TypeUrl: listenerType,
+	})
+
+	// Route authorization requires client validation on the virtual host.
+	proxy2 := fixture.NewProxy("example.com").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: serverTLSSecret.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				ClientCertificateAuthorization: &contour_api_v1.ClientCertificateAuthorization{
+					Principals: []contour_api_v1.ClientCertificatePrincipal{{
+						Exact: "spiffe://cluster.local/ns/default/sa/admin",
+					}},
+				},
+			}},
+		})
+	rh.OnUpdate(proxy1, proxy2)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy2).HasError(contour_api_v1.ConditionTypeRouteError, "ClientCertificateAuthorizationNotValid",
+		"route.clientCertificateAuthorization requires client validation to be configured on the virtual host")
+
+	// Invalid certificate hashes are rejected.
+	proxy3 := fixture.NewProxy("example.com").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: serverTLSSecret.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate:     clientCASecret.Name,
+						CertificateHashes: []string{"not-a-hash"},
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnUpdate(proxy2, proxy3)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy3).HasError(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+		`Spec.VirtualHost.TLS client validation is invalid: invalid SHA-256 certificate hash "not-a-hash"`)
+}
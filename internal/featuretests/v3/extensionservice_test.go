@@ -220,6 +220,52 @@ func extUpstreamValidation(t *testing.T, rh cache.ResourceEventHandler, c *Conto
 			),
 		),
 	})
+
+	// The SNI server name is taken from an exact DNS subject alt name
+	// when there is no subject name.
+	rh.OnUpdate(ext, &v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("ns/ext"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "svc1", Port: 8081},
+			},
+			UpstreamValidation: &contour_api_v1.UpstreamValidation{
+				CACertificate: "otherNs/cacert",
+				SubjectAltNames: []contour_api_v1.SubjectAltNameMatcher{
+					{Exact: "ext.projectcontour.io"},
+				},
+			},
+		},
+	})
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			DefaultCluster(
+				h2cCluster(cluster("extension/ns/ext", "extension/ns/ext", "extension_ns_ext")),
+				&envoy_cluster_v3.Cluster{TransportSocket: tlsSocket},
+			),
+		),
+	})
+
+	// A URI subject name can't be used as the SNI server name.
+	rh.OnUpdate(ext, &v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("ns/ext"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "svc1", Port: 8081},
+			},
+			UpstreamValidation: &contour_api_v1.UpstreamValidation{
+				CACertificate:   "otherNs/cacert",
+				SubjectName:     "spiffe://cluster.local/ns/ns/sa/ext",
+				SubjectNameType: contour_api_v1.SubjectAltNameTypeURI,
+			},
+		},
+	})
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+	})
 }
 
 func extExternalName(_ *testing.T, rh cache.ResourceEventHandler, c *Contour) {
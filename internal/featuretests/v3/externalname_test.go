@@ -320,6 +320,169 @@ func TestExternalNameService(t *testing.T) {
 	})
 }
 
+func TestExternalNameServiceHostRewrite(t *testing.T) {
+	rh, c, done := setup(t, enableExternalNameService(t))
+	defer done()
+
+	s1 := fixture.NewService("kuard").
+		WithSpec(v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Port:       443,
+				TargetPort: intstr.FromInt(8443),
+			}},
+			ExternalName: "foo.io",
+			Type:         v1.ServiceTypeExternalName,
+		})
+	rh.OnAdd(s1)
+
+	// Rewriting the Host header to the upstream hostname
+	// also uses it as the SNI server name.
+	p1 := fixture.NewProxy("kuard").
+		WithFQDN("kuard.projectcontour.io").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Protocol: pointer.StringPtr("tls"),
+					Name:     s1.Name,
+					Port:     443,
+				}},
+				AutoHostRewrite: true,
+			}},
+		})
+	rh.OnAdd(p1)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("kuard.projectcontour.io",
+					&envoy_route_v3.Route{
+						Match: routePrefix("/"),
+						Action: &envoy_route_v3.Route_Route{
+							Route: &envoy_route_v3.RouteAction{
+								ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+									Cluster: "default/kuard/443/7d449598f5",
+								},
+								HostRewriteSpecifier: &envoy_route_v3.RouteAction_AutoHostRewrite{
+									AutoHostRewrite: protobuf.Bool(true),
+								},
+							},
+						},
+					},
+				),
+			),
+		),
+	}).Status(p1).IsValid()
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			DefaultCluster(
+				externalNameCluster("default/kuard/443/7d449598f5", "default/kuard", "default_kuard_443", "foo.io", 443),
+				&envoy_cluster_v3.Cluster{
+					TransportSocket: envoy_v3.UpstreamTLSTransportSocket(
+						envoy_v3.UpstreamTLSContext(nil, "foo.io", nil),
+					),
+				},
+			),
+		),
+	})
+
+	// A service hostRewrite overrides the Host header set by the route.
+	p2 := fixture.NewProxy("kuard").
+		WithFQDN("kuard.projectcontour.io").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Protocol:    pointer.StringPtr("tls"),
+					Name:        s1.Name,
+					Port:        443,
+					HostRewrite: true,
+				}},
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "Host",
+						Value: "external.address",
+					}},
+				},
+			}},
+		})
+	rh.OnUpdate(p1, p2)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("kuard.projectcontour.io",
+					&envoy_route_v3.Route{
+						Match: routePrefix("/"),
+						Action: &envoy_route_v3.Route_Route{
+							Route: &envoy_route_v3.RouteAction{
+								ClusterSpecifier: &envoy_route_v3.RouteAction_WeightedClusters{
+									WeightedClusters: &envoy_route_v3.WeightedCluster{
+										Clusters: []*envoy_route_v3.WeightedCluster_ClusterWeight{{
+											Name:   "default/kuard/443/7d449598f5",
+											Weight: protobuf.UInt32(1),
+											HostRewriteSpecifier: &envoy_route_v3.WeightedCluster_ClusterWeight_HostRewriteLiteral{
+												HostRewriteLiteral: "foo.io",
+											},
+										}},
+										TotalWeight: protobuf.UInt32(1),
+									},
+								},
+								HostRewriteSpecifier: &envoy_route_v3.RouteAction_HostRewriteLiteral{
+									HostRewriteLiteral: "external.address",
+								},
+							},
+						},
+					},
+				),
+			),
+		),
+	}).Status(p2).IsValid()
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			DefaultCluster(
+				externalNameCluster("default/kuard/443/7d449598f5", "default/kuard", "default_kuard_443", "foo.io", 443),
+				&envoy_cluster_v3.Cluster{
+					TransportSocket: envoy_v3.UpstreamTLSTransportSocket(
+						envoy_v3.UpstreamTLSContext(nil, "foo.io", nil),
+					),
+				},
+			),
+		),
+	})
+
+	// Rewriting the Host header to the upstream hostname
+	// requires an ExternalName service.
+	s2 := fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)})
+	rh.OnAdd(s2)
+
+	p3 := fixture.NewProxy("kuard").
+		WithFQDN("kuard.projectcontour.io").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name:        s2.Name,
+					Port:        80,
+					HostRewrite: true,
+				}},
+			}},
+		})
+	rh.OnUpdate(p2, p3)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+	}).Status(p3).HasError(contour_api_v1.ConditionTypeServiceError, "HostRewriteNotValid",
+		`service "backend": hostRewrite requires a service of type ExternalName`)
+}
+
 func enableExternalNameService(t *testing.T) func(*dag.Builder) {
 	return func(b *dag.Builder) {
 
@@ -18,6 +18,7 @@ import (
 
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/dag"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
@@ -478,3 +479,118 @@ func TestConditions_ContainsHeader_HTTProxy(t *testing.T) {
 		TypeUrl: routeType,
 	})
 }
+
+func TestConditions_RegexPrefixSuffixHeader_HTTProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("svc1").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}),
+	)
+
+	rh.OnAdd(fixture.NewService("svc2").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}),
+	)
+
+	proxy1 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "hello.world"},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "svc1",
+					Port: 80,
+				}},
+			}, {
+				Conditions: matchconditions(
+					prefixMatchCondition("/"),
+					contour_api_v1.MatchCondition{
+						Header: &contour_api_v1.HeaderMatchCondition{
+							Name:       "user-agent",
+							Prefix:     "curl/",
+							IgnoreCase: true,
+						},
+					},
+					contour_api_v1.MatchCondition{
+						Header: &contour_api_v1.HeaderMatchCondition{
+							Name:  "x-tenant",
+							Regex: "tenant-[0-9]+",
+						},
+					},
+				),
+				Services: []contour_api_v1.Service{{
+					Name: "svc2",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy1)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("hello.world",
+					&envoy_route_v3.Route{
+						Match: &envoy_route_v3.RouteMatch{
+							PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
+								Prefix: "/",
+							},
+							Headers: []*envoy_route_v3.HeaderMatcher{{
+								Name: "user-agent",
+								HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+									StringMatch: &matcher.StringMatcher{
+										MatchPattern: &matcher.StringMatcher_Prefix{Prefix: "curl/"},
+										IgnoreCase:   true,
+									},
+								},
+							}, {
+								Name: "x-tenant",
+								HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_StringMatch{
+									StringMatch: &matcher.StringMatcher{
+										MatchPattern: &matcher.StringMatcher_SafeRegex{
+											SafeRegex: envoy_v3.SafeRegexMatch("tenant-[0-9]+"),
+										},
+									},
+								},
+							}},
+						},
+						Action: routeCluster("default/svc2/80/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/svc1/80/da39a3ee5e"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy1).IsValid()
+
+	// Invalid regular expressions are rejected.
+	proxy2 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "hello.world"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(
+					contour_api_v1.MatchCondition{
+						Header: &contour_api_v1.HeaderMatchCondition{
+							Name:  "x-tenant",
+							Regex: "tenant-[0-9",
+						},
+					},
+				),
+				Services: []contour_api_v1.Service{{
+					Name: "svc2",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnUpdate(proxy1, proxy2)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy2).HasError(contour_api_v1.ConditionTypeRouteError, "HeaderMatchConditionsNotValid",
+		"invalid header regex condition \"tenant-[0-9\": error parsing regexp: missing closing ]: `[0-9`")
+}
@@ -0,0 +1,124 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func withRegexRewrite(route *envoy_route_v3.Route_Route, pattern, substitution string) *envoy_route_v3.Route_Route {
+	route.Route.RegexRewrite = &matcher.RegexMatchAndSubstitute{
+		Pattern:      envoy_v3.SafeRegexMatch(pattern),
+		Substitution: substitution,
+	}
+	return route
+}
+
+func TestHTTPProxyPathRewrite(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	proxy := fixture.NewProxy("kuard").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "kuard.projectcontour.io",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/service")),
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				PathRewritePolicy: &contour_api_v1.PathRewritePolicy{
+					RegexRewrite: &contour_api_v1.RegexRewrite{
+						Pattern:      `^/service/([^/]+)(/.*)$`,
+						Substitution: `\2/instance/\1`,
+					},
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/healthz")),
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				PathRewritePolicy: &contour_api_v1.PathRewritePolicy{
+					ReplaceFullPath: "/status",
+				},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("kuard.projectcontour.io",
+					&envoy_route_v3.Route{
+						Match: routePrefix("/service"),
+						Action: withRegexRewrite(routeCluster("default/kuard/8080/da39a3ee5e"),
+							`^/service/([^/]+)(/.*)$`, `\2/instance/\1`),
+					},
+					&envoy_route_v3.Route{
+						Match: routePrefix("/healthz"),
+						Action: withRegexRewrite(routeCluster("default/kuard/8080/da39a3ee5e"),
+							"^/.*$", "/status"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy).IsValid()
+
+	// Only one kind of path rewrite may be specified.
+	proxy = update(rh, proxy,
+		func(proxy *contour_api_v1.HTTPProxy) {
+			proxy.Spec.Routes[1].PathRewritePolicy.ReplacePrefix = []contour_api_v1.ReplacePrefix{{
+				Replacement: "/status",
+			}}
+		})
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy).HasError(contour_api_v1.ConditionTypeRouteError, "PathRewritePolicyNotValid",
+		"route.pathRewritePolicy must specify only one of replacePrefix, regexRewrite or replaceFullPath")
+
+	// The substitution may only refer to capture groups in the pattern.
+	proxy = update(rh, proxy,
+		func(proxy *contour_api_v1.HTTPProxy) {
+			proxy.Spec.Routes[1].PathRewritePolicy.ReplacePrefix = nil
+			proxy.Spec.Routes[0].PathRewritePolicy.RegexRewrite.Substitution = `\3/instance/\1`
+		})
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy).HasError(contour_api_v1.ConditionTypeRouteError, "PathRewritePolicyNotValid",
+		`route.pathRewritePolicy is invalid: invalid regexRewrite.substitution "\\3/instance/\\1": refers to capture group 3 but the pattern has 2`)
+}
@@ -0,0 +1,221 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestConditions_QueryParameter_HTTPProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("svc1").
+		WithPorts(v1.ServicePort{Port: 80}))
+	rh.OnAdd(fixture.NewService("svc2").
+		WithPorts(v1.ServicePort{Port: 80}))
+
+	child := fixture.NewProxy("child").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(contour_api_v1.MatchCondition{
+					QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+						Name:    "debug",
+						Present: true,
+					},
+				}),
+				Services: []contour_api_v1.Service{{
+					Name: "svc2",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(child)
+
+	proxy1 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "hello.world"},
+			Includes: []contour_api_v1.Include{{
+				Name: child.Name,
+				Conditions: matchconditions(
+					prefixMatchCondition("/api"),
+					contour_api_v1.MatchCondition{
+						QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+							Name:   "version",
+							Prefix: "v1",
+						},
+					},
+				),
+			}},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "svc1",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy1)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("hello.world",
+					&envoy_route_v3.Route{
+						Match: &envoy_route_v3.RouteMatch{
+							PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
+								Prefix: "/api",
+							},
+							QueryParameters: []*envoy_route_v3.QueryParameterMatcher{{
+								Name: "version",
+								QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+									StringMatch: &matcher.StringMatcher{
+										MatchPattern: &matcher.StringMatcher_Prefix{Prefix: "v1"},
+									},
+								},
+							}, {
+								Name: "debug",
+								QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_PresentMatch{
+									PresentMatch: true,
+								},
+							}},
+						},
+						Action: routeCluster("default/svc2/80/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/svc1/80/da39a3ee5e"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy1).IsValid()
+
+	// A query parameter condition must specify exactly one match type.
+	proxy2 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "hello.world"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(contour_api_v1.MatchCondition{
+					QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+						Name:  "version",
+						Exact: "v1",
+						Regex: "v[0-9]+",
+					},
+				}),
+				Services: []contour_api_v1.Service{{
+					Name: "svc1",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnUpdate(proxy1, proxy2)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy2).HasError(contour_api_v1.ConditionTypeRouteError, "QueryParameterMatchConditionsNotValid",
+		"route: a query parameter condition must specify exactly one of exact, prefix, suffix, regex, contains or present")
+}
+
+func TestConditions_QueryParameter_MatchTypeOrder_HTTPProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("svc1").
+		WithPorts(v1.ServicePort{Port: 80}))
+	rh.OnAdd(fixture.NewService("svc2").
+		WithPorts(v1.ServicePort{Port: 80}))
+
+	// Routes that only differ by the query parameter match type
+	// are ordered from the most to the least specific match.
+	proxy1 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "hello.world"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(contour_api_v1.MatchCondition{
+					QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+						Name:    "v",
+						Present: true,
+					},
+				}),
+				Services: []contour_api_v1.Service{{
+					Name: "svc1",
+					Port: 80,
+				}},
+			}, {
+				Conditions: matchconditions(contour_api_v1.MatchCondition{
+					QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+						Name:  "v",
+						Exact: "2",
+					},
+				}),
+				Services: []contour_api_v1.Service{{
+					Name: "svc2",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy1)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("hello.world",
+					&envoy_route_v3.Route{
+						Match: &envoy_route_v3.RouteMatch{
+							PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
+								Prefix: "/",
+							},
+							QueryParameters: []*envoy_route_v3.QueryParameterMatcher{{
+								Name: "v",
+								QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+									StringMatch: &matcher.StringMatcher{
+										MatchPattern: &matcher.StringMatcher_Exact{Exact: "2"},
+									},
+								},
+							}},
+						},
+						Action: routeCluster("default/svc2/80/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match: &envoy_route_v3.RouteMatch{
+							PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
+								Prefix: "/",
+							},
+							QueryParameters: []*envoy_route_v3.QueryParameterMatcher{{
+								Name: "v",
+								QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_PresentMatch{
+									PresentMatch: true,
+								},
+							}},
+						},
+						Action: routeCluster("default/svc1/80/da39a3ee5e"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(proxy1).IsValid()
+}
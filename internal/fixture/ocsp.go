@@ -0,0 +1,55 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixture
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// OCSPResponse returns a DER encoded OCSP response, signed by the
+// fixture CA, that reports status for the first certificate in certPEM.
+func OCSPResponse(certPEM string, status int, nextUpdate time.Time) []byte {
+	parse := func(data string) *x509.Certificate {
+		block, _ := pem.Decode([]byte(data))
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			panic(err)
+		}
+		return cert
+	}
+
+	block, _ := pem.Decode([]byte(CA_KEY))
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		panic(err)
+	}
+
+	issuer := parse(CA_CERT)
+	resp, err := ocsp.CreateResponse(issuer, issuer, ocsp.Response{
+		Status:       status,
+		SerialNumber: parse(certPEM).SerialNumber,
+		ThisUpdate:   nextUpdate.Add(-2 * time.Hour),
+		NextUpdate:   nextUpdate,
+		RevokedAt:    nextUpdate.Add(-3 * time.Hour),
+	}, key.(crypto.Signer))
+	if err != nil {
+		panic(err)
+	}
+	return resp
+}
@@ -24,65 +24,189 @@ import (
 )
 
 type ValidateListenersResult struct {
-	InsecurePort int
-	SecurePort   int
+	// Ports is the set of distinct ports used by valid
+	// listeners, in the order they are first used.
+	Ports []ListenerPort
+
+	// ListenerPorts holds the port of each valid listener,
+	// indexed by listener name.
+	ListenerPorts map[gatewayapi_v1beta1.SectionName]ListenerPort
 
 	InvalidListenerConditions map[gatewayapi_v1beta1.SectionName]metav1.Condition
 }
 
+const (
+	// DefaultHTTPContainerPort is the port that Envoy's
+	// default HTTP listener binds to.
+	DefaultHTTPContainerPort = 8080
+
+	// DefaultHTTPSContainerPort is the port that Envoy's
+	// default HTTPS listener binds to.
+	DefaultHTTPSContainerPort = 8443
+
+	// DefaultMetricsContainerPort is the port that Envoy's
+	// stats and health listener binds to.
+	DefaultMetricsContainerPort = 8002
+
+	// DefaultAdminContainerPort is the port that Envoy's
+	// admin listener binds to.
+	DefaultAdminContainerPort = 9001
+)
+
+// DefaultReservedContainerPorts returns the Envoy ports that are used
+// by listeners other than Gateway listeners in a default deployment,
+// keyed by port, with a description of the listener using each.
+func DefaultReservedContainerPorts() map[int32]string {
+	return map[int32]string{
+		DefaultMetricsContainerPort: "stats and health",
+		DefaultAdminContainerPort:   "admin",
+	}
+}
+
+// ListenerPort is a port a Gateway listens on, along with
+// the port Envoy listens on inside its container.
+type ListenerPort struct {
+	// Name is a name for the port that is unique within the Gateway
+	// and can be used as a Service or container port name. Ports
+	// served by Envoy's default HTTP and HTTPS listeners are named
+	// "http" and "https", all others are named "<protocol>-<port>".
+	Name string
+
+	// Protocol is the kind of Envoy listener needed for
+	// the port: one of "http", "https" or "tcp". HTTPS and
+	// TLS listeners both use "https".
+	Protocol string
+
+	// Port is the Gateway listener's port.
+	Port int32
+
+	// ContainerPort is the port Envoy listens on. Ports below 1024
+	// are privileged, so they are offset by 8000 in the same way the
+	// default HTTP and HTTPS ports (80 and 443) map to Envoy's 8080
+	// and 8443.
+	ContainerPort int32
+}
+
+// listenerProtocol returns the ListenerPort protocol for a Gateway
+// listener protocol, or an empty string if it's not supported.
+func listenerProtocol(protocol gatewayapi_v1beta1.ProtocolType) string {
+	switch protocol {
+	case gatewayapi_v1beta1.HTTPProtocolType:
+		return "http"
+	case gatewayapi_v1beta1.HTTPSProtocolType, gatewayapi_v1beta1.TLSProtocolType:
+		return "https"
+	case gatewayapi_v1beta1.TCPProtocolType:
+		return "tcp"
+	default:
+		return ""
+	}
+}
+
+// NewListenerPort returns the ListenerPort for a listener with
+// the given protocol (one of "http", "https" or "tcp") and port.
+func NewListenerPort(protocol string, port int32) ListenerPort {
+	containerPort := port
+	if containerPort < 1024 {
+		containerPort += 8000
+	}
+
+	name := fmt.Sprintf("%s-%d", protocol, port)
+	switch {
+	case protocol == "http" && containerPort == DefaultHTTPContainerPort:
+		name = "http"
+	case protocol == "https" && containerPort == DefaultHTTPSContainerPort:
+		name = "https"
+	}
+
+	return ListenerPort{
+		Name:          name,
+		Protocol:      protocol,
+		Port:          port,
+		ContainerPort: containerPort,
+	}
+}
+
 // ValidateListeners validates protocols, ports and hostnames on a set of listeners.
 // It ensures that:
 //   - all protocols are supported
-//   - each listener group (grouped by protocol, with HTTPS & TLS going together) uses a single port
+//   - all listeners on a port use compatible protocols (HTTPS & TLS are compatible)
+//   - no two ports are served by the same Envoy port, so for example
+//     HTTPS listeners on ports 443 and 8443 can't be combined
+//   - no port is served by one of the reserved Envoy ports
+//   - each TCP listener uses a port that no other listener uses
 //   - listener hostnames are syntactically valid
-//   - hostnames within each listener group are unique
+//   - hostnames are unique among the listeners on each port
 //
-// It returns the insecure & secure ports to use, as well as conditions for all invalid listeners.
+// reservedPorts holds the Envoy ports used by listeners other than
+// Gateway listeners, keyed by port, with a description of the listener
+// using each.
+//
+// It returns the ports to use, as well as conditions for all invalid listeners.
 // If a listener is not in the "InvalidListenerConditions" map, it is assumed to be valid according
 // to the above rules.
-func ValidateListeners(listeners []gatewayapi_v1beta1.Listener) ValidateListenersResult {
+func ValidateListeners(listeners []gatewayapi_v1beta1.Listener, reservedPorts map[int32]string) ValidateListenersResult {
 	result := ValidateListenersResult{
+		ListenerPorts:             map[gatewayapi_v1beta1.SectionName]ListenerPort{},
 		InvalidListenerConditions: map[gatewayapi_v1beta1.SectionName]metav1.Condition{},
 	}
 
-	// All listeners with a protocol of "HTTP" must use the same port number
-	// Heuristic: the first port number encountered is allowed, any other listeners with a different port number are marked "Detached" with "PortUnavailable"
-	// All listeners with a protocol of "HTTP" using the one allowed port must have a unique hostname
-	// Any listener with a duplicate hostname is marked "Conflicted" with "HostnameConflict"
+	// The first listener on each port determines the protocol for the port.
+	// Any later listeners on the port with a different protocol are marked
+	// "Conflicted" with "ProtocolConflict".
+	// Each Envoy container port can only be used by one port. Any listener
+	// whose port maps to a container port already used by another port is
+	// marked "Detached" with "PortUnavailable".
+	// All listeners on a port must have a unique hostname. Any listener
+	// with a duplicate hostname is marked "Conflicted" with "HostnameConflict".
+
+	type portHostname struct {
+		port     gatewayapi_v1beta1.PortNumber
+		hostname string
+	}
 
 	var (
-		insecureHostnames = map[string]int{}
-		secureHostnames   = map[string]int{}
+		portProtocols  = map[gatewayapi_v1beta1.PortNumber]string{}
+		containerPorts = map[int32]gatewayapi_v1beta1.PortNumber{}
+		hostnames      = map[portHostname]int{}
+
+		// The first TCP listener seen for each port.
+		tcpListeners = map[gatewayapi_v1beta1.PortNumber]gatewayapi_v1beta1.SectionName{}
 	)
 
 	for _, listener := range listeners {
-		hostname := HostnameDeref(listener.Hostname)
+		protocol := listenerProtocol(listener.Protocol)
+		if protocol == "" {
+			continue
+		}
 
-		switch listener.Protocol {
-		case gatewayapi_v1beta1.HTTPProtocolType:
-			// Keep the first insecure listener port we see
-			if result.InsecurePort == 0 {
-				result.InsecurePort = int(listener.Port)
-			}
+		// Keep the first protocol we see for each port.
+		if _, ok := portProtocols[listener.Port]; !ok {
+			portProtocols[listener.Port] = protocol
+		}
+		if portProtocols[listener.Port] != protocol {
+			continue
+		}
 
-			// Count hostnames among insecure listeners with the "valid" port.
-			// For other insecure listeners with an "invalid" port, the
-			// "PortUnavailable" reason will take precedence.
-			if int(listener.Port) == result.InsecurePort {
-				insecureHostnames[hostname]++
-			}
-		case gatewayapi_v1beta1.HTTPSProtocolType, gatewayapi_v1beta1.TLSProtocolType:
-			// Keep the first secure listener port we see
-			if result.SecurePort == 0 {
-				result.SecurePort = int(listener.Port)
-			}
+		// Keep the first port we see for each container port. Reserved
+		// container ports are never used, so they don't count.
+		containerPort := NewListenerPort(protocol, int32(listener.Port)).ContainerPort
+		if reservedPorts[containerPort] != "" {
+			continue
+		}
+		if _, ok := containerPorts[containerPort]; !ok {
+			containerPorts[containerPort] = listener.Port
+		}
 
-			// Count hostnames among secure listeners with the "valid" port.
-			// For other secure listeners with an "invalid" port, the
-			// "PortUnavailable" reason will take precedence.
-			if int(listener.Port) == result.SecurePort {
-				secureHostnames[hostname]++
+		switch protocol {
+		case "tcp":
+			if _, ok := tcpListeners[listener.Port]; !ok {
+				tcpListeners[listener.Port] = listener.Name
 			}
+		default:
+			// Count hostnames among the listeners on each port
+			// that use the port's protocol. For other listeners
+			// the "ProtocolConflict" reason takes precedence.
+			hostnames[portHostname{port: listener.Port, hostname: HostnameDeref(listener.Hostname)}]++
 		}
 	}
 
@@ -100,54 +224,108 @@ func ValidateListeners(listeners []gatewayapi_v1beta1.Listener) ValidateListener
 			}
 		}
 
-		switch listener.Protocol {
-		case gatewayapi_v1beta1.HTTPProtocolType:
-			switch {
-			case int(listener.Port) != result.InsecurePort:
-				result.InvalidListenerConditions[listener.Name] = metav1.Condition{
-					Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
-					Status:  metav1.ConditionTrue,
-					Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
-					Message: "Only one HTTP port is supported",
-				}
-			case insecureHostnames[hostname] > 1:
-				result.InvalidListenerConditions[listener.Name] = metav1.Condition{
-					Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
-					Status:  metav1.ConditionTrue,
-					Reason:  string(gatewayapi_v1beta1.ListenerReasonHostnameConflict),
-					Message: "Hostname must be unique among HTTP listeners",
-				}
+		protocol := listenerProtocol(listener.Protocol)
+		if protocol == "" {
+			result.InvalidListenerConditions[listener.Name] = metav1.Condition{
+				Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+				Status:  metav1.ConditionTrue,
+				Reason:  string(gatewayapi_v1beta1.ListenerReasonUnsupportedProtocol),
+				Message: fmt.Sprintf("Listener protocol %q is unsupported, must be one of HTTP, HTTPS, TLS or TCP", listener.Protocol),
 			}
-		case gatewayapi_v1beta1.HTTPSProtocolType, gatewayapi_v1beta1.TLSProtocolType:
-			switch {
-			case int(listener.Port) != result.SecurePort:
-				result.InvalidListenerConditions[listener.Name] = metav1.Condition{
-					Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
-					Status:  metav1.ConditionTrue,
-					Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
-					Message: "Only one HTTPS/TLS port is supported",
-				}
-			case secureHostnames[hostname] > 1:
-				result.InvalidListenerConditions[listener.Name] = metav1.Condition{
-					Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
-					Status:  metav1.ConditionTrue,
-					Reason:  string(gatewayapi_v1beta1.ListenerReasonHostnameConflict),
-					Message: "Hostname must be unique among HTTPS/TLS listeners",
-				}
+			continue
+		}
+
+		port := NewListenerPort(protocol, int32(listener.Port))
+
+		switch {
+		case portProtocols[listener.Port] != protocol:
+			result.InvalidListenerConditions[listener.Name] = metav1.Condition{
+				Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
+				Status:  metav1.ConditionTrue,
+				Reason:  string(gatewayapi_v1beta1.ListenerReasonProtocolConflict),
+				Message: "All listeners for a given port must use a compatible protocol",
 			}
-		default:
+		case reservedPorts[port.ContainerPort] != "":
 			result.InvalidListenerConditions[listener.Name] = metav1.Condition{
 				Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
 				Status:  metav1.ConditionTrue,
-				Reason:  string(gatewayapi_v1beta1.ListenerReasonUnsupportedProtocol),
-				Message: fmt.Sprintf("Listener protocol %q is unsupported, must be one of HTTP, HTTPS or TLS", listener.Protocol),
+				Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+				Message: fmt.Sprintf("Port %d is served by Envoy port %d, which is reserved for the Envoy %s listener", listener.Port, port.ContainerPort, reservedPorts[port.ContainerPort]),
+			}
+		case containerPorts[port.ContainerPort] != listener.Port:
+			result.InvalidListenerConditions[listener.Name] = metav1.Condition{
+				Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+				Status:  metav1.ConditionTrue,
+				Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+				Message: fmt.Sprintf("Port %d is served by the same Envoy port as port %d", listener.Port, containerPorts[port.ContainerPort]),
+			}
+		case isReservedContainerPort(port):
+			result.InvalidListenerConditions[listener.Name] = metav1.Condition{
+				Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+				Status:  metav1.ConditionTrue,
+				Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+				Message: fmt.Sprintf("Port %d is served by Envoy port %d, which is reserved for the default HTTP and HTTPS listeners", listener.Port, port.ContainerPort),
+			}
+		case protocol == "tcp" && tcpListeners[listener.Port] != listener.Name:
+			result.InvalidListenerConditions[listener.Name] = metav1.Condition{
+				Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+				Status:  metav1.ConditionTrue,
+				Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+				Message: fmt.Sprintf("Port is already used by TCP listener %q", tcpListeners[listener.Port]),
+			}
+		case protocol == "http" && hostnames[portHostname{port: listener.Port, hostname: hostname}] > 1:
+			result.InvalidListenerConditions[listener.Name] = metav1.Condition{
+				Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
+				Status:  metav1.ConditionTrue,
+				Reason:  string(gatewayapi_v1beta1.ListenerReasonHostnameConflict),
+				Message: "Hostname must be unique among HTTP listeners",
+			}
+		case protocol == "https" && hostnames[portHostname{port: listener.Port, hostname: hostname}] > 1:
+			result.InvalidListenerConditions[listener.Name] = metav1.Condition{
+				Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
+				Status:  metav1.ConditionTrue,
+				Reason:  string(gatewayapi_v1beta1.ListenerReasonHostnameConflict),
+				Message: "Hostname must be unique among HTTPS/TLS listeners",
 			}
 		}
+
+		// Only report the port if the listener
+		// wasn't found to be invalid.
+		if _, ok := result.InvalidListenerConditions[listener.Name]; ok {
+			continue
+		}
+
+		result.ListenerPorts[listener.Name] = port
+		if !containsListenerPort(result.Ports, port) {
+			result.Ports = append(result.Ports, port)
+		}
 	}
 
 	return result
 }
 
+// isReservedContainerPort returns true if the port would be served by
+// the Envoy port of a default listener for a different protocol.
+func isReservedContainerPort(port ListenerPort) bool {
+	switch port.ContainerPort {
+	case DefaultHTTPContainerPort:
+		return port.Protocol != "http"
+	case DefaultHTTPSContainerPort:
+		return port.Protocol != "https"
+	default:
+		return false
+	}
+}
+
+func containsListenerPort(ports []ListenerPort, port ListenerPort) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
 // HostnameDeref returns the hostname as a string if it's not nil,
 // or an empty string otherwise.
 func HostnameDeref(hostname *gatewayapi_v1beta1.Hostname) string {
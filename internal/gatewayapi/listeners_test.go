@@ -56,12 +56,15 @@ func TestValidateListeners(t *testing.T) {
 		},
 	}
 
-	res := ValidateListeners(listeners)
-	assert.Equal(t, 80, res.InsecurePort)
+	res := ValidateListeners(listeners, DefaultReservedContainerPorts())
+	assert.Equal(t, []ListenerPort{
+		{Name: "http", Protocol: "http", Port: 80, ContainerPort: 8080},
+		{Name: "https", Protocol: "https", Port: 443, ContainerPort: 8443},
+	}, res.Ports)
 	assert.Empty(t, res.InvalidListenerConditions)
 
-	// One HTTP listener with an invalid port number, some
-	// non-HTTP listeners as well.
+	// One HTTP listener with a port that is served by the same
+	// Envoy port as another, some non-HTTP listeners as well.
 	listeners = []gatewayapi_v1beta1.Listener{
 		{
 			Name:     "listener-1",
@@ -94,19 +97,121 @@ func TestValidateListeners(t *testing.T) {
 		},
 	}
 
-	res = ValidateListeners(listeners)
-	assert.Equal(t, 80, res.InsecurePort)
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
+	assert.Equal(t, []ListenerPort{
+		{Name: "http", Protocol: "http", Port: 80, ContainerPort: 8080},
+		{Name: "https", Protocol: "https", Port: 443, ContainerPort: 8443},
+	}, res.Ports)
 	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
 		"listener-4": {
 			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
 			Status:  metav1.ConditionTrue,
 			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
-			Message: "Only one HTTP port is supported",
+			Message: "Port 8080 is served by the same Envoy port as port 80",
+		},
+	}, res.InvalidListenerConditions)
+
+	// HTTP listeners on multiple ports, each with
+	// a hostname that is unique on its port.
+	listeners = []gatewayapi_v1beta1.Listener{
+		{
+			Name:     "listener-1",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     80,
+			Hostname: ListenerHostname("local.projectcontour.io"),
+		},
+		{
+			Name:     "listener-2",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     81,
+			Hostname: ListenerHostname("local.projectcontour.io"),
+		},
+		{
+			Name:     "listener-3",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     8081,
+			Hostname: ListenerHostname("local.projectcontour.io"),
+		},
+		{
+			Name:     "listener-4",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     8081,
+			Hostname: ListenerHostname("*.projectcontour.io"),
+		},
+	}
+
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
+	assert.Equal(t, []ListenerPort{
+		{Name: "http", Protocol: "http", Port: 80, ContainerPort: 8080},
+		{Name: "http-81", Protocol: "http", Port: 81, ContainerPort: 8081},
+	}, res.Ports)
+	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]ListenerPort{
+		"listener-1": {Name: "http", Protocol: "http", Port: 80, ContainerPort: 8080},
+		"listener-2": {Name: "http-81", Protocol: "http", Port: 81, ContainerPort: 8081},
+	}, res.ListenerPorts)
+	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
+		"listener-3": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+			Message: "Port 8081 is served by the same Envoy port as port 81",
+		},
+		"listener-4": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+			Message: "Port 8081 is served by the same Envoy port as port 81",
+		},
+	}, res.InvalidListenerConditions)
+
+	// HTTP, HTTPS and TLS listeners on the same port.
+	listeners = []gatewayapi_v1beta1.Listener{
+		{
+			Name:     "listener-1",
+			Protocol: gatewayapi_v1beta1.HTTPSProtocolType,
+			Port:     9443,
+			Hostname: ListenerHostname("local.projectcontour.io"),
+		},
+		{
+			Name:     "listener-2",
+			Protocol: gatewayapi_v1beta1.TLSProtocolType,
+			Port:     9443,
+			Hostname: ListenerHostname("*.projectcontour.io"),
+		},
+		{
+			Name:     "listener-3",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     9443,
+			Hostname: ListenerHostname("local.envoyproxy.io"),
+		},
+		{
+			Name:     "listener-4",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     8443,
+		},
+	}
+
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
+	assert.Equal(t, []ListenerPort{
+		{Name: "https-9443", Protocol: "https", Port: 9443, ContainerPort: 9443},
+	}, res.Ports)
+	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
+		"listener-3": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonProtocolConflict),
+			Message: "All listeners for a given port must use a compatible protocol",
+		},
+		"listener-4": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+			Message: "Port 8443 is served by Envoy port 8443, which is reserved for the default HTTP and HTTPS listeners",
 		},
 	}, res.InvalidListenerConditions)
 
 	// Two HTTP listeners with the same hostname, some HTTP
-	// listeners with invalid port, some non-HTTP listeners as well.
+	// listeners with an unavailable port, some non-HTTP listeners as well.
 	listeners = []gatewayapi_v1beta1.Listener{
 		{
 			Name:     "listener-1",
@@ -134,7 +239,7 @@ func TestValidateListeners(t *testing.T) {
 		{
 			Name:     "listener-5",
 			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
-			Port:     8080, // invalid port
+			Port:     8080, // unavailable port
 			Hostname: ListenerHostname("local.envoyproxy.io"),
 		},
 		{
@@ -145,8 +250,7 @@ func TestValidateListeners(t *testing.T) {
 		},
 	}
 
-	res = ValidateListeners(listeners)
-	assert.Equal(t, 80, res.InsecurePort)
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
 	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
 		"listener-2": {
 			Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
@@ -164,7 +268,7 @@ func TestValidateListeners(t *testing.T) {
 			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
 			Status:  metav1.ConditionTrue,
 			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
-			Message: "Only one HTTP port is supported",
+			Message: "Port 8080 is served by the same Envoy port as port 80",
 		},
 	}, res.InvalidListenerConditions)
 
@@ -202,11 +306,15 @@ func TestValidateListeners(t *testing.T) {
 		},
 	}
 
-	res = ValidateListeners(listeners)
-	assert.Equal(t, 443, res.SecurePort)
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
+	assert.Equal(t, []ListenerPort{
+		{Name: "https", Protocol: "https", Port: 443, ContainerPort: 8443},
+		{Name: "http", Protocol: "http", Port: 80, ContainerPort: 8080},
+	}, res.Ports)
 	assert.Empty(t, res.InvalidListenerConditions)
 
-	// One HTTPS listener with an invalid port number, some
+	// One HTTPS listener with a port that is served by the
+	// same Envoy port as another, some
 	// non-HTTPS listeners as well.
 	listeners = []gatewayapi_v1beta1.Listener{
 		{
@@ -240,19 +348,18 @@ func TestValidateListeners(t *testing.T) {
 		},
 	}
 
-	res = ValidateListeners(listeners)
-	assert.Equal(t, 443, res.SecurePort)
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
 	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
 		"listener-4": {
 			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
 			Status:  metav1.ConditionTrue,
 			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
-			Message: "Only one HTTPS/TLS port is supported",
+			Message: "Port 8443 is served by the same Envoy port as port 443",
 		},
 	}, res.InvalidListenerConditions)
 
 	// Two HTTPS/TLS listeners with the same hostname, some HTTPS/TLS
-	// listeners with invalid port, some HTTP listeners as well.
+	// listeners with an unavailable port, some HTTP listeners as well.
 	listeners = []gatewayapi_v1beta1.Listener{
 		{
 			Name:     "listener-1",
@@ -280,7 +387,7 @@ func TestValidateListeners(t *testing.T) {
 		{
 			Name:     "listener-5",
 			Protocol: gatewayapi_v1beta1.HTTPSProtocolType,
-			Port:     8443, // invalid port
+			Port:     8443, // unavailable port
 			Hostname: ListenerHostname("local.envoyproxy.io"),
 		},
 		{
@@ -291,8 +398,7 @@ func TestValidateListeners(t *testing.T) {
 		},
 	}
 
-	res = ValidateListeners(listeners)
-	assert.Equal(t, 443, res.SecurePort)
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
 	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
 		"listener-2": {
 			Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
@@ -310,7 +416,7 @@ func TestValidateListeners(t *testing.T) {
 			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
 			Status:  metav1.ConditionTrue,
 			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
-			Message: "Only one HTTPS/TLS port is supported",
+			Message: "Port 8443 is served by the same Envoy port as port 443",
 		},
 	}, res.InvalidListenerConditions)
 
@@ -336,7 +442,7 @@ func TestValidateListeners(t *testing.T) {
 		},
 	}
 
-	res = ValidateListeners(listeners)
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
 	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
 		"listener-1": {
 			Type:    string(gatewayapi_v1beta1.ListenerConditionReady),
@@ -357,4 +463,116 @@ func TestValidateListeners(t *testing.T) {
 			Message: "invalid hostname \".invalid.$.\": [a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')]",
 		},
 	}, res.InvalidListenerConditions)
+
+	// TCP listeners on their own ports are valid, a TCP listener
+	// on an HTTP port or on another TCP listener's port is not.
+	listeners = []gatewayapi_v1beta1.Listener{
+		{
+			Name:     "listener-1",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     80,
+		},
+		{
+			Name:     "tcp-1",
+			Protocol: gatewayapi_v1beta1.TCPProtocolType,
+			Port:     22,
+		},
+		{
+			Name:     "tcp-2",
+			Protocol: gatewayapi_v1beta1.TCPProtocolType,
+			Port:     9000,
+		},
+		{
+			Name:     "tcp-3",
+			Protocol: gatewayapi_v1beta1.TCPProtocolType,
+			Port:     80,
+		},
+		{
+			Name:     "tcp-4",
+			Protocol: gatewayapi_v1beta1.TCPProtocolType,
+			Port:     9000,
+		},
+	}
+
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
+	assert.Equal(t, []ListenerPort{
+		{Name: "http", Protocol: "http", Port: 80, ContainerPort: 8080},
+		{Name: "tcp-22", Protocol: "tcp", Port: 22, ContainerPort: 8022},
+		{Name: "tcp-9000", Protocol: "tcp", Port: 9000, ContainerPort: 9000},
+	}, res.Ports)
+	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
+		"tcp-3": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionConflicted),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonProtocolConflict),
+			Message: "All listeners for a given port must use a compatible protocol",
+		},
+		"tcp-4": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+			Message: "Port is already used by TCP listener \"tcp-2\"",
+		},
+	}, res.InvalidListenerConditions)
+
+	// Listeners whose ports are served by the Envoy ports of
+	// the stats, health or admin listeners.
+	listeners = []gatewayapi_v1beta1.Listener{
+		{
+			Name:     "http-1",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     80,
+		},
+		{
+			Name:     "tcp-1",
+			Protocol: gatewayapi_v1beta1.TCPProtocolType,
+			Port:     2,
+		},
+		{
+			Name:     "tcp-2",
+			Protocol: gatewayapi_v1beta1.TCPProtocolType,
+			Port:     1001,
+		},
+		{
+			Name:     "http-2",
+			Protocol: gatewayapi_v1beta1.HTTPProtocolType,
+			Port:     8002,
+		},
+		{
+			Name:     "tcp-3",
+			Protocol: gatewayapi_v1beta1.TCPProtocolType,
+			Port:     9001,
+		},
+	}
+
+	res = ValidateListeners(listeners, DefaultReservedContainerPorts())
+	assert.Equal(t, []ListenerPort{
+		{Name: "http", Protocol: "http", Port: 80, ContainerPort: 8080},
+	}, res.Ports)
+	assert.Equal(t, map[gatewayapi_v1beta1.SectionName]metav1.Condition{
+		"tcp-1": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+			Message: "Port 2 is served by Envoy port 8002, which is reserved for the Envoy stats and health listener",
+		},
+		"tcp-2": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+			Message: "Port 1001 is served by Envoy port 9001, which is reserved for the Envoy admin listener",
+		},
+		"http-2": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+			Message: "Port 8002 is served by Envoy port 8002, which is reserved for the Envoy stats and health listener",
+		},
+		"tcp-3": {
+			Type:    string(gatewayapi_v1beta1.ListenerConditionDetached),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(gatewayapi_v1beta1.ListenerReasonPortUnavailable),
+			Message: "Port 9001 is served by Envoy port 9001, which is reserved for the Envoy admin listener",
+		},
+	}, res.InvalidListenerConditions)
 }
@@ -38,6 +38,9 @@ type Metrics struct {
 	CacheHandlerOnUpdateSummary prometheus.Summary
 	EventHandlerOperations      *prometheus.CounterVec
 
+	xdsTLSReloadTotal *prometheus.CounterVec
+	xdsTLSReloadGauge prometheus.Gauge
+
 	// Keep a local cache of metrics for comparison on updates
 	proxyMetricCache *RouteMetric
 }
@@ -69,6 +72,9 @@ const (
 	DAGRebuildTotal             = "contour_dagrebuild_total"
 	cacheHandlerOnUpdateSummary = "contour_cachehandler_onupdate_duration_seconds"
 	eventHandlerOperations      = "contour_eventhandler_operation_total"
+
+	XDSTLSReloadTotal = "contour_xds_tls_reload_total"
+	XDSTLSReloadGauge = "contour_xds_tls_reload_timestamp"
 )
 
 // NewMetrics creates a new set of metrics and registers them with
@@ -147,6 +153,19 @@ func NewMetrics(registry *prometheus.Registry) *Metrics {
 			},
 			[]string{"op", "kind"},
 		),
+		xdsTLSReloadTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: XDSTLSReloadTotal,
+				Help: "Total number of times the xDS server's TLS certificate, key and CA bundle have been reloaded, by result.",
+			},
+			[]string{"result"},
+		),
+		xdsTLSReloadGauge: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: XDSTLSReloadGauge,
+				Help: "Timestamp of the last successful reload of the xDS server's TLS certificate, key and CA bundle.",
+			},
+		),
 	}
 	m.buildInfoGauge.WithLabelValues(build.Branch, build.Sha, build.Version).Set(1)
 	m.register(registry)
@@ -166,6 +185,8 @@ func (m *Metrics) register(registry *prometheus.Registry) {
 		m.dagRebuildTotal,
 		m.CacheHandlerOnUpdateSummary,
 		m.EventHandlerOperations,
+		m.xdsTLSReloadTotal,
+		m.xdsTLSReloadGauge,
 	)
 }
 
@@ -189,6 +210,7 @@ func (m *Metrics) Zero() {
 	m.SetDAGLastRebuilt(time.Now())
 	m.SetHTTPProxyMetric(zeroes)
 	m.EventHandlerOperations.WithLabelValues("add", "Secret").Inc()
+	m.SetXDSTLSReload(time.Now(), nil)
 
 	prometheus.NewTimer(m.CacheHandlerOnUpdateSummary).ObserveDuration()
 }
@@ -203,6 +225,18 @@ func (m *Metrics) SetDAGRebuiltTotal() {
 	m.dagRebuildTotal.Inc()
 }
 
+// SetXDSTLSReload records the result of reloading the xDS server's
+// TLS credentials. If err is nil, ts is recorded as the time of the
+// last successful reload.
+func (m *Metrics) SetXDSTLSReload(ts time.Time, err error) {
+	if err != nil {
+		m.xdsTLSReloadTotal.WithLabelValues("failure").Inc()
+		return
+	}
+	m.xdsTLSReloadTotal.WithLabelValues("success").Inc()
+	m.xdsTLSReloadGauge.Set(float64(ts.Unix()))
+}
+
 // SetHTTPProxyMetric sets metric values for a set of HTTPProxies
 func (m *Metrics) SetHTTPProxyMetric(metrics RouteMetric) {
 	// Process metrics
@@ -14,6 +14,7 @@
 package metrics
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -75,6 +76,48 @@ func TestSetDAGLastRebuilt(t *testing.T) {
 	}
 }
 
+func TestSetXDSTLSReload(t *testing.T) {
+	r := prometheus.NewRegistry()
+	m := NewMetrics(r)
+
+	ts := time.Date(2009, 11, 17, 20, 34, 58, 651387237, time.UTC)
+	m.SetXDSTLSReload(ts, nil)
+	m.SetXDSTLSReload(ts.Add(time.Hour), errors.New("boom"))
+	m.SetXDSTLSReload(ts.Add(2*time.Hour), nil)
+
+	gathering, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string][]*io_prometheus_client.Metric{}
+	for _, mf := range gathering {
+		got[mf.GetName()] = mf.Metric
+	}
+
+	label := func(value string) []*io_prometheus_client.LabelPair {
+		name := "result"
+		return []*io_prometheus_client.LabelPair{{Name: &name, Value: &value}}
+	}
+	float := func(f float64) *float64 { return &f }
+
+	assert.Equal(t, []*io_prometheus_client.Metric{
+		{
+			Label:   label("failure"),
+			Counter: &io_prometheus_client.Counter{Value: float(1)},
+		},
+		{
+			Label:   label("success"),
+			Counter: &io_prometheus_client.Counter{Value: float(2)},
+		},
+	}, got[XDSTLSReloadTotal])
+
+	// The timestamp is only updated by successful reloads.
+	if assert.Len(t, got[XDSTLSReloadGauge], 1) {
+		assert.Equal(t, float64(ts.Add(2*time.Hour).Unix()), got[XDSTLSReloadGauge][0].GetGauge().GetValue())
+	}
+}
+
 func TestWriteProxyMetric(t *testing.T) {
 	tests := map[string]struct {
 		proxyMetrics RouteMetric
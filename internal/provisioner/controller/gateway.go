@@ -207,22 +207,25 @@ func (r *gatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	// Validate listener ports and hostnames to get
 	// the ports to program.
-	validateListenersResult := gatewayapi.ValidateListeners(gateway.Spec.Listeners)
-
-	if validateListenersResult.InsecurePort > 0 {
-		port := model.ServicePort{
-			Name:       "http",
-			PortNumber: int32(validateListenersResult.InsecurePort),
-		}
-		contourModel.Spec.NetworkPublishing.Envoy.ServicePorts = append(contourModel.Spec.NetworkPublishing.Envoy.ServicePorts, port)
-	}
-	if validateListenersResult.SecurePort > 0 {
-		port := model.ServicePort{
-			Name:       "https",
-			PortNumber: int32(validateListenersResult.SecurePort),
+	validateListenersResult := gatewayapi.ValidateListeners(gateway.Spec.Listeners, gatewayapi.DefaultReservedContainerPorts())
+
+	for _, port := range validateListenersResult.Ports {
+		contourModel.Spec.NetworkPublishing.Envoy.ServicePorts = append(contourModel.Spec.NetworkPublishing.Envoy.ServicePorts, model.ServicePort{
+			Name:       port.Name,
+			PortNumber: port.Port,
+		})
+
+		// The "http" and "https" ports are served by Envoy's
+		// default listeners, whose container ports are always
+		// present. Every other port needs its own.
+		if port.Name == "http" || port.Name == "https" {
+			continue
 		}
 
-		contourModel.Spec.NetworkPublishing.Envoy.ServicePorts = append(contourModel.Spec.NetworkPublishing.Envoy.ServicePorts, port)
+		contourModel.Spec.NetworkPublishing.Envoy.ContainerPorts = append(contourModel.Spec.NetworkPublishing.Envoy.ContainerPorts, model.ContainerPort{
+			Name:       port.Name,
+			PortNumber: port.ContainerPort,
+		})
 	}
 
 	gatewayClassParams, err := r.getGatewayClassParams(ctx, gatewayClass)
@@ -563,13 +563,13 @@ func TestGatewayReconcile(t *testing.T) {
 							Port:     82,
 							Hostname: gatewayapi.ListenerHostname("foo.bar"),
 						},
-						// listener-3's port will be ignored because it's different than the previous HTTP listeners'
+						// listener-3 gets its own port
 						{
 							Name:     "listener-3",
 							Protocol: gatewayv1beta1.HTTPProtocolType,
 							Port:     80,
 						},
-						// listener-4 will be ignored because it's an unsupported protocol
+						// listener-4 will be ignored because its port is used by HTTP listeners
 						{
 							Name:     "listener-4",
 							Protocol: gatewayv1beta1.TCPProtocolType,
@@ -586,7 +586,7 @@ func TestGatewayReconcile(t *testing.T) {
 							Port:     8443,
 							Hostname: gatewayapi.ListenerHostname("foo.bar"),
 						},
-						// listener-7's port will be ignored because it's different than the previous HTTPS/TLS listeners'
+						// listener-7 gets its own port
 						{
 							Name:     "listener-7",
 							Protocol: gatewayv1beta1.HTTPSProtocolType,
@@ -607,11 +607,17 @@ func TestGatewayReconcile(t *testing.T) {
 				}
 				require.NoError(t, r.client.Get(context.Background(), keyFor(envoyService), envoyService))
 
-				require.Len(t, envoyService.Spec.Ports, 2)
+				require.Len(t, envoyService.Spec.Ports, 4)
 				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
-					Name:       "http",
+					Name:       "http-82",
 					Protocol:   corev1.ProtocolTCP,
 					Port:       82,
+					TargetPort: intstr.IntOrString{IntVal: 8082},
+				})
+				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
+					Name:       "http",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
 					TargetPort: intstr.IntOrString{IntVal: 8080},
 				})
 				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
@@ -620,6 +626,12 @@ func TestGatewayReconcile(t *testing.T) {
 					Port:       8443,
 					TargetPort: intstr.IntOrString{IntVal: 8443},
 				})
+				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
+					Name:       "https-8444",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       8444,
+					TargetPort: intstr.IntOrString{IntVal: 8444},
+				})
 			},
 		},
 		"The Envoy service's ports are derived from the Gateway's listeners (http only)": {
@@ -643,13 +655,13 @@ func TestGatewayReconcile(t *testing.T) {
 							Port:     82,
 							Hostname: gatewayapi.ListenerHostname("foo.bar"),
 						},
-						// listener-3's port will be ignored because it's different than the previous HTTP listeners'
+						// listener-3 gets its own port
 						{
 							Name:     "listener-3",
 							Protocol: gatewayv1beta1.HTTPProtocolType,
 							Port:     80,
 						},
-						// listener-4 will be ignored because it's an unsupported protocol
+						// listener-4 will be ignored because its port is used by HTTP listeners
 						{
 							Name:     "listener-4",
 							Protocol: gatewayv1beta1.TCPProtocolType,
@@ -669,13 +681,85 @@ func TestGatewayReconcile(t *testing.T) {
 				}
 				require.NoError(t, r.client.Get(context.Background(), keyFor(envoyService), envoyService))
 
-				require.Len(t, envoyService.Spec.Ports, 1)
+				require.Len(t, envoyService.Spec.Ports, 2)
 				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
-					Name:       "http",
+					Name:       "http-82",
 					Protocol:   corev1.ProtocolTCP,
 					Port:       82,
+					TargetPort: intstr.IntOrString{IntVal: 8082},
+				})
+				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
+					Name:       "http",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.IntOrString{IntVal: 8080},
+				})
+			},
+		},
+		"The Envoy service's ports are derived from the Gateway's listeners (tcp)": {
+			gatewayClass: reconcilableGatewayClass("gatewayclass-1", controller),
+			gateway: &gatewayv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "gateway-1",
+				},
+				Spec: gatewayv1beta1.GatewaySpec{
+					GatewayClassName: "gatewayclass-1",
+					Listeners: []gatewayv1beta1.Listener{
+						{
+							Name:     "listener-1",
+							Protocol: gatewayv1beta1.HTTPProtocolType,
+							Port:     80,
+						},
+						{
+							Name:     "listener-2",
+							Protocol: gatewayv1beta1.TCPProtocolType,
+							Port:     22,
+						},
+						{
+							Name:     "listener-3",
+							Protocol: gatewayv1beta1.TCPProtocolType,
+							Port:     9000,
+						},
+						// listener-4 will be ignored because its port is used by listener-3
+						{
+							Name:     "listener-4",
+							Protocol: gatewayv1beta1.TCPProtocolType,
+							Port:     9000,
+						},
+					},
+				},
+			},
+			assertions: func(t *testing.T, r *gatewayReconciler, gw *gatewayv1beta1.Gateway, reconcileErr error) {
+				require.NoError(t, reconcileErr)
+				// Get the expected Envoy service from the client.
+				envoyService := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: gw.Namespace,
+						Name:      "envoy-" + gw.Name,
+					},
+				}
+				require.NoError(t, r.client.Get(context.Background(), keyFor(envoyService), envoyService))
+
+				require.Len(t, envoyService.Spec.Ports, 3)
+				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
+					Name:       "http",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
 					TargetPort: intstr.IntOrString{IntVal: 8080},
 				})
+				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
+					Name:       "tcp-22",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       22,
+					TargetPort: intstr.IntOrString{IntVal: 8022},
+				})
+				assert.Contains(t, envoyService.Spec.Ports, corev1.ServicePort{
+					Name:       "tcp-9000",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       9000,
+					TargetPort: intstr.IntOrString{IntVal: 9000},
+				})
 			},
 		},
 		"If ContourDeployment.Spec.Contour.Replicas is not specified, the Contour deployment defaults to 2 replicas": {
@@ -74,16 +74,46 @@ func (s headerMatchConditionSorter) Less(i, j int) bool {
 				return compareValue(s[i], s[j])
 			case dag.HeaderMatchTypeRegex:
 				return true
+			case dag.HeaderMatchTypePrefix:
+				return true
+			case dag.HeaderMatchTypeSuffix:
+				return true
 			case dag.HeaderMatchTypeContains:
 				return true
 			case dag.HeaderMatchTypePresent:
 				return true
 			}
 		case dag.HeaderMatchTypeRegex:
-			// Regex matches sort ahead of Contains matches.
+			// Regex matches sort ahead of Prefix matches.
 			switch s[j].MatchType {
 			case dag.HeaderMatchTypeRegex:
 				return compareValue(s[i], s[j])
+			case dag.HeaderMatchTypePrefix:
+				return true
+			case dag.HeaderMatchTypeSuffix:
+				return true
+			case dag.HeaderMatchTypeContains:
+				return true
+			case dag.HeaderMatchTypePresent:
+				return true
+			}
+		case dag.HeaderMatchTypePrefix:
+			// Prefix matches sort ahead of Suffix matches.
+			switch s[j].MatchType {
+			case dag.HeaderMatchTypePrefix:
+				return compareValue(s[i], s[j])
+			case dag.HeaderMatchTypeSuffix:
+				return true
+			case dag.HeaderMatchTypeContains:
+				return true
+			case dag.HeaderMatchTypePresent:
+				return true
+			}
+		case dag.HeaderMatchTypeSuffix:
+			// Suffix matches sort ahead of Contains matches.
+			switch s[j].MatchType {
+			case dag.HeaderMatchTypeSuffix:
+				return compareValue(s[i], s[j])
 			case dag.HeaderMatchTypeContains:
 				return true
 			case dag.HeaderMatchTypePresent:
@@ -107,6 +137,33 @@ func (s headerMatchConditionSorter) Less(i, j int) bool {
 	}
 }
 
+// queryParamMatchTypeOrder ranks query param match types from the
+// most to the least specific.
+var queryParamMatchTypeOrder = map[string]int{
+	dag.QueryParamMatchTypeExact:    0,
+	dag.QueryParamMatchTypeRegex:    1,
+	dag.QueryParamMatchTypePrefix:   2,
+	dag.QueryParamMatchTypeSuffix:   3,
+	dag.QueryParamMatchTypeContains: 4,
+	dag.QueryParamMatchTypePresent:  5,
+}
+
+// Sorts QueryParamMatchCondition objects, first by their matcher
+// conditions type, then by the query param name, then by value.
+type queryParamMatchConditionSorter []dag.QueryParamMatchCondition
+
+func (s queryParamMatchConditionSorter) Len() int      { return len(s) }
+func (s queryParamMatchConditionSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s queryParamMatchConditionSorter) Less(i, j int) bool {
+	if s[i].MatchType != s[j].MatchType {
+		return queryParamMatchTypeOrder[s[i].MatchType] < queryParamMatchTypeOrder[s[j].MatchType]
+	}
+	if s[i].Name != s[j].Name {
+		return s[i].Name < s[j].Name
+	}
+	return s[i].Value < s[j].Value
+}
+
 // longestRouteByHeaderAndQueryParamConditions compares the HeaderMatchConditions
 // and QueryParamMatchConditions slices for lhs and rhs and returns true if lhs is
 // longer.
@@ -129,10 +186,28 @@ func longestRouteByHeaderAndQueryParamConditions(lhs, rhs *dag.Route) bool {
 	}
 
 	// If there is no difference in the header match conditions, compare the length
-	// of the query param match conditions. Note that this is sufficient for implementing
-	// the Gateway API spec, but this may need to be enhanced if we add query param
-	// match support to HTTPProxy.
-	return len(lhs.QueryParamMatchConditions) > len(rhs.QueryParamMatchConditions)
+	// of the query param match conditions.
+	if len(lhs.QueryParamMatchConditions) != len(rhs.QueryParamMatchConditions) {
+		return len(lhs.QueryParamMatchConditions) > len(rhs.QueryParamMatchConditions)
+	}
+
+	// QueryParamMatchConditions are equal length: compare item by item
+	// so that routes always sort the same way.
+	params := make([]dag.QueryParamMatchCondition, 2)
+
+	for i := 0; i < len(lhs.QueryParamMatchConditions); i++ {
+		params[0] = lhs.QueryParamMatchConditions[i]
+		params[1] = rhs.QueryParamMatchConditions[i]
+
+		switch {
+		case queryParamMatchConditionSorter(params).Less(0, 1):
+			return true
+		case queryParamMatchConditionSorter(params).Less(1, 0):
+			return false
+		}
+	}
+
+	return false
 }
 
 // Sorts the given Route slice in place. Routes are ordered first by
@@ -294,6 +369,8 @@ func For(v interface{}) sort.Interface {
 		return routeSorter(v)
 	case []dag.HeaderMatchCondition:
 		return headerMatchConditionSorter(v)
+	case []dag.QueryParamMatchCondition:
+		return queryParamMatchConditionSorter(v)
 	case []*envoy_cluster_v3.Cluster:
 		return clusterSorter(v)
 	case []*envoy_endpoint_v3.ClusterLoadAssignment:
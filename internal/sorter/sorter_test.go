@@ -142,6 +142,22 @@ func containsHeader(name string, value string) dag.HeaderMatchCondition {
 	}
 }
 
+func prefixHeader(name string, value string) dag.HeaderMatchCondition {
+	return dag.HeaderMatchCondition{
+		Name:      name,
+		MatchType: dag.HeaderMatchTypePrefix,
+		Value:     value,
+	}
+}
+
+func suffixHeader(name string, value string) dag.HeaderMatchCondition {
+	return dag.HeaderMatchCondition{
+		Name:      name,
+		MatchType: dag.HeaderMatchTypeSuffix,
+		Value:     value,
+	}
+}
+
 func presentHeader(name string) dag.HeaderMatchCondition {
 	return dag.HeaderMatchCondition{
 		Name:      name,
@@ -317,6 +333,64 @@ func TestSortRoutesMostQueryParams(t *testing.T) {
 	assert.Equal(t, want, have)
 }
 
+func TestSortRoutesQueryParamMatchType(t *testing.T) {
+	want := []*dag.Route{
+		{
+			PathMatchCondition: matchExact("/"),
+			QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+				{Name: "query-param", Value: "2", MatchType: dag.QueryParamMatchTypeExact},
+			},
+		},
+		{
+			PathMatchCondition: matchExact("/"),
+			QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+				{Name: "query-param", Value: "1", MatchType: dag.QueryParamMatchTypePrefix},
+			},
+		},
+		{
+			PathMatchCondition: matchExact("/"),
+			QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+				{Name: "query-param", MatchType: dag.QueryParamMatchTypePresent},
+			},
+		},
+	}
+
+	have := shuffleRoutes(want)
+
+	sort.Stable(For(have))
+	assert.Equal(t, want, have)
+}
+
+func TestSortQueryParamMatchConditions(t *testing.T) {
+	// Conditions are ordered by type (in order: "exact", "regex",
+	// "prefix", "suffix", "contains", "present"), then by name and
+	// then by value.
+	want := []dag.QueryParamMatchCondition{
+		{Name: "a", Value: "1", MatchType: dag.QueryParamMatchTypeExact},
+		{Name: "a", Value: "2", MatchType: dag.QueryParamMatchTypeExact},
+		{Name: "b", Value: "1", MatchType: dag.QueryParamMatchTypeExact},
+		{Name: "a", Value: "a.*", MatchType: dag.QueryParamMatchTypeRegex},
+		{Name: "a", Value: "1", MatchType: dag.QueryParamMatchTypePrefix},
+		{Name: "a", Value: "1", MatchType: dag.QueryParamMatchTypeSuffix},
+		{Name: "a", Value: "1", MatchType: dag.QueryParamMatchTypeContains},
+		{Name: "a", MatchType: dag.QueryParamMatchTypePresent},
+	}
+
+	have := []dag.QueryParamMatchCondition{
+		want[7],
+		want[6],
+		want[4],
+		want[5],
+		want[0],
+		want[2],
+		want[3],
+		want[1],
+	}
+
+	sort.Stable(For(have))
+	assert.Equal(t, want, have)
+}
+
 func TestSortSecrets(t *testing.T) {
 	want := []*envoy_tls_v3.Secret{
 		{Name: "first"},
@@ -335,22 +409,26 @@ func TestSortSecrets(t *testing.T) {
 func TestSortHeaderMatchConditions(t *testing.T) {
 	want := []dag.HeaderMatchCondition{
 		// Note that if the header names are the same, we
-		// order by the type (in order: "exact", "regex", "contains",
-		// "present").
+		// order by the type (in order: "exact", "regex", "prefix",
+		// "suffix", "contains", "present").
 		presentHeader("ashort"),
 		exactHeader("header-name", "anything"),
 		regexHeader("header-name", "a.*regex"),
+		prefixHeader("header-name", "start"),
+		suffixHeader("header-name", "end"),
 		containsHeader("header-name", "something"),
 		presentHeader("header-name"),
 		exactHeader("long-header-name", "long-header-value"),
 	}
 
 	have := []dag.HeaderMatchCondition{
-		want[5],
+		want[7],
+		want[6],
 		want[4],
-		want[3],
+		want[5],
 		want[0],
 		want[2],
+		want[3],
 		want[1],
 	}
 
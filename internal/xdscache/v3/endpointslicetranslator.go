@@ -0,0 +1,610 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/golang/protobuf/proto"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/sorter"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// RecalculateEndpointSlices generates a slice of LocalityEndpoints
+// resources by matching the given service port to the given set of
+// discoveryv1.EndpointSlices belonging to a single Service. slices may
+// be empty, in which case, the result is nil.
+//
+// Endpoints that are ready are always used. If a Service has no ready
+// endpoints for the port, endpoints that are still serving while they
+// terminate are used instead, so that in-flight rollouts don't black
+// hole traffic.
+//
+// Endpoints are grouped into a LocalityEndpoints resource for each
+// topology zone they are in, and endpoints without a zone are grouped
+// into a single LocalityEndpoints without a locality. If the endpoint
+// has a topology aware hint, the hinted zone is used instead of the
+// zone the endpoint runs in, so that Envoy's zone aware load balancing
+// follows the traffic split chosen by the EndpointSlice controller.
+// Each LocalityEndpoints carries the given weight.
+func RecalculateEndpointSlices(port v1.ServicePort, weight uint32, slices map[string]*discoveryv1.EndpointSlice) []*LocalityEndpoints {
+	if len(slices) == 0 {
+		return nil
+	}
+
+	// Process the slices in a stable order so that the same
+	// set of slices always yields the same result.
+	names := make([]string, 0, len(slices))
+	for name := range slices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ready, terminating []endpointAddress
+	seen := map[endpointAddress]bool{}
+
+	for _, name := range names {
+		slice := slices[name]
+
+		// FQDN slices are not supported; the addresses
+		// are not IPs that Envoy can connect to directly.
+		if slice.AddressType == discoveryv1.AddressTypeFQDN {
+			continue
+		}
+
+		for _, p := range slice.Ports {
+			if p.Port == nil {
+				// A nil port means "all ports", which
+				// is not something we can load balance to.
+				continue
+			}
+
+			protocol := v1.ProtocolTCP
+			if p.Protocol != nil {
+				protocol = *p.Protocol
+			}
+			if port.Protocol != protocol && protocol != v1.ProtocolTCP {
+				// NOTE: we only support "TCP", which is the default.
+				continue
+			}
+
+			// If the port isn't named, it must be the
+			// only Service port, so it's a match by
+			// definition. Otherwise, only take endpoint
+			// ports that match the service port name.
+			var portName string
+			if p.Name != nil {
+				portName = *p.Name
+			}
+			if port.Name != "" && port.Name != portName {
+				continue
+			}
+
+			for _, ep := range slice.Endpoints {
+				// Consumers must only use the first address
+				// of an endpoint; the rest are considered
+				// to be fungible.
+				if len(ep.Addresses) == 0 {
+					continue
+				}
+
+				key := endpointAddress{ip: ep.Addresses[0], port: int(*p.Port)}
+
+				// The same endpoint may transiently appear
+				// in more than one slice; only take it once.
+				if seen[key] {
+					continue
+				}
+
+				addr := key
+				addr.zone = endpointZone(ep)
+
+				switch {
+				case endpointReady(ep.Conditions):
+					ready = append(ready, addr)
+					seen[key] = true
+				case endpointServingTerminating(ep.Conditions):
+					terminating = append(terminating, addr)
+					seen[key] = true
+				}
+			}
+		}
+	}
+
+	addresses := ready
+	if len(addresses) == 0 {
+		addresses = terminating
+	}
+
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].zone != addresses[j].zone {
+			return addresses[i].zone < addresses[j].zone
+		}
+		if addresses[i].ip == addresses[j].ip {
+			return addresses[i].port < addresses[j].port
+		}
+		return addresses[i].ip < addresses[j].ip
+	})
+
+	// Since addresses are sorted by zone, each
+	// zone's endpoints are contiguous.
+	var localities []*LocalityEndpoints
+	for start := 0; start < len(addresses); {
+		zone := addresses[start].zone
+
+		var lb []*LoadBalancingEndpoint
+		end := start
+		for ; end < len(addresses) && addresses[end].zone == zone; end++ {
+			lb = append(lb, envoy_v3.LBEndpoint(envoy_v3.SocketAddress(addresses[end].ip, addresses[end].port)))
+		}
+
+		// Users are allowed to set the load balancing weight
+		// to 0, which we reflect to Envoy as nil in order to
+		// assign no load to that locality.
+		locality := &LocalityEndpoints{
+			LbEndpoints:         lb,
+			LoadBalancingWeight: protobuf.UInt32OrNil(weight),
+		}
+		if zone != "" {
+			locality.Locality = &envoy_core_v3.Locality{Zone: zone}
+		}
+
+		localities = append(localities, locality)
+		start = end
+	}
+
+	return localities
+}
+
+// endpointAddress is an IP and port pair taken from an EndpointSlice,
+// along with the topology zone of the endpoint, if known.
+type endpointAddress struct {
+	ip   string
+	port int
+	zone string
+}
+
+// endpointZone returns the zone that the endpoint should be balanced
+// in. The first topology aware hint takes precedence over the zone the
+// endpoint actually runs in.
+func endpointZone(ep discoveryv1.Endpoint) string {
+	if ep.Hints != nil && len(ep.Hints.ForZones) > 0 {
+		return ep.Hints.ForZones[0].Name
+	}
+	if ep.Zone != nil {
+		return *ep.Zone
+	}
+	return ""
+}
+
+// endpointReady returns true if the endpoint conditions indicate
+// that it is ready. A nil ready condition is interpreted as ready.
+func endpointReady(c discoveryv1.EndpointConditions) bool {
+	return c.Ready == nil || *c.Ready
+}
+
+// endpointServingTerminating returns true if the endpoint conditions
+// indicate that it is terminating but is still able to serve traffic.
+func endpointServingTerminating(c discoveryv1.EndpointConditions) bool {
+	return c.Serving != nil && *c.Serving && c.Terminating != nil && *c.Terminating
+}
+
+// EndpointSliceCache is a cache of EndpointSlice and ServiceCluster objects.
+type EndpointSliceCache struct {
+	mu sync.Mutex // Protects all fields.
+
+	// Slice of stale clusters. A stale cluster is one that
+	// needs to be recalculated. Clusters can be added to the stale
+	// slice due to changes in EndpointSlices or due to a DAG rebuild.
+	stale []*dag.ServiceCluster
+
+	// Index of ServiceClusters. ServiceClusters are indexed
+	// by the name of their Kubernetes Services. This makes it
+	// easy to determine which EndpointSlices affect which ServiceCluster.
+	services map[types.NamespacedName][]*dag.ServiceCluster
+
+	// Cache of EndpointSlices, indexed by the name of the Service
+	// that owns them and then by the name of the EndpointSlice.
+	endpointSlices map[types.NamespacedName]map[string]*discoveryv1.EndpointSlice
+}
+
+// Recalculate regenerates all the ClusterLoadAssignments from the
+// cached EndpointSlices and stale ServiceClusters. A ClusterLoadAssignment
+// will be generated for every stale ServerCluster, however, if there
+// are no endpoints for the Services in the ServiceCluster, the
+// ClusterLoadAssignment will be empty.
+func (c *EndpointSliceCache) Recalculate() map[string]*envoy_endpoint_v3.ClusterLoadAssignment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	assignments := map[string]*envoy_endpoint_v3.ClusterLoadAssignment{}
+	for _, cluster := range c.stale {
+		// Clusters can be in the stale list multiple times;
+		// skip to avoid duplicate recalculations.
+		if _, ok := assignments[cluster.ClusterName]; ok {
+			continue
+		}
+
+		cla := envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: cluster.ClusterName,
+			Endpoints:   nil,
+			Policy:      nil,
+		}
+
+		// Look up each service, and if we have endpoints for that service,
+		// attach them as new LocalityEndpoints resources, one per zone.
+		for _, w := range cluster.Services {
+			n := types.NamespacedName{Namespace: w.ServiceNamespace, Name: w.ServiceName}
+			localities := RecalculateEndpointSlices(w.ServicePort, w.Weight, c.endpointSlices[n])
+			cla.Endpoints = append(cla.Endpoints, localities...)
+		}
+
+		assignments[cla.ClusterName] = &cla
+	}
+
+	c.stale = nil
+	return assignments
+}
+
+// SetClusters replaces the cache of ServiceCluster resources. All
+// the added clusters will be marked stale.
+func (c *EndpointSliceCache) SetClusters(clusters []*dag.ServiceCluster) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Keep a local index to start with so that errors don't cause
+	// partial failure.
+	serviceIndex := map[types.NamespacedName][]*dag.ServiceCluster{}
+
+	// Reindex the cluster so that we can find them by service name.
+	for _, cluster := range clusters {
+		if err := cluster.Validate(); err != nil {
+			return fmt.Errorf("invalid ServiceCluster %q: %w", cluster.ClusterName, err)
+		}
+
+		// Make sure service clusters with default weights are balanced.
+		cluster.Rebalance()
+
+		for _, s := range cluster.Services {
+			name := types.NamespacedName{
+				Namespace: s.ServiceNamespace,
+				Name:      s.ServiceName,
+			}
+
+			serviceIndex[name] = append(serviceIndex[name], cluster)
+		}
+	}
+
+	c.stale = clusters
+	c.services = serviceIndex
+
+	return nil
+}
+
+// UpdateEndpointSlice adds slice to the cache, or replaces it if it is
+// already cached. Any ServiceClusters that are backed by the Service
+// that slice belongs to become stale. Returns a boolean indicating
+// whether any ServiceClusters use slice or not.
+func (c *EndpointSliceCache) UpdateEndpointSlice(slice *discoveryv1.EndpointSlice) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name, ok := endpointSliceServiceName(slice)
+	if !ok {
+		return false
+	}
+
+	if c.endpointSlices[name] == nil {
+		c.endpointSlices[name] = map[string]*discoveryv1.EndpointSlice{}
+	}
+	c.endpointSlices[name][slice.Name] = slice.DeepCopy()
+
+	// If any service clusters include this slice, mark them
+	// all as stale.
+	if affected := c.services[name]; len(affected) > 0 {
+		c.stale = append(c.stale, affected...)
+		return true
+	}
+
+	return false
+}
+
+// DeleteEndpointSlice deletes slice from the cache. Any ServiceClusters
+// that are backed by the Service that slice belongs to become stale.
+// Returns a boolean indicating whether any ServiceClusters use slice
+// or not.
+func (c *EndpointSliceCache) DeleteEndpointSlice(slice *discoveryv1.EndpointSlice) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name, ok := endpointSliceServiceName(slice)
+	if !ok {
+		return false
+	}
+
+	delete(c.endpointSlices[name], slice.Name)
+	if len(c.endpointSlices[name]) == 0 {
+		delete(c.endpointSlices, name)
+	}
+
+	// If any service clusters include this slice, mark them
+	// all as stale.
+	if affected := c.services[name]; len(affected) > 0 {
+		c.stale = append(c.stale, affected...)
+		return true
+	}
+
+	return false
+}
+
+// endpointSliceServiceName returns the name of the Service that owns
+// the given EndpointSlice, as recorded by the service name label.
+func endpointSliceServiceName(slice *discoveryv1.EndpointSlice) (types.NamespacedName, bool) {
+	svc, ok := slice.Labels[discoveryv1.LabelServiceName]
+	if !ok || svc == "" {
+		return types.NamespacedName{}, false
+	}
+
+	return types.NamespacedName{Namespace: slice.Namespace, Name: svc}, true
+}
+
+// NewEndpointSliceTranslator allocates a new endpoint slice translator.
+func NewEndpointSliceTranslator(log logrus.FieldLogger) *EndpointSliceTranslator {
+	return &EndpointSliceTranslator{
+		Cond:        contour.Cond{},
+		FieldLogger: log,
+		entries:     map[string]*envoy_endpoint_v3.ClusterLoadAssignment{},
+		cache: EndpointSliceCache{
+			stale:          nil,
+			services:       map[types.NamespacedName][]*dag.ServiceCluster{},
+			endpointSlices: map[types.NamespacedName]map[string]*discoveryv1.EndpointSlice{},
+		},
+	}
+}
+
+// A EndpointSliceTranslator translates Kubernetes EndpointSlice objects
+// into Envoy ClusterLoadAssignment resources.
+type EndpointSliceTranslator struct {
+	// Observer notifies when the endpoint slice cache has been updated.
+	Observer contour.Observer
+
+	contour.Cond
+	logrus.FieldLogger
+
+	cache EndpointSliceCache
+
+	// localService is the Envoy Service whose endpoints are
+	// served as Envoy's local cluster, if any.
+	localService types.NamespacedName
+
+	mu      sync.Mutex // Protects entries.
+	entries map[string]*envoy_endpoint_v3.ClusterLoadAssignment
+}
+
+// SetObserver sets the observer to notify when the
+// endpoint slice cache has been updated.
+func (e *EndpointSliceTranslator) SetObserver(observer contour.Observer) {
+	e.Observer = observer
+}
+
+// SetLocalService sets the Service that fronts the Envoy pods. Its
+// endpoints are served under envoy.LocalClusterName so that Envoy
+// can compare its own zone with the zones of its peers when zone
+// aware routing is used.
+func (e *EndpointSliceTranslator) SetLocalService(service types.NamespacedName) {
+	e.localService = service
+}
+
+// Merge combines the given entries with the existing entries in the
+// EndpointSliceTranslator. If the same key exists in both maps, an
+// existing entry is replaced.
+func (e *EndpointSliceTranslator) Merge(entries map[string]*envoy_endpoint_v3.ClusterLoadAssignment) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for k, v := range entries {
+		e.entries[k] = v
+	}
+}
+
+// OnChange observes DAG rebuild events.
+func (e *EndpointSliceTranslator) OnChange(root *dag.DAG) {
+	clusters := []*dag.ServiceCluster{}
+	names := map[string]bool{}
+
+	for _, svc := range root.GetServiceClusters() {
+		if err := svc.Validate(); err != nil {
+			e.WithError(err).Errorf("dropping invalid service cluster %q", svc.ClusterName)
+		} else if _, ok := names[svc.ClusterName]; ok {
+			e.Debugf("dropping service cluster with duplicate name %q", svc.ClusterName)
+		} else {
+			e.Debugf("added ServiceCluster %q from DAG", svc.ClusterName)
+			clusters = append(clusters, svc.DeepCopy())
+			names[svc.ClusterName] = true
+		}
+	}
+
+	// The local cluster only needs the number of Envoy pods in each
+	// zone, so every TCP port of the Envoy Service is taken. Each pod
+	// is counted once per port, which leaves the zone ratios intact.
+	if e.localService.Name != "" && e.localService.Namespace != "" {
+		clusters = append(clusters, &dag.ServiceCluster{
+			ClusterName: envoy.LocalClusterName,
+			Services: []dag.WeightedService{{
+				Weight:           1,
+				ServiceName:      e.localService.Name,
+				ServiceNamespace: e.localService.Namespace,
+			}},
+		})
+	}
+
+	// Update the cache with the new clusters.
+	if err := e.cache.SetClusters(clusters); err != nil {
+		e.WithError(err).Error("failed to cache service clusters")
+	}
+
+	// After rebuilding the DAG, the service cluster could be
+	// completely different. Some could be added, and some could
+	// be removed. Since we reset the cluster cache above, all
+	// the load assignments will be recalculated and we can just
+	// set the entries rather than merging them.
+	entries := e.cache.Recalculate()
+
+	// Only update and notify if entries has changed.
+	changed := false
+
+	e.mu.Lock()
+	if !equal(e.entries, entries) {
+		e.entries = entries
+		changed = true
+	}
+	e.mu.Unlock()
+
+	if changed {
+		e.Debug("cluster load assignments changed, notifying waiters")
+		e.Notify()
+	} else {
+		e.Debug("cluster load assignments did not change")
+	}
+}
+
+func (e *EndpointSliceTranslator) OnAdd(obj interface{}) {
+	switch obj := obj.(type) {
+	case *discoveryv1.EndpointSlice:
+		if !e.cache.UpdateEndpointSlice(obj) {
+			return
+		}
+
+		e.WithField("endpointslice", k8s.NamespacedNameOf(obj)).Debug("EndpointSlice is in use by a ServiceCluster, recalculating ClusterLoadAssignments")
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
+	default:
+		e.Errorf("OnAdd unexpected type %T: %#v", obj, obj)
+	}
+}
+
+func (e *EndpointSliceTranslator) OnUpdate(oldObj, newObj interface{}) {
+	switch newObj := newObj.(type) {
+	case *discoveryv1.EndpointSlice:
+		oldObj, ok := oldObj.(*discoveryv1.EndpointSlice)
+		if !ok {
+			e.Errorf("OnUpdate endpointslice %#v received invalid oldObj %T; %#v", newObj, oldObj, oldObj)
+			return
+		}
+
+		// Skip computation if either old and new slices
+		// are equal (thus also handling nil).
+		if oldObj == newObj {
+			return
+		}
+
+		// If there are no endpoints in this object, and the old
+		// object also had zero endpoints, ignore this update
+		// to avoid sending a noop notification to watchers.
+		if len(oldObj.Endpoints) == 0 && len(newObj.Endpoints) == 0 {
+			return
+		}
+
+		if !e.cache.UpdateEndpointSlice(newObj) {
+			return
+		}
+
+		e.WithField("endpointslice", k8s.NamespacedNameOf(newObj)).Debug("EndpointSlice is in use by a ServiceCluster, recalculating ClusterLoadAssignments")
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
+	default:
+		e.Errorf("OnUpdate unexpected type %T: %#v", newObj, newObj)
+	}
+}
+
+func (e *EndpointSliceTranslator) OnDelete(obj interface{}) {
+	switch obj := obj.(type) {
+	case *discoveryv1.EndpointSlice:
+		if !e.cache.DeleteEndpointSlice(obj) {
+			return
+		}
+
+		e.WithField("endpointslice", k8s.NamespacedNameOf(obj)).Debug("EndpointSlice was in use by a ServiceCluster, recalculating ClusterLoadAssignments")
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
+	case cache.DeletedFinalStateUnknown:
+		e.OnDelete(obj.Obj) // recurse into ourselves with the tombstoned value
+	default:
+		e.Errorf("OnDelete unexpected type %T: %#v", obj, obj)
+	}
+}
+
+// Contents returns a copy of the contents of the cache.
+func (e *EndpointSliceTranslator) Contents() []proto.Message {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	values := make([]*envoy_endpoint_v3.ClusterLoadAssignment, 0, len(e.entries))
+	for _, v := range e.entries {
+		values = append(values, v)
+	}
+
+	sort.Stable(sorter.For(values))
+	return protobuf.AsMessages(values)
+}
+
+func (e *EndpointSliceTranslator) Query(names []string) []proto.Message {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	values := make([]*envoy_endpoint_v3.ClusterLoadAssignment, 0, len(names))
+	for _, n := range names {
+		v, ok := e.entries[n]
+		if !ok {
+			e.Debugf("no cache entry for %q", n)
+			v = &envoy_endpoint_v3.ClusterLoadAssignment{
+				ClusterName: n,
+			}
+		}
+		values = append(values, v)
+	}
+
+	sort.Stable(sorter.For(values))
+	return protobuf.AsMessages(values)
+}
+
+func (*EndpointSliceTranslator) TypeURL() string { return resource.EndpointType }
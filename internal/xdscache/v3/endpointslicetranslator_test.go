@@ -0,0 +1,661 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	"github.com/golang/protobuf/proto"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+func TestEndpointSliceTranslatorContents(t *testing.T) {
+	tests := map[string]struct {
+		contents map[string]*envoy_endpoint_v3.ClusterLoadAssignment
+		want     []proto.Message
+	}{
+		"empty": {
+			contents: nil,
+			want:     nil,
+		},
+		"simple": {
+			contents: clusterloadassignments(
+				envoy_v3.ClusterLoadAssignment("default/httpbin-org",
+					envoy_v3.SocketAddress("10.10.10.10", 80),
+				),
+			),
+			want: []proto.Message{
+				envoy_v3.ClusterLoadAssignment("default/httpbin-org",
+					envoy_v3.SocketAddress("10.10.10.10", 80),
+				),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+			et.entries = tc.contents
+			got := et.Contents()
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
+
+func TestEndpointSliceTranslatorAddEndpointSlices(t *testing.T) {
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/httpbin-org/a",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "httpbin-org",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{Name: "a"},
+				},
+			},
+		},
+		{
+			ClusterName: "default/httpbin-org/b",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "httpbin-org",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{Name: "b"},
+				},
+			},
+		},
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "simple",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		slices     []*discoveryv1.EndpointSlice
+		want       []proto.Message
+		wantUpdate bool
+	}{
+		"simple": {
+			slices: []*discoveryv1.EndpointSlice{
+				endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeIPv4,
+					sliceEndpoints(readyEndpoint("192.168.183.24")),
+					slicePorts(slicePort("", 8080)),
+				),
+			},
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/a"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/b"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/simple",
+					Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.24", 8080)),
+				},
+			},
+			wantUpdate: true,
+		},
+		"adding an EndpointSlice not used by a ServiceCluster should not trigger a recalculation": {
+			slices: []*discoveryv1.EndpointSlice{
+				endpointSlice("default", "not-used-abcde", "not-used", discoveryv1.AddressTypeIPv4,
+					sliceEndpoints(readyEndpoint("192.168.183.24")),
+					slicePorts(slicePort("", 8080)),
+				),
+			},
+			want:       nil,
+			wantUpdate: false,
+		},
+		"adding an EndpointSlice without a service name label should not trigger a recalculation": {
+			slices: []*discoveryv1.EndpointSlice{
+				endpointSlice("default", "simple-abcde", "", discoveryv1.AddressTypeIPv4,
+					sliceEndpoints(readyEndpoint("192.168.183.24")),
+					slicePorts(slicePort("", 8080)),
+				),
+			},
+			want:       nil,
+			wantUpdate: false,
+		},
+		"multiple slices are merged": {
+			slices: []*discoveryv1.EndpointSlice{
+				endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeIPv4,
+					sliceEndpoints(
+						readyEndpoint("50.17.192.147"),
+						readyEndpoint("50.17.206.192"),
+					),
+					slicePorts(slicePort("", 80)),
+				),
+				endpointSlice("default", "simple-fghij", "simple", discoveryv1.AddressTypeIPv4,
+					sliceEndpoints(
+						readyEndpoint("50.19.99.160"),
+						readyEndpoint("23.23.247.89"),
+						// Duplicated from the first slice.
+						readyEndpoint("50.17.192.147"),
+					),
+					slicePorts(slicePort("", 80)),
+				),
+			},
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/a"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/b"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/simple",
+					Endpoints: envoy_v3.WeightedEndpoints(1,
+						envoy_v3.SocketAddress("23.23.247.89", 80), // addresses should be sorted
+						envoy_v3.SocketAddress("50.17.192.147", 80),
+						envoy_v3.SocketAddress("50.17.206.192", 80),
+						envoy_v3.SocketAddress("50.19.99.160", 80),
+					),
+				},
+			},
+			wantUpdate: true,
+		},
+		"multiple ports": {
+			slices: []*discoveryv1.EndpointSlice{
+				endpointSlice("default", "httpbin-org-abcde", "httpbin-org", discoveryv1.AddressTypeIPv4,
+					sliceEndpoints(readyEndpoint("10.10.1.1")),
+					slicePorts(
+						slicePort("b", 309),
+						slicePort("a", 8675),
+					),
+				),
+			},
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/httpbin-org/a",
+					Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("10.10.1.1", 8675)),
+				},
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/httpbin-org/b",
+					Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("10.10.1.1", 309)),
+				},
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/simple"},
+			},
+			wantUpdate: true,
+		},
+		"not ready endpoints are skipped": {
+			slices: []*discoveryv1.EndpointSlice{
+				endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeIPv4,
+					sliceEndpoints(
+						readyEndpoint("10.10.1.1"),
+						endpoint("10.10.2.2", false, false, false),
+						endpoint("10.10.3.3", false, true, true),
+					),
+					slicePorts(slicePort("", 8080)),
+				),
+			},
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/a"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/b"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/simple",
+					Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("10.10.1.1", 8080)),
+				},
+			},
+			wantUpdate: true,
+		},
+		"serving terminating endpoints are used when there are no ready endpoints": {
+			slices: []*discoveryv1.EndpointSlice{
+				endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeIPv4,
+					sliceEndpoints(
+						endpoint("10.10.2.2", false, false, true),
+						endpoint("10.10.3.3", false, true, true),
+					),
+					slicePorts(slicePort("", 8080)),
+				),
+			},
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/a"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/b"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/simple",
+					Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("10.10.3.3", 8080)),
+				},
+			},
+			wantUpdate: true,
+		},
+		"FQDN slices are skipped": {
+			slices: []*discoveryv1.EndpointSlice{
+				endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeFQDN,
+					sliceEndpoints(readyEndpoint("example.com")),
+					slicePorts(slicePort("", 8080)),
+				),
+			},
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/a"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/httpbin-org/b"},
+				&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/simple"},
+			},
+			wantUpdate: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+			observer := &simpleObserver{}
+			et.Observer = observer
+
+			require.NoError(t, et.cache.SetClusters(clusters))
+			for _, slice := range tc.slices {
+				et.OnAdd(slice)
+			}
+			got := et.Contents()
+			protobuf.ExpectEqual(t, tc.want, got)
+			require.Equal(t, tc.wantUpdate, observer.updated)
+		})
+	}
+}
+
+func TestEndpointSliceTranslatorRemoveEndpointSlices(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+
+	require.NoError(t, et.cache.SetClusters([]*dag.ServiceCluster{
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{{
+				Weight:           1,
+				ServiceName:      "simple",
+				ServiceNamespace: "default",
+				ServicePort:      v1.ServicePort{},
+			}},
+		},
+	}))
+
+	s1 := endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeIPv4,
+		sliceEndpoints(readyEndpoint("192.168.183.24")),
+		slicePorts(slicePort("", 8080)),
+	)
+	s2 := endpointSlice("default", "simple-fghij", "simple", discoveryv1.AddressTypeIPv4,
+		sliceEndpoints(readyEndpoint("192.168.183.25")),
+		slicePorts(slicePort("", 8080)),
+	)
+	et.OnAdd(s1)
+	et.OnAdd(s2)
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints: envoy_v3.WeightedEndpoints(1,
+				envoy_v3.SocketAddress("192.168.183.24", 8080),
+				envoy_v3.SocketAddress("192.168.183.25", 8080),
+			),
+		},
+	}
+	protobuf.RequireEqual(t, want, et.Contents())
+
+	// Removing one slice keeps the endpoints of the other.
+	et.OnDelete(s1)
+
+	want = []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.25", 8080)),
+		},
+	}
+	protobuf.RequireEqual(t, want, et.Contents())
+
+	// Removing the last slice removes all the endpoints.
+	et.OnDelete(s2)
+
+	want = []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/simple"},
+	}
+	protobuf.RequireEqual(t, want, et.Contents())
+}
+
+func TestEndpointSliceTranslatorUpdateEndpointSlices(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+
+	require.NoError(t, et.cache.SetClusters([]*dag.ServiceCluster{
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{{
+				Weight:           1,
+				ServiceName:      "simple",
+				ServiceNamespace: "default",
+				ServicePort:      v1.ServicePort{},
+			}},
+		},
+	}))
+
+	s1 := endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeIPv4,
+		sliceEndpoints(readyEndpoint("192.168.183.24")),
+		slicePorts(slicePort("", 8080)),
+	)
+	et.OnAdd(s1)
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.24", 8080)),
+		},
+	}
+	protobuf.RequireEqual(t, want, et.Contents())
+
+	// s2 is the same as s1, but the endpoint is now terminating
+	// and a new ready endpoint has replaced it.
+	s2 := endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeIPv4,
+		sliceEndpoints(
+			endpoint("192.168.183.24", false, true, true),
+			readyEndpoint("192.168.183.26"),
+		),
+		slicePorts(slicePort("", 8080)),
+	)
+	et.OnUpdate(s1, s2)
+
+	want = []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.26", 8080)),
+		},
+	}
+	protobuf.RequireEqual(t, want, et.Contents())
+
+	// s3 is the same as s2, but without endpoints.
+	s3 := endpointSlice("default", "simple-abcde", "simple", discoveryv1.AddressTypeIPv4,
+		nil,
+		slicePorts(slicePort("", 8080)),
+	)
+	et.OnUpdate(s2, s3)
+
+	want = []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/simple"},
+	}
+	protobuf.RequireEqual(t, want, et.Contents())
+}
+
+// Test that a cluster with weighted services propagates the weights.
+func TestEndpointSliceTranslatorWeightedService(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/weighted",
+			Services: []dag.WeightedService{
+				{
+					Weight:           0,
+					ServiceName:      "weight0",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+				{
+					Weight:           1,
+					ServiceName:      "weight1",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+				{
+					Weight:           2,
+					ServiceName:      "weight2",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, et.cache.SetClusters(clusters))
+
+	for _, svc := range []string{"weight0", "weight1", "weight2"} {
+		et.OnAdd(endpointSlice("default", svc+"-abcde", svc, discoveryv1.AddressTypeIPv4,
+			sliceEndpoints(readyEndpoint("192.168.183.24")),
+			slicePorts(slicePort("", 8080)),
+		))
+	}
+
+	// Each helper builds a `LocalityLbEndpoints` with one
+	// entry, so we can compose the final result by reaching
+	// in an taking the first element of each slice.
+	w0 := envoy_v3.Endpoints(envoy_v3.SocketAddress("192.168.183.24", 8080))
+	w1 := envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.24", 8080))
+	w2 := envoy_v3.WeightedEndpoints(2, envoy_v3.SocketAddress("192.168.183.24", 8080))
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/weighted",
+			Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{
+				w0[0], w1[0], w2[0],
+			},
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, et.Contents())
+}
+
+func TestEndpointSliceTranslatorZonedEndpoints(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/zoned",
+			Services: []dag.WeightedService{
+				{
+					Weight:           2,
+					ServiceName:      "zoned",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, et.cache.SetClusters(clusters))
+
+	et.OnAdd(endpointSlice("default", "zoned-abcde", "zoned", discoveryv1.AddressTypeIPv4,
+		sliceEndpoints(
+			zonedEndpoint("192.168.183.26", "zone-b"),
+			zonedEndpoint("192.168.183.25", "zone-a"),
+			readyEndpoint("192.168.183.27"),
+			zonedEndpoint("192.168.183.24", "zone-a"),
+		),
+		slicePorts(slicePort("", 8080)),
+	))
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/zoned",
+			Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{{
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.27", 8080)),
+				},
+				LoadBalancingWeight: protobuf.UInt32(2),
+			}, {
+				Locality: &envoy_core_v3.Locality{Zone: "zone-a"},
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.24", 8080)),
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.25", 8080)),
+				},
+				LoadBalancingWeight: protobuf.UInt32(2),
+			}, {
+				Locality: &envoy_core_v3.Locality{Zone: "zone-b"},
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.26", 8080)),
+				},
+				LoadBalancingWeight: protobuf.UInt32(2),
+			}},
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, et.Contents())
+}
+
+// TestEndpointSliceTranslatorHintedEndpoints checks that topology
+// aware hints take precedence over the zone of the endpoint.
+func TestEndpointSliceTranslatorHintedEndpoints(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+	clusters := []*dag.ServiceCluster{
+		{
+			ClusterName: "default/hinted",
+			Services: []dag.WeightedService{
+				{
+					Weight:           1,
+					ServiceName:      "hinted",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, et.cache.SetClusters(clusters))
+
+	et.OnAdd(endpointSlice("default", "hinted-abcde", "hinted", discoveryv1.AddressTypeIPv4,
+		sliceEndpoints(
+			hintedEndpoint("192.168.183.24", "zone-a", "zone-a"),
+			hintedEndpoint("192.168.183.25", "zone-a", "zone-b"),
+			hintedEndpoint("192.168.183.26", "zone-b", "zone-b"),
+		),
+		slicePorts(slicePort("", 8080)),
+	))
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/hinted",
+			Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{{
+				Locality: &envoy_core_v3.Locality{Zone: "zone-a"},
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.24", 8080)),
+				},
+				LoadBalancingWeight: protobuf.UInt32(1),
+			}, {
+				Locality: &envoy_core_v3.Locality{Zone: "zone-b"},
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.25", 8080)),
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.26", 8080)),
+				},
+				LoadBalancingWeight: protobuf.UInt32(1),
+			}},
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, et.Contents())
+}
+
+// TestEndpointSliceTranslatorLocalService checks that the endpoints
+// of the local Service are served as the Envoy local cluster.
+func TestEndpointSliceTranslatorLocalService(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+	et.SetLocalService(types.NamespacedName{Namespace: "projectcontour", Name: "envoy"})
+	et.OnChange(&dag.DAG{})
+
+	et.OnAdd(endpointSlice("projectcontour", "envoy-abcde", "envoy", discoveryv1.AddressTypeIPv4,
+		sliceEndpoints(
+			zonedEndpoint("192.168.183.24", "zone-a"),
+			zonedEndpoint("192.168.183.25", "zone-b"),
+		),
+		slicePorts(slicePort("http", 8080)),
+	))
+
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "envoy-local",
+			Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{{
+				Locality: &envoy_core_v3.Locality{Zone: "zone-a"},
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.24", 8080)),
+				},
+				LoadBalancingWeight: protobuf.UInt32(1),
+			}, {
+				Locality: &envoy_core_v3.Locality{Zone: "zone-b"},
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.25", 8080)),
+				},
+				LoadBalancingWeight: protobuf.UInt32(1),
+			}},
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, et.Contents())
+}
+
+func endpointSlice(ns, name, service string, addressType discoveryv1.AddressType, endpoints []discoveryv1.Endpoint, ports []discoveryv1.EndpointPort) *discoveryv1.EndpointSlice {
+	s := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		AddressType: addressType,
+		Endpoints:   endpoints,
+		Ports:       ports,
+	}
+
+	if service != "" {
+		s.Labels = map[string]string{
+			discoveryv1.LabelServiceName: service,
+		}
+	}
+
+	return s
+}
+
+func sliceEndpoints(eps ...discoveryv1.Endpoint) []discoveryv1.Endpoint {
+	return eps
+}
+
+func readyEndpoint(address string) discoveryv1.Endpoint {
+	return endpoint(address, true, true, false)
+}
+
+func endpoint(address string, ready, serving, terminating bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses: []string{address},
+		Conditions: discoveryv1.EndpointConditions{
+			Ready:       pointer.Bool(ready),
+			Serving:     pointer.Bool(serving),
+			Terminating: pointer.Bool(terminating),
+		},
+	}
+}
+
+func zonedEndpoint(address, zone string) discoveryv1.Endpoint {
+	ep := readyEndpoint(address)
+	ep.Zone = pointer.String(zone)
+	return ep
+}
+
+func hintedEndpoint(address, zone, hint string) discoveryv1.Endpoint {
+	ep := zonedEndpoint(address, zone)
+	ep.Hints = &discoveryv1.EndpointHints{
+		ForZones: []discoveryv1.ForZone{{Name: hint}},
+	}
+	return ep
+}
+
+func slicePorts(eps ...discoveryv1.EndpointPort) []discoveryv1.EndpointPort {
+	return eps
+}
+
+func slicePort(name string, port int32) discoveryv1.EndpointPort {
+	protocol := v1.ProtocolTCP
+	return discoveryv1.EndpointPort{
+		Name:     pointer.String(name),
+		Port:     pointer.Int32(port),
+		Protocol: &protocol,
+	}
+}
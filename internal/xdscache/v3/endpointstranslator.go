@@ -256,6 +256,12 @@ type EndpointsTranslator struct {
 	entries map[string]*envoy_endpoint_v3.ClusterLoadAssignment
 }
 
+// SetObserver sets the observer to notify when the
+// endpoints cache has been updated.
+func (e *EndpointsTranslator) SetObserver(observer contour.Observer) {
+	e.Observer = observer
+}
+
 // Merge combines the given entries with the existing entries in the
 // EndpointsTranslator. If the same key exists in both maps, an existing entry
 // is replaced.
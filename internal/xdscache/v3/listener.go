@@ -14,7 +14,6 @@
 package v3
 
 import (
-	"path"
 	"sort"
 	"sync"
 
@@ -41,6 +40,7 @@ const (
 	ENVOY_HTTP_LISTENER            = "ingress_http"
 	ENVOY_FALLBACK_ROUTECONFIG     = "ingress_fallbackcert"
 	ENVOY_HTTPS_LISTENER           = "ingress_https"
+	ENVOY_HTTP3_LISTENER           = "ingress_http3"
 	DEFAULT_HTTP_ACCESS_LOG        = "/dev/stdout"
 	DEFAULT_HTTP_LISTENER_ADDRESS  = "0.0.0.0"
 	DEFAULT_HTTP_LISTENER_PORT     = 8080
@@ -129,6 +129,11 @@ type ListenerConfig struct {
 	// right side of the x-forwarded-for HTTP header to trust.
 	XffNumTrustedHops uint32
 
+	// HTTP3 adds a QUIC listener on the address and port of the
+	// HTTPS listener that serves HTTP/3 for its secure virtual hosts.
+	// If not set, defaults to false.
+	HTTP3 bool
+
 	// ConnectionBalancer
 	// The validated value is 'exact'.
 	// If no configuration is specified, Envoy will not attempt to balance active connections between worker threads
@@ -137,6 +142,14 @@ type ListenerConfig struct {
 	// RateLimitConfig optionally configures the global Rate Limit Service to be
 	// used.
 	RateLimitConfig *RateLimitConfig
+
+	// TracingConfig optionally configures the export of trace data
+	// to an OpenTelemetry collector.
+	TracingConfig *TracingConfig
+
+	// AccessLogServiceConfig optionally configures a gRPC access log
+	// service to send access logs to instead of the access log files.
+	AccessLogServiceConfig *AccessLogServiceConfig
 }
 
 type RateLimitConfig struct {
@@ -148,6 +161,28 @@ type RateLimitConfig struct {
 	EnableXRateLimitHeaders bool
 }
 
+type TracingConfig struct {
+	ExtensionService types.NamespacedName
+	SNI              string
+	Timeout          timeout.Setting
+	OverallSampling  float64
+	MaxPathTagLength uint32
+	CustomTags       []*CustomTag
+}
+
+type CustomTag struct {
+	TagName           string
+	Literal           string
+	RequestHeaderName string
+}
+
+type AccessLogServiceConfig struct {
+	ExtensionService types.NamespacedName
+	SNI              string
+	Timeout          timeout.Setting
+	LogName          string
+}
+
 // DefaultListeners returns the configured Listeners or a single
 // Insecure (http) & single Secure (https) default listeners
 // if not provided.
@@ -205,6 +240,43 @@ func (lvc *ListenerConfig) secureListeners() map[string]*envoy_listener_v3.Liste
 	return listeners
 }
 
+// quicListener returns the QUIC listener that serves HTTP/3 on the
+// address and port of the HTTPS listener.
+func (lvc *ListenerConfig) quicListener() *envoy_listener_v3.Listener {
+	l, ok := lvc.HTTPSListeners[ENVOY_HTTPS_LISTENER]
+	if !ok {
+		l = Listener{
+			Address: DEFAULT_HTTPS_LISTENER_ADDRESS,
+			Port:    DEFAULT_HTTPS_LISTENER_PORT,
+		}
+	}
+	return envoy_v3.QUICListener(ENVOY_HTTP3_LISTENER, l.Address, l.Port)
+}
+
+// http3Enabled returns whether a secure virtual host of the HTTPS
+// listener is also served over HTTP/3. QUIC always terminates TLS,
+// so TLS passthrough vhosts are excluded, as are vhosts that require
+// client certificates, which Envoy's QUIC listener doesn't support.
+func http3Enabled(listenerName string, vh *dag.SecureVirtualHost) bool {
+	return listenerName == ENVOY_HTTPS_LISTENER &&
+		vh.TCPProxy == nil &&
+		vh.Secret != nil &&
+		vh.DownstreamValidation == nil
+}
+
+// listenerAddress returns the address for a listener that isn't
+// one of the configured HTTP or HTTPS listeners. If the listener
+// doesn't have one, it shares the address of the HTTP listener.
+func (lvc *ListenerConfig) listenerAddress(listener *dag.Listener) string {
+	if len(listener.Address) > 0 {
+		return listener.Address
+	}
+	if l, ok := lvc.HTTPListeners[ENVOY_HTTP_LISTENER]; ok && len(l.Address) > 0 {
+		return l.Address
+	}
+	return DEFAULT_HTTP_LISTENER_ADDRESS
+}
+
 // httpAccessLog returns the access log for the HTTP (non TLS)
 // listener or DEFAULT_HTTP_ACCESS_LOG if not configured.
 func (lvc *ListenerConfig) httpAccessLog() string {
@@ -241,7 +313,36 @@ func (lvc *ListenerConfig) accesslogFields() contour_api_v1alpha1.AccessLogJSONF
 	return contour_api_v1alpha1.DefaultAccessLogJSONFields
 }
 
+// grpcAccessLogConfig returns the gRPC access log service configuration,
+// or nil if access logs are written to files.
+func (lvc *ListenerConfig) grpcAccessLogConfig() *envoy_v3.GrpcAccessLogConfig {
+	if lvc.AccessLogServiceConfig == nil {
+		return nil
+	}
+
+	return &envoy_v3.GrpcAccessLogConfig{
+		ExtensionService: lvc.AccessLogServiceConfig.ExtensionService,
+		SNI:              lvc.AccessLogServiceConfig.SNI,
+		Timeout:          lvc.AccessLogServiceConfig.Timeout,
+		LogName:          lvc.AccessLogServiceConfig.LogName,
+	}
+}
+
+// grpcAccessLogFields returns the JSON fields that select the additional
+// headers sent to the gRPC access log service. Fields are only selected
+// when the access log format is JSON.
+func (lvc *ListenerConfig) grpcAccessLogFields() contour_api_v1alpha1.AccessLogJSONFields {
+	if lvc.accesslogType() != string(config.JSONAccessLog) {
+		return nil
+	}
+	return lvc.accesslogFields()
+}
+
 func (lvc *ListenerConfig) newInsecureAccessLog() []*envoy_accesslog_v3.AccessLog {
+	if als := lvc.grpcAccessLogConfig(); als != nil {
+		return envoy_v3.HTTPGrpcAccessLog(als, lvc.grpcAccessLogFields(), lvc.AccessLogLevel)
+	}
+
 	switch lvc.accesslogType() {
 	case string(config.JSONAccessLog):
 		return envoy_v3.FileAccessLogJSON(lvc.httpAccessLog(), lvc.accesslogFields(), lvc.AccessLogFormatterExtensions, lvc.AccessLogLevel)
@@ -251,6 +352,10 @@ func (lvc *ListenerConfig) newInsecureAccessLog() []*envoy_accesslog_v3.AccessLo
 }
 
 func (lvc *ListenerConfig) newSecureAccessLog() []*envoy_accesslog_v3.AccessLog {
+	if als := lvc.grpcAccessLogConfig(); als != nil {
+		return envoy_v3.HTTPGrpcAccessLog(als, lvc.grpcAccessLogFields(), lvc.AccessLogLevel)
+	}
+
 	switch lvc.accesslogType() {
 	case "json":
 		return envoy_v3.FileAccessLogJSON(lvc.httpsAccessLog(), lvc.accesslogFields(), lvc.AccessLogFormatterExtensions, lvc.AccessLogLevel)
@@ -259,6 +364,132 @@ func (lvc *ListenerConfig) newSecureAccessLog() []*envoy_accesslog_v3.AccessLog
 	}
 }
 
+// accessLoggers returns the access logs for an HTTP connection manager.
+// The default access logs are restricted to requests without an access
+// log policy, and each access log policy adds access logs for the
+// requests it applies to.
+func (lvc *ListenerConfig) accessLoggers(defaultLogs []*envoy_accesslog_v3.AccessLog, path string, policies map[string]*dag.AccessLogPolicy) []*envoy_accesslog_v3.AccessLog {
+	if len(policies) == 0 {
+		return defaultLogs
+	}
+
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	logs := envoy_v3.AccessLogsWithoutPolicy(defaultLogs)
+	for _, name := range names {
+		logs = append(logs, envoy_v3.AccessLogsForPolicy(name, lvc.policyAccessLog(path, policies[name]))...)
+	}
+	return logs
+}
+
+// policyAccessLog returns the access log for an access log policy. Fields
+// that the policy doesn't set are taken from the global configuration.
+func (lvc *ListenerConfig) policyAccessLog(path string, policy *dag.AccessLogPolicy) []*envoy_accesslog_v3.AccessLog {
+	logging := contour_api_v1alpha1.EnvoyLogging{
+		AccessLogFormat:       contour_api_v1alpha1.AccessLogType(lvc.accesslogType()),
+		AccessLogFormatString: lvc.AccessLogFormatString,
+		AccessLogJSONFields:   lvc.accesslogFields(),
+		AccessLogLevel:        lvc.AccessLogLevel,
+	}
+	if policy.Format != "" {
+		logging.AccessLogFormat = policy.Format
+	}
+	if policy.FormatString != "" {
+		logging.AccessLogFormatString = policy.FormatString
+	}
+	if len(policy.JSONFields) > 0 {
+		logging.AccessLogJSONFields = policy.JSONFields
+	}
+	if policy.Level != "" {
+		logging.AccessLogLevel = policy.Level
+	}
+
+	if als := lvc.grpcAccessLogConfig(); als != nil {
+		var fields contour_api_v1alpha1.AccessLogJSONFields
+		if logging.AccessLogFormat == contour_api_v1alpha1.JSONAccessLog {
+			fields = logging.AccessLogJSONFields
+		}
+		return envoy_v3.HTTPGrpcAccessLog(als, fields, logging.AccessLogLevel)
+	}
+
+	switch logging.AccessLogFormat {
+	case contour_api_v1alpha1.JSONAccessLog:
+		return envoy_v3.FileAccessLogJSON(path, logging.AccessLogJSONFields, logging.AccessLogFormatterExtensions(), logging.AccessLogLevel)
+	default:
+		return envoy_v3.FileAccessLogEnvoy(path, logging.AccessLogFormatString, logging.AccessLogFormatterExtensions(), logging.AccessLogLevel)
+	}
+}
+
+// accessLogPolicies returns the access log policies of the virtual
+// hosts and their routes, keyed by policy name.
+func accessLogPolicies(vhosts ...*dag.VirtualHost) map[string]*dag.AccessLogPolicy {
+	policies := map[string]*dag.AccessLogPolicy{}
+	add := func(policy *dag.AccessLogPolicy) {
+		if policy != nil {
+			policies[envoy_v3.AccessLogPolicyName(policy)] = policy
+		}
+	}
+
+	for _, vh := range vhosts {
+		add(vh.AccessLogPolicy)
+		for _, route := range vh.Routes {
+			add(route.AccessLogPolicy)
+		}
+	}
+	return policies
+}
+
+// accessLogPolicyFilter returns the HTTP filter that records the access
+// log policy of each request, or nil if there are no policies.
+func accessLogPolicyFilter(policies map[string]*dag.AccessLogPolicy) *http.HttpFilter {
+	if len(policies) == 0 {
+		return nil
+	}
+	return envoy_v3.FilterAccessLogPolicy()
+}
+
+// clientCertificateAuthorizationFilter returns the HTTP filter that
+// authorizes requests by client certificate, or nil if neither vh nor
+// any of its routes restricts the allowed client certificates.
+func clientCertificateAuthorizationFilter(vh *dag.VirtualHost) *http.HttpFilter {
+	if vh.ClientCertificateAuthorization != nil {
+		return envoy_v3.FilterClientCertificateAuthorization()
+	}
+	for _, route := range vh.Routes {
+		if route.ClientCertificateAuthorization != nil {
+			return envoy_v3.FilterClientCertificateAuthorization()
+		}
+	}
+	return nil
+}
+
+// bufferFilter returns the HTTP filter that buffers request bodies, or
+// nil if none of the routes of vhosts has a buffer policy.
+func bufferFilter(vhosts ...*dag.VirtualHost) *http.HttpFilter {
+	if !bufferPolicyEnabled(vhosts...) {
+		return nil
+	}
+	return envoy_v3.FilterBuffer()
+}
+
+func (lvc *ListenerConfig) newInsecureTCPAccessLog() []*envoy_accesslog_v3.AccessLog {
+	if als := lvc.grpcAccessLogConfig(); als != nil {
+		return envoy_v3.TCPGrpcAccessLog(als, lvc.AccessLogLevel)
+	}
+	return lvc.newInsecureAccessLog()
+}
+
+func (lvc *ListenerConfig) newSecureTCPAccessLog() []*envoy_accesslog_v3.AccessLog {
+	if als := lvc.grpcAccessLogConfig(); als != nil {
+		return envoy_v3.TCPGrpcAccessLog(als, lvc.AccessLogLevel)
+	}
+	return lvc.newSecureAccessLog()
+}
+
 // minTLSVersion returns the requested minimum TLS protocol
 // version or envoy_tls_v3.TlsParameters_TLSv1_2 if not configured.
 func (lvc *ListenerConfig) minTLSVersion() envoy_tls_v3.TlsParameters_TlsProtocol {
@@ -371,35 +602,74 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 	// want the vhosts that have been attached to a listener
 	// by the listener processor.
 	for _, listener := range root.Listeners {
-		if len(listener.VirtualHosts) > 0 {
-			if httpListener, ok := cfg.HTTPListeners[listener.Name]; ok {
-				// Add a listener if there are vhosts bound to http.
-				cm := envoy_v3.HTTPConnectionManagerBuilder().
-					Codec(envoy_v3.CodecForVersions(cfg.DefaultHTTPVersions...)).
-					DefaultFilters().
-					RouteConfigName(httpListener.Name).
-					MetricsPrefix(httpListener.Name).
-					AccessLoggers(cfg.newInsecureAccessLog()).
-					RequestTimeout(cfg.Timeouts.Request).
-					ConnectionIdleTimeout(cfg.Timeouts.ConnectionIdle).
-					StreamIdleTimeout(cfg.Timeouts.StreamIdle).
-					DelayedCloseTimeout(cfg.Timeouts.DelayedClose).
-					MaxConnectionDuration(cfg.Timeouts.MaxConnectionDuration).
-					ConnectionShutdownGracePeriod(cfg.Timeouts.ConnectionShutdownGracePeriod).
-					AllowChunkedLength(cfg.AllowChunkedLength).
-					MergeSlashes(cfg.MergeSlashes).
-					NumTrustedHops(cfg.XffNumTrustedHops).
-					AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(cfg.RateLimitConfig))).
-					Get()
+		// Plain TCP listeners have no virtual hosts, just
+		// a TCP proxy for all of the listener's traffic.
+		if listener.TCPProxy != nil {
+			listeners[listener.Name] = envoy_v3.Listener(
+				listener.Name,
+				cfg.listenerAddress(listener),
+				listener.Port,
+				proxyProtocol(cfg.UseProxyProto),
+				envoy_v3.TCPProxy(listener.Name, listener.TCPProxy, cfg.newInsecureTCPAccessLog()),
+			)
+			continue
+		}
 
-				listeners[httpListener.Name] = envoy_v3.Listener(
-					httpListener.Name,
-					httpListener.Address,
-					httpListener.Port,
-					proxyProtocol(cfg.UseProxyProto),
-					cm,
-				)
+		if len(listener.VirtualHosts) > 0 {
+			httpListener, ok := cfg.HTTPListeners[listener.Name]
+			if !ok {
+				// Listeners for Gateway listeners on other ports
+				// aren't configured, so Envoy listens on the
+				// DAG listener's port.
+				httpListener = Listener{
+					Name:    listener.Name,
+					Address: cfg.listenerAddress(listener),
+					Port:    listener.Port,
+				}
 			}
+
+			policies := accessLogPolicies(listener.VirtualHosts...)
+
+			// Add a listener if there are vhosts bound to http.
+			cm := envoy_v3.HTTPConnectionManagerBuilder().
+				Codec(envoy_v3.CodecForVersions(cfg.DefaultHTTPVersions...)).
+				DefaultFilters().
+				AddFilter(accessLogPolicyFilter(policies)).
+				AddFilter(bufferFilter(listener.VirtualHosts...)).
+				RouteConfigName(httpListener.Name).
+				MetricsPrefix(httpListener.Name).
+				AccessLoggers(cfg.accessLoggers(cfg.newInsecureAccessLog(), cfg.httpAccessLog(), policies)).
+				RequestTimeout(cfg.Timeouts.Request).
+				ConnectionIdleTimeout(cfg.Timeouts.ConnectionIdle).
+				StreamIdleTimeout(cfg.Timeouts.StreamIdle).
+				DelayedCloseTimeout(cfg.Timeouts.DelayedClose).
+				MaxConnectionDuration(cfg.Timeouts.MaxConnectionDuration).
+				ConnectionShutdownGracePeriod(cfg.Timeouts.ConnectionShutdownGracePeriod).
+				AllowChunkedLength(cfg.AllowChunkedLength).
+				MergeSlashes(cfg.MergeSlashes).
+				NumTrustedHops(cfg.XffNumTrustedHops).
+				AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(cfg.RateLimitConfig))).
+				Tracing(envoy_v3.TracingConfig(envoyTracingConfig(cfg.TracingConfig))).
+				Get()
+
+			listeners[httpListener.Name] = envoy_v3.Listener(
+				httpListener.Name,
+				httpListener.Address,
+				httpListener.Port,
+				proxyProtocol(cfg.UseProxyProto),
+				cm,
+			)
+		}
+
+		// Likewise, secure listeners for Gateway listeners on
+		// other ports aren't configured.
+		if _, ok := listeners[listener.Name]; !ok && len(listener.SecureVirtualHosts) > 0 {
+			listeners[listener.Name] = envoy_v3.Listener(
+				listener.Name,
+				cfg.listenerAddress(listener),
+				listener.Port,
+				secureProxyProtocol(cfg.UseProxyProto),
+			)
 		}
 
 		for _, vh := range listener.SecureVirtualHosts {
@@ -425,34 +695,65 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 				// metrics prefix to keep compatibility with previous
 				// Contour versions since the metrics prefix will be
 				// coded into monitoring dashboards.
-				cm := envoy_v3.HTTPConnectionManagerBuilder().
-					Codec(envoy_v3.CodecForVersions(cfg.DefaultHTTPVersions...)).
-					AddFilter(envoy_v3.FilterMisdirectedRequests(vh.VirtualHost.Name)).
-					DefaultFilters().
-					AddFilter(authFilter).
-					RouteConfigName(path.Join("https", vh.VirtualHost.Name)).
-					MetricsPrefix(listener.Name).
-					AccessLoggers(cfg.newSecureAccessLog()).
-					RequestTimeout(cfg.Timeouts.Request).
-					ConnectionIdleTimeout(cfg.Timeouts.ConnectionIdle).
-					StreamIdleTimeout(cfg.Timeouts.StreamIdle).
-					DelayedCloseTimeout(cfg.Timeouts.DelayedClose).
-					MaxConnectionDuration(cfg.Timeouts.MaxConnectionDuration).
-					ConnectionShutdownGracePeriod(cfg.Timeouts.ConnectionShutdownGracePeriod).
-					AllowChunkedLength(cfg.AllowChunkedLength).
-					MergeSlashes(cfg.MergeSlashes).
-					NumTrustedHops(cfg.XffNumTrustedHops).
-					AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(cfg.RateLimitConfig))).
-					Get()
+				policies := accessLogPolicies(&vh.VirtualHost)
+
+				secureConnectionManager := func(codec envoy_v3.HTTPVersionType, metricsPrefix string) *envoy_listener_v3.Filter {
+					return envoy_v3.HTTPConnectionManagerBuilder().
+						Codec(codec).
+						AddFilter(envoy_v3.FilterMisdirectedRequests(vh.VirtualHost.Name)).
+						DefaultFilters().
+						AddFilter(clientCertificateAuthorizationFilter(&vh.VirtualHost)).
+						AddFilter(authFilter).
+						AddFilter(accessLogPolicyFilter(policies)).
+						AddFilter(bufferFilter(&vh.VirtualHost)).
+						RouteConfigName(secureRouteConfigName(listener.Name, vh.VirtualHost.Name)).
+						MetricsPrefix(metricsPrefix).
+						AccessLoggers(cfg.accessLoggers(cfg.newSecureAccessLog(), cfg.httpsAccessLog(), policies)).
+						RequestTimeout(cfg.Timeouts.Request).
+						ConnectionIdleTimeout(cfg.Timeouts.ConnectionIdle).
+						StreamIdleTimeout(cfg.Timeouts.StreamIdle).
+						DelayedCloseTimeout(cfg.Timeouts.DelayedClose).
+						MaxConnectionDuration(cfg.Timeouts.MaxConnectionDuration).
+						ConnectionShutdownGracePeriod(cfg.Timeouts.ConnectionShutdownGracePeriod).
+						AllowChunkedLength(cfg.AllowChunkedLength).
+						MergeSlashes(cfg.MergeSlashes).
+						NumTrustedHops(cfg.XffNumTrustedHops).
+						ForwardClientCertificate(vh.ForwardClientCertificate).
+						AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(cfg.RateLimitConfig))).
+						Tracing(envoy_v3.TracingConfig(envoyTracingConfig(cfg.TracingConfig))).
+						Get()
+				}
 
-				filters = envoy_v3.Filters(cm)
+				filters = envoy_v3.Filters(secureConnectionManager(envoy_v3.CodecForVersions(cfg.DefaultHTTPVersions...), listener.Name))
 
 				alpnProtos = envoy_v3.ProtoNamesForVersions(cfg.DefaultHTTPVersions...)
+
+				// Serve the same vhost over HTTP/3 on the QUIC
+				// listener, reusing the vhost's certificate. QUIC
+				// requires TLS 1.3, so the cipher suites configured
+				// for TLS 1.2 don't apply.
+				if cfg.HTTP3 && http3Enabled(listener.Name, vh) {
+					if _, ok := listeners[ENVOY_HTTP3_LISTENER]; !ok {
+						listeners[ENVOY_HTTP3_LISTENER] = cfg.quicListener()
+					}
+
+					quicTLS := envoy_v3.DownstreamTLSContext(
+						vh.Secret,
+						envoy_tls_v3.TlsParameters_TLSv1_3,
+						nil,
+						nil,
+						"h3")
+					quicTLS.OcspStaplePolicy = envoy_v3.OCSPStaplePolicy(vh.OCSPStaplePolicy)
+
+					listeners[ENVOY_HTTP3_LISTENER].FilterChains = append(listeners[ENVOY_HTTP3_LISTENER].FilterChains,
+						envoy_v3.FilterChainQUIC(vh.VirtualHost.Name, quicTLS,
+							envoy_v3.Filters(secureConnectionManager(envoy_v3.HTTPVersion3, ENVOY_HTTP3_LISTENER))))
+				}
 			} else {
 				filters = envoy_v3.Filters(
 					envoy_v3.TCPProxy(listener.Name,
 						vh.TCPProxy,
-						cfg.newSecureAccessLog()),
+						cfg.newSecureTCPAccessLog()),
 				)
 
 				// Do not offer ALPN for TCP proxying, since
@@ -473,6 +774,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 					cfg.CipherSuites,
 					vh.DownstreamValidation,
 					alpnProtos...)
+				downstreamTLS.OcspStaplePolicy = envoy_v3.OCSPStaplePolicy(vh.OCSPStaplePolicy)
 			}
 
 			listeners[listener.Name].FilterChains = append(listeners[listener.Name].FilterChains, envoy_v3.FilterChainTLS(vh.VirtualHost.Name, downstreamTLS, filters))
@@ -493,11 +795,23 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 					alpnProtos...,
 				)
 
+				// The fallback route configuration holds the routes
+				// of every vhost that enabled the fallback certificate.
+				var fallbackVirtualHosts []*dag.VirtualHost
+				for _, svh := range listener.SecureVirtualHosts {
+					if svh.FallbackCertificate != nil {
+						fallbackVirtualHosts = append(fallbackVirtualHosts, &svh.VirtualHost)
+					}
+				}
+				policies := accessLogPolicies(fallbackVirtualHosts...)
+
 				cm := envoy_v3.HTTPConnectionManagerBuilder().
 					DefaultFilters().
+					AddFilter(accessLogPolicyFilter(policies)).
+					AddFilter(bufferFilter(fallbackVirtualHosts...)).
 					RouteConfigName(ENVOY_FALLBACK_ROUTECONFIG).
 					MetricsPrefix(listener.Name).
-					AccessLoggers(cfg.newSecureAccessLog()).
+					AccessLoggers(cfg.accessLoggers(cfg.newSecureAccessLog(), cfg.httpsAccessLog(), policies)).
 					RequestTimeout(cfg.Timeouts.Request).
 					ConnectionIdleTimeout(cfg.Timeouts.ConnectionIdle).
 					StreamIdleTimeout(cfg.Timeouts.StreamIdle).
@@ -508,6 +822,7 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 					MergeSlashes(cfg.MergeSlashes).
 					NumTrustedHops(cfg.XffNumTrustedHops).
 					AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(cfg.RateLimitConfig))).
+					Tracing(envoy_v3.TracingConfig(envoyTracingConfig(cfg.TracingConfig))).
 					Get()
 
 				// Default filter chain
@@ -521,10 +836,17 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 	// Remove the https listener if there are no vhosts bound to it.
 	if len(listeners[ENVOY_HTTPS_LISTENER].FilterChains) == 0 {
 		delete(listeners, ENVOY_HTTPS_LISTENER)
-	} else {
-		// there's some https listeners, we need to sort the filter chains
-		// to ensure that the LDS entries are identical.
-		sort.Stable(sorter.For(listeners[ENVOY_HTTPS_LISTENER].FilterChains))
+	}
+
+	// Sort the filter chains of the https listeners
+	// to ensure that the LDS entries are identical.
+	for _, listener := range root.Listeners {
+		if len(listener.SecureVirtualHosts) > 0 {
+			sort.Stable(sorter.For(listeners[listener.Name].FilterChains))
+		}
+	}
+	if quic, ok := listeners[ENVOY_HTTP3_LISTENER]; ok {
+		sort.Stable(sorter.For(quic.FilterChains))
 	}
 
 	// support more params of envoy listener
@@ -532,6 +854,10 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 	// 1. connection balancer
 	if cfg.ConnectionBalancer == "exact" {
 		for _, listener := range listeners {
+			// Connection balancing only applies to TCP listeners.
+			if listener.UdpListenerConfig != nil {
+				continue
+			}
 			listener.ConnectionBalanceConfig = &envoy_listener_v3.Listener_ConnectionBalanceConfig{
 				BalanceType: &envoy_listener_v3.Listener_ConnectionBalanceConfig_ExactBalance_{
 					ExactBalance: &envoy_listener_v3.Listener_ConnectionBalanceConfig_ExactBalance{},
@@ -558,6 +884,30 @@ func envoyGlobalRateLimitConfig(config *RateLimitConfig) *envoy_v3.GlobalRateLim
 	}
 }
 
+func envoyTracingConfig(config *TracingConfig) *envoy_v3.EnvoyTracingConfig {
+	if config == nil {
+		return nil
+	}
+
+	var customTags []*envoy_v3.CustomTag
+	for _, tag := range config.CustomTags {
+		customTags = append(customTags, &envoy_v3.CustomTag{
+			TagName:           tag.TagName,
+			Literal:           tag.Literal,
+			RequestHeaderName: tag.RequestHeaderName,
+		})
+	}
+
+	return &envoy_v3.EnvoyTracingConfig{
+		ExtensionService: config.ExtensionService,
+		SNI:              config.SNI,
+		Timeout:          config.Timeout,
+		OverallSampling:  config.OverallSampling,
+		MaxPathTagLength: config.MaxPathTagLength,
+		CustomTags:       customTags,
+	}
+}
+
 func proxyProtocol(useProxy bool) []*envoy_listener_v3.ListenerFilter {
 	if useProxy {
 		return envoy_v3.ListenerFilters(
@@ -309,6 +309,110 @@ func TestListenerVisit(t *testing.T) {
 				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
 			}),
 		},
+		"simple ingress with secret and http3 enabled": {
+			ListenerConfig: ListenerConfig{
+				HTTP3:              true,
+				ConnectionBalancer: "exact",
+			},
+			objs: []interface{}{
+				&networking_v1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: networking_v1.IngressSpec{
+						TLS: []networking_v1.IngressTLS{{
+							Hosts:      []string{"whatever.example.com"},
+							SecretName: "secret",
+						}},
+						Rules: []networking_v1.IngressRule{{
+							Host: "whatever.example.com",
+							IngressRuleValue: networking_v1.IngressRuleValue{
+								HTTP: &networking_v1.HTTPIngressRuleValue{
+									Paths: []networking_v1.HTTPIngressPath{{
+										Backend: *backend("kuard", 8080),
+									}},
+								},
+							},
+						}},
+					},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "secret",
+						Namespace: "default",
+					},
+					Type: "kubernetes.io/tls",
+					Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "kuard",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     8080,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:          ENVOY_HTTP_LISTENER,
+				Address:       envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains:  envoy_v3.FilterChains(envoy_v3.HTTPConnectionManager(ENVOY_HTTP_LISTENER, envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo), 0)),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+				ConnectionBalanceConfig: &envoy_listener_v3.Listener_ConnectionBalanceConfig{
+					BalanceType: &envoy_listener_v3.Listener_ConnectionBalanceConfig_ExactBalance_{
+						ExactBalance: &envoy_listener_v3.Listener_ConnectionBalanceConfig_ExactBalance{},
+					},
+				},
+			}, &envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTPS_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{{
+					FilterChainMatch: &envoy_listener_v3.FilterChainMatch{
+						ServerNames: []string{"whatever.example.com"},
+					},
+					TransportSocket: transportSocket("secret", envoy_tls_v3.TlsParameters_TLSv1_2, nil, "h2", "http/1.1"),
+					Filters:         envoy_v3.Filters(httpsFilterFor("whatever.example.com")),
+				}},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+				ConnectionBalanceConfig: &envoy_listener_v3.Listener_ConnectionBalanceConfig{
+					BalanceType: &envoy_listener_v3.Listener_ConnectionBalanceConfig_ExactBalance_{
+						ExactBalance: &envoy_listener_v3.Listener_ConnectionBalanceConfig_ExactBalance{},
+					},
+				},
+			}, &envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP3_LISTENER,
+				Address: udpSocketAddress("0.0.0.0", 8443),
+				UdpListenerConfig: &envoy_listener_v3.UdpListenerConfig{
+					QuicOptions: &envoy_listener_v3.QuicProtocolOptions{},
+					DownstreamSocketConfig: &envoy_core_v3.UdpSocketConfig{
+						PreferGro: protobuf.Bool(true),
+					},
+				},
+				FilterChains: []*envoy_listener_v3.FilterChain{{
+					FilterChainMatch: &envoy_listener_v3.FilterChainMatch{
+						ServerNames: []string{"whatever.example.com"},
+					},
+					TransportSocket: quicTransportSocket("secret"),
+					Filters: envoy_v3.Filters(envoy_v3.HTTPConnectionManagerBuilder().
+						Codec(envoy_v3.HTTPVersion3).
+						AddFilter(envoy_v3.FilterMisdirectedRequests("whatever.example.com")).
+						DefaultFilters().
+						MetricsPrefix(ENVOY_HTTP3_LISTENER).
+						RouteConfigName(path.Join("https", "whatever.example.com")).
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy(DEFAULT_HTTP_ACCESS_LOG, "", nil, v1alpha1.LogLevelInfo)).
+						Get()),
+				}},
+			}),
+		},
 		"multiple tls ingress with secrets should be sorted": {
 			objs: []interface{}{
 				&networking_v1.Ingress{
@@ -3098,6 +3202,129 @@ func TestListenerVisit(t *testing.T) {
 				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
 			}),
 		},
+		"insecure httpproxy with tracing config": {
+			ListenerConfig: ListenerConfig{
+				TracingConfig: &TracingConfig{
+					ExtensionService: types.NamespacedName{Namespace: "projectcontour", Name: "otel-collector"},
+					Timeout:          timeout.DurationSetting(5 * time.Second),
+					OverallSampling:  100,
+					MaxPathTagLength: 256,
+					CustomTags: []*CustomTag{{
+						TagName: "cluster",
+						Literal: "west",
+					}},
+				},
+			},
+			objs: []interface{}{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(envoy_v3.HTTPConnectionManagerBuilder().
+					RouteConfigName("ingress_http").
+					MetricsPrefix("ingress_http").
+					AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout", "", nil, v1alpha1.LogLevelInfo)).
+					DefaultFilters().
+					Tracing(envoy_v3.TracingConfig(&envoy_v3.EnvoyTracingConfig{
+						ExtensionService: k8s.NamespacedNameFrom("projectcontour/otel-collector"),
+						Timeout:          timeout.DurationSetting(5 * time.Second),
+						OverallSampling:  100,
+						MaxPathTagLength: 256,
+						CustomTags: []*envoy_v3.CustomTag{{
+							TagName: "cluster",
+							Literal: "west",
+						}},
+					})).
+					Get()),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
+		"insecure httpproxy with access log service config": {
+			ListenerConfig: ListenerConfig{
+				AccessLogType:       v1alpha1.JSONAccessLog,
+				AccessLogJSONFields: v1alpha1.AccessLogJSONFields{"@timestamp", "user_agent"},
+				AccessLogServiceConfig: &AccessLogServiceConfig{
+					ExtensionService: types.NamespacedName{Namespace: "projectcontour", Name: "als"},
+					LogName:          "contour",
+				},
+			},
+			objs: []interface{}{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 80,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:     "http",
+							Protocol: "TCP",
+							Port:     80,
+						}},
+					},
+				},
+			},
+			want: listenermap(&envoy_listener_v3.Listener{
+				Name:    ENVOY_HTTP_LISTENER,
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(envoy_v3.HTTPConnectionManagerBuilder().
+					RouteConfigName("ingress_http").
+					MetricsPrefix("ingress_http").
+					AccessLoggers(envoy_v3.HTTPGrpcAccessLog(&envoy_v3.GrpcAccessLogConfig{
+						ExtensionService: k8s.NamespacedNameFrom("projectcontour/als"),
+						LogName:          "contour",
+					}, v1alpha1.AccessLogJSONFields{"@timestamp", "user_agent"}, "")).
+					DefaultFilters().
+					Get()),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			}),
+		},
 		"secure httpproxy with rate limit config": {
 			ListenerConfig: ListenerConfig{
 				RateLimitConfig: &RateLimitConfig{
@@ -3441,6 +3668,28 @@ func transportSocket(secretname string, tlsMinProtoVersion envoy_tls_v3.TlsParam
 	)
 }
 
+func quicTransportSocket(secretname string) *envoy_core_v3.TransportSocket {
+	secret := &dag.Secret{
+		Object: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretname,
+				Namespace: "default",
+			},
+			Type: v1.SecretTypeTLS,
+			Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+		},
+	}
+	return envoy_v3.DownstreamQUICTransportSocket(
+		envoy_v3.DownstreamTLSContext(secret, envoy_tls_v3.TlsParameters_TLSv1_3, nil, nil, "h3"),
+	)
+}
+
+func udpSocketAddress(address string, port int) *envoy_core_v3.Address {
+	addr := envoy_v3.SocketAddress(address, port)
+	addr.GetSocketAddress().Protocol = envoy_core_v3.SocketAddress_UDP
+	return addr
+}
+
 func listenermap(listeners ...*envoy_listener_v3.Listener) map[string]*envoy_listener_v3.Listener {
 	m := make(map[string]*envoy_listener_v3.Listener)
 	for _, l := range listeners {
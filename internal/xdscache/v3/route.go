@@ -33,6 +33,11 @@ type RouteCache struct {
 	mu     sync.Mutex
 	values map[string]*envoy_route_v3.RouteConfiguration
 	contour.Cond
+
+	// HTTP3AdvertisedPort, if not zero, is the port advertised in an
+	// alt-svc response header for the secure virtual hosts that are
+	// also served over HTTP/3.
+	HTTP3AdvertisedPort int
 }
 
 // Update replaces the contents of the cache with the supplied map.
@@ -91,48 +96,118 @@ func (c *RouteCache) OnChange(root *dag.DAG) {
 	// 	- one for all the HTTP vhost routes -- "ingress_http"
 	//	- one per svhost -- "https/<vhost fqdn>"
 	//	- one for fallback cert (if configured) -- "ingress_fallbackcert"
+	//	- one per additional HTTP listener -- "<listener name>"
+	//	- one per svhost on additional HTTPS listeners -- "<listener name>/<vhost fqdn>"
 	routeConfigs := map[string]*envoy_route_v3.RouteConfiguration{
 		ENVOY_HTTP_LISTENER: envoy_v3.RouteConfiguration(ENVOY_HTTP_LISTENER),
 	}
 
-	for vhost, routes := range root.GetVirtualHostRoutes() {
-		sortRoutes(routes)
-		routeConfigs[ENVOY_HTTP_LISTENER].VirtualHosts = append(routeConfigs[ENVOY_HTTP_LISTENER].VirtualHosts,
-			envoy_v3.VirtualHostAndRoutes(vhost, routes, false, nil))
-	}
+	// The names of the route configs whose connection
+	// managers have the buffer filter.
+	buffered := map[string]bool{}
+
+	for _, listener := range root.Listeners {
+		for _, vhost := range listener.VirtualHosts {
+			routes := virtualHostRoutes(vhost)
+			if len(routes) == 0 {
+				continue
+			}
+
+			// Add the listener's route config if not already present.
+			name := listener.Name
+			if _, ok := routeConfigs[name]; !ok {
+				routeConfigs[name] = envoy_v3.RouteConfiguration(name)
+			}
 
-	for vhost, routes := range root.GetSecureVirtualHostRoutes() {
-		// Add secure vhost route config if not already present.
-		name := path.Join("https", vhost.VirtualHost.Name)
-		if _, ok := routeConfigs[name]; !ok {
-			routeConfigs[name] = envoy_v3.RouteConfiguration(name)
+			sortRoutes(routes)
+			routeConfigs[name].VirtualHosts = append(routeConfigs[name].VirtualHosts,
+				envoy_v3.VirtualHostAndRoutes(vhost, routes, false, nil))
+			buffered[name] = buffered[name] || bufferPolicyEnabled(vhost)
 		}
 
-		sortRoutes(routes)
-		routeConfigs[name].VirtualHosts = append(routeConfigs[name].VirtualHosts,
-			envoy_v3.VirtualHostAndRoutes(&vhost.VirtualHost, routes, true, vhost.AuthorizationService))
-
-		// A fallback route configuration contains routes for all the vhosts that have the fallback certificate enabled.
-		// When a request is received, the default TLS filterchain will accept the connection,
-		// and this routing table in RDS defines where the request proxies next.
-		if vhost.FallbackCertificate != nil {
-			// Add fallback route config if not already present.
-			if _, ok := routeConfigs[ENVOY_FALLBACK_ROUTECONFIG]; !ok {
-				routeConfigs[ENVOY_FALLBACK_ROUTECONFIG] = envoy_v3.RouteConfiguration(ENVOY_FALLBACK_ROUTECONFIG)
+		for _, vhost := range listener.SecureVirtualHosts {
+			routes := virtualHostRoutes(&vhost.VirtualHost)
+			if len(routes) == 0 {
+				continue
 			}
 
-			routeConfigs[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts = append(routeConfigs[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts,
-				envoy_v3.VirtualHostAndRoutes(&vhost.VirtualHost, routes, true, vhost.AuthorizationService))
+			// Add secure vhost route config if not already present.
+			name := secureRouteConfigName(listener.Name, vhost.VirtualHost.Name)
+			if _, ok := routeConfigs[name]; !ok {
+				routeConfigs[name] = envoy_v3.RouteConfiguration(name)
+			}
+
+			sortRoutes(routes)
+			evh := envoy_v3.VirtualHostAndRoutes(&vhost.VirtualHost, routes, true, vhost.AuthorizationService)
+			if c.HTTP3AdvertisedPort > 0 && http3Enabled(listener.Name, vhost) {
+				evh.ResponseHeadersToAdd = envoy_v3.AltSvcHTTP3(c.HTTP3AdvertisedPort)
+			}
+			routeConfigs[name].VirtualHosts = append(routeConfigs[name].VirtualHosts, evh)
+			buffered[name] = bufferPolicyEnabled(&vhost.VirtualHost)
+
+			// A fallback route configuration contains routes for all the vhosts that have the fallback certificate enabled.
+			// When a request is received, the default TLS filterchain will accept the connection,
+			// and this routing table in RDS defines where the request proxies next.
+			if vhost.FallbackCertificate != nil {
+				// Add fallback route config if not already present.
+				if _, ok := routeConfigs[ENVOY_FALLBACK_ROUTECONFIG]; !ok {
+					routeConfigs[ENVOY_FALLBACK_ROUTECONFIG] = envoy_v3.RouteConfiguration(ENVOY_FALLBACK_ROUTECONFIG)
+				}
+
+				routeConfigs[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts = append(routeConfigs[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts,
+					envoy_v3.VirtualHostAndRoutes(&vhost.VirtualHost, routes, true, vhost.AuthorizationService))
+				buffered[ENVOY_FALLBACK_ROUTECONFIG] = buffered[ENVOY_FALLBACK_ROUTECONFIG] || bufferPolicyEnabled(&vhost.VirtualHost)
+			}
 		}
 	}
 
-	for _, routeConfig := range routeConfigs {
+	for name, routeConfig := range routeConfigs {
 		sort.Stable(sorter.For(routeConfig.VirtualHosts))
+
+		// The buffer filter applies to every route of its
+		// connection manager, so disable it on each virtual
+		// host and let the routes with a buffer policy
+		// enable it.
+		if buffered[name] {
+			envoy_v3.DisableBuffer(routeConfig.VirtualHosts...)
+		}
 	}
 
 	c.Update(routeConfigs)
 }
 
+// virtualHostRoutes returns the routes of the virtual host as a slice.
+func virtualHostRoutes(vhost *dag.VirtualHost) []*dag.Route {
+	var routes []*dag.Route
+	for _, r := range vhost.Routes {
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// bufferPolicyEnabled returns true if any of the routes
+// of vhosts has a buffer policy.
+func bufferPolicyEnabled(vhosts ...*dag.VirtualHost) bool {
+	for _, vh := range vhosts {
+		for _, route := range vh.Routes {
+			if route.BufferPolicy != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// secureRouteConfigName returns the name of the route configuration
+// for a secure virtual host on the named listener. Secure virtual hosts
+// on the default HTTPS listener keep their "https/<vhost fqdn>" names.
+func secureRouteConfigName(listenerName, vhostName string) string {
+	if listenerName == ENVOY_HTTPS_LISTENER {
+		return path.Join("https", vhostName)
+	}
+	return path.Join(listenerName, vhostName)
+}
+
 // sortRoutes sorts the given Route slice in place. Routes are ordered
 // first by path match type, path match value via string comparison and
 // then by the length of the HeaderMatch slice (if any). The HeaderMatch
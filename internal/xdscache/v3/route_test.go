@@ -139,6 +139,7 @@ func TestRouteVisit(t *testing.T) {
 	tests := map[string]struct {
 		objs                []interface{}
 		fallbackCertificate *types.NamespacedName
+		http3AdvertisedPort int
 		want                map[string]*envoy_route_v3.RouteConfiguration
 	}{
 		"nothing": {
@@ -464,6 +465,91 @@ func TestRouteVisit(t *testing.T) {
 				),
 			),
 		},
+		"simple httpproxy with secret and http3 enabled": {
+			http3AdvertisedPort: 443,
+			objs: []interface{}{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "www.example.com",
+							TLS: &contour_api_v1.TLS{
+								SecretName: "secret",
+							},
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/",
+							}},
+							Services: []contour_api_v1.Service{{
+								Name: "backend",
+								Port: 8080,
+							}},
+						}},
+					},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "secret",
+						Namespace: "default",
+					},
+					Type: "kubernetes.io/tls",
+					Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "backend",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Ports: []v1.ServicePort{{
+							Name:       "www",
+							Protocol:   "TCP",
+							Port:       8080,
+							TargetPort: intstr.FromInt(8080),
+						}},
+					},
+				},
+			},
+			want: routeConfigurations(
+				envoy_v3.RouteConfiguration("ingress_http",
+					envoy_v3.VirtualHost("www.example.com",
+						&envoy_route_v3.Route{
+							Match: routePrefix("/"),
+							Action: &envoy_route_v3.Route_Redirect{
+								Redirect: &envoy_route_v3.RedirectAction{
+									SchemeRewriteSpecifier: &envoy_route_v3.RedirectAction_HttpsRedirect{
+										HttpsRedirect: true,
+									},
+								},
+							},
+						},
+					),
+				),
+				envoy_v3.RouteConfiguration("https/www.example.com",
+					&envoy_route_v3.VirtualHost{
+						Name:    "www.example.com",
+						Domains: []string{"www.example.com"},
+						Routes: []*envoy_route_v3.Route{{
+							Match:  routePrefix("/"),
+							Action: routecluster("default/backend/8080/da39a3ee5e"),
+						}},
+						ResponseHeadersToAdd: []*envoy_core_v3.HeaderValueOption{{
+							Header: &envoy_core_v3.HeaderValue{
+								Key:   "alt-svc",
+								Value: `h3=":443"; ma=86400`,
+							},
+							Append: &wrappers.BoolValue{
+								Value: false,
+							},
+						}},
+					},
+				),
+			),
+		},
 		"simple tls ingress with allow-http:false": {
 			objs: []interface{}{
 				&networking_v1.Ingress{
@@ -3056,7 +3142,9 @@ func TestRouteVisit(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			var rc RouteCache
+			rc := RouteCache{
+				HTTP3AdvertisedPort: tc.http3AdvertisedPort,
+			}
 			rc.OnChange(buildDAGFallback(t, tc.fallbackCertificate, tc.objs...))
 			protobuf.ExpectEqual(t, tc.want, rc.values)
 		})
@@ -75,12 +75,19 @@ type Configuration struct {
 
 	// EnvoyServiceName holds the name of the Envoy service name.
 	EnvoyServiceName string
+
+	// CACertificate and CAPrivateKey hold an existing CA keypair in
+	// PEM format to issue the certificates from. If they are not set,
+	// a new CA is generated.
+	CACertificate []byte
+	CAPrivateKey  []byte
 }
 
 // Certificates contains a set of Certificates as []byte each holding
 // the CA Cert along with with Contour & Envoy Certs.
 type Certificates struct {
 	CACertificate      []byte
+	CAPrivateKey       []byte
 	ContourCertificate []byte
 	ContourPrivateKey  []byte
 	EnvoyCertificate   []byte
@@ -98,9 +105,12 @@ func GenerateCerts(config *Configuration) (*Certificates, error) {
 
 	now := time.Now()
 	expiry := now.Add(24 * time.Duration(uint32OrDefault(config.Lifetime, DefaultCertificateLifetime)) * time.Hour)
-	caCertPEM, caKeyPEM, err := newCA("Project Contour", expiry)
-	if err != nil {
-		return nil, err
+	caCertPEM, caKeyPEM := config.CACertificate, config.CAPrivateKey
+	if len(caCertPEM) == 0 || len(caKeyPEM) == 0 {
+		var err error
+		if caCertPEM, caKeyPEM, err = newCA("Project Contour", expiry); err != nil {
+			return nil, err
+		}
 	}
 
 	contourCert, contourKey, err := newCert(caCertPEM,
@@ -127,6 +137,7 @@ func GenerateCerts(config *Configuration) (*Certificates, error) {
 
 	return &Certificates{
 		CACertificate:      caCertPEM,
+		CAPrivateKey:       caKeyPEM,
 		ContourCertificate: contourCert,
 		ContourPrivateKey:  contourKey,
 		EnvoyCertificate:   envoyCert,
@@ -152,6 +163,10 @@ func newCert(caCertPEM, caKeyPEM []byte, expiry time.Time, service, namespace, d
 	if !ok {
 		return nil, nil, fmt.Errorf("CA private key has unexpected type %T", caKeyPair.PrivateKey)
 	}
+	// A certificate is not valid for longer than the CA that issued it.
+	if caCert.NotAfter.Before(expiry) {
+		expiry = caCert.NotAfter
+	}
 
 	newKey, err := rsa.GenerateKey(rand.Reader, keySize)
 	if err != nil {
@@ -19,6 +19,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -416,6 +417,21 @@ type ListenerParameters struct {
 	// See https://www.envoyproxy.io/docs/envoy/latest/api-v2/api/v2/listener.proto#envoy-api-msg-listener-connectionbalanceconfig
 	// for more information.
 	ConnectionBalancer string `yaml:"connection-balancer"`
+
+	// HTTP3 holds the HTTP/3 (QUIC) settings for the HTTPS listener.
+	HTTP3 HTTP3Parameters `yaml:"http3,omitempty"`
+}
+
+// HTTP3Parameters holds the HTTP/3 (QUIC) settings for the HTTPS listener.
+type HTTP3Parameters struct {
+	// Enabled adds a UDP listener that serves HTTP/3 over QUIC on the
+	// HTTPS listener's address and port, and advertises it with an
+	// "alt-svc" header on responses from the HTTPS listener.
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// AdvertisedPort is the UDP port advertised in the "alt-svc" header.
+	// If not set, defaults to 443.
+	AdvertisedPort int `yaml:"advertised-port,omitempty"`
 }
 
 func (p *ListenerParameters) Validate() error {
@@ -426,6 +442,10 @@ func (p *ListenerParameters) Validate() error {
 	if p.ConnectionBalancer != "" && p.ConnectionBalancer != "exact" {
 		return fmt.Errorf("invalid listener connection balancer value %q, only 'exact' connection balancing is supported for now", p.ConnectionBalancer)
 	}
+
+	if p.HTTP3.AdvertisedPort < 0 || p.HTTP3.AdvertisedPort > 65535 {
+		return fmt.Errorf("invalid listener HTTP/3 advertised port %d", p.HTTP3.AdvertisedPort)
+	}
 	return nil
 }
 
@@ -466,9 +486,17 @@ type Parameters struct {
 	// AccessLogLevel sets the verbosity level of the access log.
 	AccessLogLevel AccessLogLevel `yaml:"accesslog-level,omitempty"`
 
+	// AccessLogService optionally configures Envoy to send access
+	// logs to a gRPC access log service instead of the access log files.
+	AccessLogService *AccessLogService `yaml:"accessLogService,omitempty"`
+
 	// TLS contains TLS policy parameters.
 	TLS TLSParameters `yaml:"tls,omitempty"`
 
+	// SecretDiscoveryService optionally configures a secret discovery
+	// service that Envoy fetches upstream client certificates from.
+	SecretDiscoveryService *SecretDiscoveryService `yaml:"secretDiscoveryService,omitempty"`
+
 	// DisablePermitInsecure disables the use of the
 	// permitInsecure field in HTTPProxy.
 	DisablePermitInsecure bool `yaml:"disablePermitInsecure,omitempty"`
@@ -525,8 +553,18 @@ type Parameters struct {
 	// to be used for global rate limiting.
 	RateLimitService RateLimitService `yaml:"rateLimitService,omitempty"`
 
+	// Tracing holds the configuration for exporting trace data
+	// from Envoy to an OpenTelemetry collector.
+	Tracing *Tracing `yaml:"tracing,omitempty"`
+
 	// MetricsParameters holds configurable parameters for Contour and Envoy metrics.
 	Metrics MetricsParameters `yaml:"metrics,omitempty"`
+
+	// FeatureFlags defines toggles to enable new Contour features.
+	// Available toggles are:
+	// useEndpointSlices - configures Contour to build Envoy endpoint
+	// data from Kubernetes EndpointSlices rather than Endpoints.
+	FeatureFlags []string `yaml:"featureFlags,omitempty"`
 }
 
 // RateLimitService defines properties of a global Rate Limit Service.
@@ -552,6 +590,120 @@ type RateLimitService struct {
 	EnableXRateLimitHeaders bool `yaml:"enableXRateLimitHeaders,omitempty"`
 }
 
+// AccessLogService defines properties of a gRPC access log service.
+type AccessLogService struct {
+	// ExtensionService identifies the extension service defining the
+	// access log service, formatted as <namespace>/<name>.
+	ExtensionService string `yaml:"extensionService"`
+
+	// LogName identifies the source of the access log entries.
+	// Defaults to "contour".
+	LogName string `yaml:"logName,omitempty"`
+}
+
+// Validate ensures that the access log service configuration is valid.
+func (a *AccessLogService) Validate() error {
+	if a == nil {
+		return nil
+	}
+
+	if a.ExtensionService == "" {
+		return errors.New("accessLogService.extensionService must be defined")
+	}
+
+	return nil
+}
+
+// SecretDiscoveryService defines properties of a secret discovery service.
+type SecretDiscoveryService struct {
+	// ExtensionService identifies the extension service defining the
+	// secret discovery service, formatted as <namespace>/<name>.
+	ExtensionService string `yaml:"extensionService"`
+}
+
+// Validate ensures that the secret discovery service configuration is valid.
+func (s *SecretDiscoveryService) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.ExtensionService == "" {
+		return errors.New("secretDiscoveryService.extensionService must be defined")
+	}
+
+	return nil
+}
+
+// Tracing defines properties for exporting trace data from Envoy.
+type Tracing struct {
+	// ExtensionService identifies the extension service defining the
+	// OpenTelemetry collector, formatted as <namespace>/<name>.
+	ExtensionService string `yaml:"extensionService"`
+
+	// OverallSampling defines the percentage of requests that are
+	// sampled for tracing, as a number between 0 and 100.
+	// Defaults to 100.
+	OverallSampling *string `yaml:"overallSampling,omitempty"`
+
+	// MaxPathTagLength defines the maximum length of the request path
+	// to extract and include in the HttpUrl tag.
+	// Defaults to 256.
+	MaxPathTagLength *uint32 `yaml:"maxPathTagLength,omitempty"`
+
+	// CustomTags defines a list of custom tags with unique tag names
+	// that are added to each span.
+	CustomTags []CustomTag `yaml:"customTags,omitempty"`
+}
+
+// CustomTag defines a custom tag that is added to spans. Exactly
+// one of Literal or RequestHeaderName must be set.
+type CustomTag struct {
+	// TagName is the unique name of the custom tag.
+	TagName string `yaml:"tagName"`
+
+	// Literal is a static value for the tag.
+	Literal string `yaml:"literal,omitempty"`
+
+	// RequestHeaderName is the name of the request header
+	// whose value is used for the tag.
+	RequestHeaderName string `yaml:"requestHeaderName,omitempty"`
+}
+
+// Validate ensures that the tracing configuration is valid.
+func (t *Tracing) Validate() error {
+	if t == nil {
+		return nil
+	}
+
+	if t.ExtensionService == "" {
+		return errors.New("tracing.extensionService must be defined")
+	}
+
+	if t.OverallSampling != nil {
+		sampling, err := strconv.ParseFloat(*t.OverallSampling, 64)
+		if err != nil || sampling < 0 || sampling > 100 {
+			return fmt.Errorf("invalid tracing overall sampling %q: must be a number between 0 and 100", *t.OverallSampling)
+		}
+	}
+
+	tagNames := map[string]struct{}{}
+	for _, tag := range t.CustomTags {
+		if tag.TagName == "" {
+			return errors.New("tracing custom tag name must be defined")
+		}
+		if _, ok := tagNames[tag.TagName]; ok {
+			return fmt.Errorf("duplicate tracing custom tag name %q", tag.TagName)
+		}
+		tagNames[tag.TagName] = struct{}{}
+
+		if (tag.Literal == "") == (tag.RequestHeaderName == "") {
+			return fmt.Errorf("tracing custom tag %q must define exactly one of literal or requestHeaderName", tag.TagName)
+		}
+	}
+
+	return nil
+}
+
 // MetricsParameters defines configuration for metrics server endpoints in both
 // Contour and Envoy.
 type MetricsParameters struct {
@@ -658,6 +810,10 @@ func (p *Parameters) Validate() error {
 		return err
 	}
 
+	if err := contour_api_v1alpha1.FeatureFlags(p.FeatureFlags).Validate(); err != nil {
+		return err
+	}
+
 	if err := p.Policy.Validate(); err != nil {
 		return err
 	}
@@ -672,6 +828,24 @@ func (p *Parameters) Validate() error {
 		return err
 	}
 
+	if err := p.AccessLogService.Validate(); err != nil {
+		return err
+	}
+
+	if p.AccessLogService != nil && p.AccessLogFormat == JSONAccessLog {
+		if err := contour_api_v1alpha1.AccessLogJSONFields(p.AccessLogFields).ValidateForAccessLogService(); err != nil {
+			return err
+		}
+	}
+
+	if err := p.SecretDiscoveryService.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.Tracing.Validate(); err != nil {
+		return err
+	}
+
 	return p.Listener.Validate()
 }
 
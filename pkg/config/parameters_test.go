@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
+	"k8s.io/utils/pointer"
 )
 
 func TestGetenvOr(t *testing.T) {
@@ -479,4 +480,103 @@ func TestListenerValidation(t *testing.T) {
 		ConnectionBalancer: "invalid",
 	}
 	require.Error(t, l.Validate())
+	l = &ListenerParameters{
+		HTTP3: HTTP3Parameters{Enabled: true, AdvertisedPort: 443},
+	}
+	require.NoError(t, l.Validate())
+	l = &ListenerParameters{
+		HTTP3: HTTP3Parameters{Enabled: true, AdvertisedPort: 65536},
+	}
+	require.Error(t, l.Validate())
+}
+
+func TestAccessLogServiceValidation(t *testing.T) {
+	var als *AccessLogService
+	require.NoError(t, als.Validate())
+
+	als = &AccessLogService{
+		ExtensionService: "projectcontour/als",
+		LogName:          "contour",
+	}
+	require.NoError(t, als.Validate())
+
+	als = &AccessLogService{}
+	require.Error(t, als.Validate())
+}
+
+func TestAccessLogServiceFieldsValidation(t *testing.T) {
+	p := Defaults()
+	p.AccessLogFormat = JSONAccessLog
+	p.AccessLogService = &AccessLogService{
+		ExtensionService: "projectcontour/als",
+	}
+	require.NoError(t, p.Validate())
+
+	// Fields that are not part of the access log entry are rejected.
+	p.AccessLogFields = AccessLogFields{"@timestamp", "upstream_wire_bytes_sent"}
+	require.Error(t, p.Validate())
+
+	// Unless access logs are not in JSON format, in which case
+	// the fields are not used.
+	p.AccessLogFormat = EnvoyAccessLog
+	require.NoError(t, p.Validate())
+}
+
+func TestSecretDiscoveryServiceValidation(t *testing.T) {
+	var sds *SecretDiscoveryService
+	require.NoError(t, sds.Validate())
+
+	sds = &SecretDiscoveryService{
+		ExtensionService: "spire/spire-agent",
+	}
+	require.NoError(t, sds.Validate())
+
+	sds = &SecretDiscoveryService{}
+	require.Error(t, sds.Validate())
+}
+
+func TestTracingValidation(t *testing.T) {
+	var trace *Tracing
+	require.NoError(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+	}
+	require.NoError(t, trace.Validate())
+
+	trace = &Tracing{}
+	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+		OverallSampling:  pointer.String("50"),
+		CustomTags: []CustomTag{
+			{TagName: "literal", Literal: "foo"},
+			{TagName: "header", RequestHeaderName: ":method"},
+		},
+	}
+	require.NoError(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+		OverallSampling:  pointer.String("-1"),
+	}
+	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+		CustomTags: []CustomTag{
+			{TagName: "both", Literal: "foo", RequestHeaderName: ":method"},
+		},
+	}
+	require.Error(t, trace.Validate())
+
+	trace = &Tracing{
+		ExtensionService: "projectcontour/otel-collector",
+		CustomTags: []CustomTag{
+			{TagName: "dup", Literal: "foo"},
+			{TagName: "dup", RequestHeaderName: ":method"},
+		},
+	}
+	require.Error(t, trace.Validate())
 }